@@ -13,12 +13,26 @@ import (
 
 	"github.com/namf2001/go-backend-template/config"
 	authcontroller "github.com/namf2001/go-backend-template/internal/controller/auth"
+	authservercontroller "github.com/namf2001/go-backend-template/internal/controller/authserver"
 	userscontroller "github.com/namf2001/go-backend-template/internal/controller/users"
+	appMiddleware "github.com/namf2001/go-backend-template/internal/handler/middleware"
 	authhandler "github.com/namf2001/go-backend-template/internal/handler/rest/v1/auth"
+	authserverhandler "github.com/namf2001/go-backend-template/internal/handler/rest/v1/authserver"
 	usershandler "github.com/namf2001/go-backend-template/internal/handler/rest/v1/users"
 	"github.com/namf2001/go-backend-template/internal/pkg/database"
+	"github.com/namf2001/go-backend-template/internal/pkg/encryption"
+	"github.com/namf2001/go-backend-template/internal/pkg/jwt"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/namf2001/go-backend-template/internal/pkg/mailer"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
 	"github.com/namf2001/go-backend-template/internal/pkg/oauth"
+	"github.com/namf2001/go-backend-template/internal/pkg/passkey"
+	"github.com/namf2001/go-backend-template/internal/pkg/password"
+	"github.com/namf2001/go-backend-template/internal/pkg/tracing"
 	"github.com/namf2001/go-backend-template/internal/repository"
+	"github.com/namf2001/go-backend-template/internal/repository/loginattempts"
+	"github.com/namf2001/go-backend-template/internal/repository/ratelimits"
+	"go.uber.org/zap"
 )
 
 // @title           Go Backend Template API
@@ -39,6 +53,10 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
 	ctx := context.Background()
 
@@ -56,37 +74,116 @@ func main() {
 
 	log.Printf("Initializing config for environment: %s", env)
 	config.Init(env)
+	if err := logger.Init(); err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer func() { _ = logger.L().Sync() }()
 
 	if err := run(ctx); err != nil {
-		log.Fatalf("Application error: %v", err)
+		logger.L().Fatal("application error", zap.Error(err))
 	}
 }
 
 func run(ctx context.Context) error {
 	cfg := config.GetConfig()
 
+	shutdownTracing, err := tracing.Init(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.L().Error("tracing shutdown failed", zap.Error(err))
+		}
+	}()
+
 	db, err := database.NewPostgresConnection()
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer db.Close()
-	log.Println("✓ Database connected successfully")
+	logger.L().Info("database connected")
+	if err := metrics.RegisterDB(db, cfg.GetString("DB_NAME")); err != nil {
+		return fmt.Errorf("failed to register database metrics: %w", err)
+	}
 
-	// Initialize OAuth
-	oauth.Init()
+	// Initialize JWT keys
+	if err := jwt.Init(); err != nil {
+		return fmt.Errorf("failed to load jwt keys: %w", err)
+	}
+	// Initialize OAuth providers
+	providers, err := oauth.NewRegistryFromConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize oauth providers: %w", err)
+	}
+	oauthStates, err := oauth.NewStateCookieFromConfig()
+	if err != nil {
+		return fmt.Errorf("failed to initialize oauth state: %w", err)
+	}
+	// Initialize encryption of secrets at rest
+	cipher, err := encryption.NewFromConfig()
+	if err != nil {
+		return fmt.Errorf("failed to initialize encryption: %w", err)
+	}
+	// Initialize passkey relying party
+	relyingParty, err := passkey.NewFromConfig()
+	if err != nil {
+		return fmt.Errorf("failed to initialize webauthn: %w", err)
+	}
+	// Initialize password hashing
+	passwords, err := password.NewFromConfig()
+	if err != nil {
+		return fmt.Errorf("failed to initialize password hashing: %w", err)
+	}
+	passwordPolicy, err := password.NewPolicyFromConfig()
+	if err != nil {
+		return fmt.Errorf("failed to initialize password policy: %w", err)
+	}
+	// Initialize mailer
+	mail, err := mailer.New()
+	if err != nil {
+		return fmt.Errorf("failed to initialize mailer: %w", err)
+	}
 	// Initialize repository
 	repo := repository.New(db)
+	// Failed logins are kept in Postgres so every instance shares them, unless LOGIN_ATTEMPTS_STORE=memory
+	loginAttempts := repo.LoginAttempt()
+	if cfg.GetString("LOGIN_ATTEMPTS_STORE") == "memory" {
+		loginAttempts = loginattempts.NewMemory()
+	}
+	// Rate limit buckets are shared through Postgres as well, unless RATE_LIMIT_STORE=memory
+	rateLimits := repo.RateLimit()
+	if cfg.GetString("RATE_LIMIT_STORE") == "memory" {
+		rateLimits = ratelimits.NewMemory()
+	}
+	rateLimitPolicies, err := loadRateLimitPolicies(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to load rate limit policies: %w", err)
+	}
+	// Client IPs are only read from X-Forwarded-For when the request comes from one of TRUSTED_PROXIES
+	trustedProxies, err := appMiddleware.ParseTrustedProxies(cfg.GetString("TRUSTED_PROXIES"))
+	if err != nil {
+		return fmt.Errorf("failed to load trusted proxies: %w", err)
+	}
 	// Initialize controllers
-	usersController := userscontroller.New(repo)
-	authController := authcontroller.New(repo)
+	usersController := userscontroller.New(repo, passwords, passwordPolicy)
+	authController := authcontroller.New(repo, mail, loginAttempts, cipher, relyingParty, passwords, passwordPolicy)
+	authServerController := authservercontroller.New(repo)
 	// Initialize handlers
 	usersHandler := usershandler.New(usersController)
-	authHandler := authhandler.New(authController)
+	authHandler := authhandler.New(authController, providers, oauthStates)
+	authServerHandler := authserverhandler.New(authServerController, cfg.GetString("OAUTH_CONSENT_URL"))
 	// Setup router
 	rtr := router{
-		ctx:          ctx,
-		usersHandler: usersHandler,
-		authHandler:  authHandler,
+		ctx:               ctx,
+		authCtrl:          authController,
+		usersHandler:      usersHandler,
+		authHandler:       authHandler,
+		authServerHandler: authServerHandler,
+		rateLimits:        rateLimits,
+		rateLimitPolicies: rateLimitPolicies,
+		trustedProxies:    trustedProxies,
+		serveMetrics:      cfg.GetString("METRICS_ADDR") == "",
 	}
 	// Start server
 	addr := fmt.Sprintf(":%s", cfg.GetString("APP_PORT"))
@@ -96,12 +193,28 @@ func run(ctx context.Context) error {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 	}
+	// Metrics are served on the API port, unless METRICS_ADDR sets an admin address of their own
+	metricsURL := fmt.Sprintf("http://localhost%s/metrics", addr)
+	var metricsSrv *http.Server
+	if metricsAddr := cfg.GetString("METRICS_ADDR"); metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		metricsSrv = &http.Server{
+			Addr:         metricsAddr,
+			Handler:      mux,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		}
+		metricsURL = fmt.Sprintf("http://%s/metrics", metricsAddr)
+	}
 
-	log.Printf("🚀 Server starting on %s", addr)
-	log.Printf("📝 Environment: %s", cfg.GetString("APP_ENV"))
-	log.Printf("🔗 Health check: http://localhost%s/health", addr)
-	log.Printf("🔗 API Swagger URL: http://localhost%s/swagger/index.html", addr)
-	log.Printf("🔗 API Metrics URL: http://localhost%s/metrics", addr)
+	logger.L().Info("server starting",
+		zap.String("addr", addr),
+		zap.String("env", cfg.GetString("APP_ENV")),
+		zap.String("health_url", fmt.Sprintf("http://localhost%s/health", addr)),
+		zap.String("swagger_url", fmt.Sprintf("http://localhost%s/swagger/index.html", addr)),
+		zap.String("metrics_url", metricsURL),
+	)
 
 	// Graceful shutdown channel
 	done := make(chan os.Signal, 1)
@@ -109,19 +222,31 @@ func run(ctx context.Context) error {
 
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server failed to start: %v", err)
+			logger.L().Fatal("server failed to start", zap.Error(err))
 		}
 	}()
+	if metricsSrv != nil {
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.L().Fatal("metrics server failed to start", zap.Error(err))
+			}
+		}()
+	}
 
 	<-done
-	log.Println("Server stopping...")
+	logger.L().Info("server stopping")
 
 	ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctxShutdown); err != nil {
 		return fmt.Errorf("server shutdown failed: %w", err)
 	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctxShutdown); err != nil {
+			return fmt.Errorf("metrics server shutdown failed: %w", err)
+		}
+	}
 	
-	log.Println("Server exited properly")
+	logger.L().Info("server exited properly")
 	return nil
 }
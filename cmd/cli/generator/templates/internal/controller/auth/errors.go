@@ -3,5 +3,34 @@ package auth
 import "errors"
 
 var (
-	ErrUserNotFound = errors.New("user not found")
-)
\ No newline at end of file
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrSessionRevoked      = errors.New("session revoked or expired")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrAccountLocked       = errors.New("too many failed login attempts")
+
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrEmailAlreadyVerified     = errors.New("email already verified")
+
+	ErrOAuthEmailNotVerified = errors.New("the provider did not verify the email")
+	ErrAccountAlreadyLinked  = errors.New("provider account is linked to another user")
+	ErrProviderAlreadyLinked = errors.New("another account of this provider is already linked")
+
+	ErrInvalidMFAToken     = errors.New("invalid or expired mfa token")
+	ErrInvalidMFACode      = errors.New("invalid mfa code")
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled      = errors.New("two-factor authentication is not enrolled")
+	ErrMFARequiresPassword = errors.New("two-factor authentication requires a password")
+
+	ErrInvalidPasskey           = errors.New("invalid passkey response")
+	ErrPasskeyCloned            = errors.New("passkey sign counter did not increase, the authenticator may be cloned")
+	ErrPasskeyAlreadyRegistered = errors.New("passkey already registered")
+	ErrPasskeyNotFound          = errors.New("passkey not found")
+	ErrLastSignInMethod         = errors.New("cannot remove the last sign-in method")
+
+	ErrInvalidAPIKey       = errors.New("invalid, expired or revoked api key")
+	ErrInvalidAPIKeyScope  = errors.New("api key scope is not a permission of the user")
+	ErrInvalidAPIKeyExpiry = errors.New("api key expiry must be in the future")
+	ErrAPIKeyNotFound      = errors.New("api key not found")
+)
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/repository/loginattempts"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockout(t *testing.T) {
+	policy := lockoutPolicy{
+		window:          15 * time.Minute,
+		delayAfter:      2,
+		baseDelay:       time.Second,
+		maxDelay:        4 * time.Second,
+		maxAttempts:     5,
+		ipMaxAttempts:   8,
+		lockoutDuration: 15 * time.Minute,
+	}
+
+	type args struct {
+		failures      int           // Failed logins recorded for the account, one second apart
+		ipFailures    int           // Extra failed logins recorded from the same IP for other accounts
+		elapsed       time.Duration // Time between the last failure and the next attempt
+		expRetryAfter time.Duration // Zero when the attempt is allowed
+	}
+	tcs := map[string]args{
+		"below delay threshold": {
+			failures: 1,
+		},
+		"first delay": {
+			failures:      2,
+			expRetryAfter: time.Second,
+		},
+		"delay doubles": {
+			failures:      3,
+			expRetryAfter: 2 * time.Second,
+		},
+		"delay is capped": {
+			failures:      4,
+			expRetryAfter: 4 * time.Second,
+		},
+		"delay elapsed": {
+			failures: 4,
+			elapsed:  4 * time.Second,
+		},
+		"account locked": {
+			failures:      5,
+			elapsed:       time.Minute,
+			expRetryAfter: 14 * time.Minute,
+		},
+		"lock expired": {
+			failures: 5,
+			elapsed:  15 * time.Minute,
+		},
+		"ip locked": {
+			ipFailures:    8,
+			elapsed:       time.Minute,
+			expRetryAfter: 14 * time.Minute,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			ctx := context.Background()
+			i := impl{attempts: loginattempts.NewMemory(), lockout: policy}
+			accountKey, ipKey := loginKeys(" Test1@Example.com", "192.0.2.1")
+			require.Equal(t, "account:test1@example.com", accountKey)
+
+			now := time.Now()
+			for j := 0; j < tc.ipFailures; j++ {
+				otherKey, _ := loginKeys("other@example.com", "")
+				require.NoError(t, i.recordLoginFailure(ctx, now, otherKey, ipKey))
+			}
+			for j := 0; j < tc.failures; j++ {
+				now = now.Add(time.Second)
+				require.NoError(t, i.recordLoginFailure(ctx, now, accountKey, ipKey))
+			}
+
+			err := i.checkLockout(ctx, now.Add(tc.elapsed), accountKey, ipKey)
+			if tc.expRetryAfter == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrAccountLocked)
+			var locked *LockedError
+			require.True(t, errors.As(err, &locked))
+			require.Equal(t, tc.expRetryAfter, locked.RetryAfter)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	"github.com/namf2001/go-backend-template/internal/pkg/password"
+	"github.com/namf2001/go-backend-template/internal/repository/loginattempts"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordLogin(t *testing.T) {
+	type args struct {
+		tokens    Tokens
+		err       error
+		expResult string
+	}
+	tcs := map[string]args{
+		"success": {
+			tokens:    Tokens{AccessToken: "access"},
+			expResult: metrics.ResultSuccess,
+		},
+		"success - mfa required": {
+			tokens:    Tokens{MFAToken: "mfa"},
+			expResult: metrics.ResultMFARequired,
+		},
+		"err - locked": {
+			err:       pkgerrors.WithStack(&LockedError{RetryAfter: time.Minute}),
+			expResult: metrics.ResultLocked,
+		},
+		"err - wrong password": {
+			err:       errors.New("password mismatch"),
+			expResult: metrics.ResultFailure,
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			counter := metrics.AuthLogins.WithLabelValues(metrics.MethodPassword, tc.expResult)
+			before := testutil.ToFloat64(counter)
+
+			// When
+			recordLogin(metrics.MethodPassword, tc.tokens, tc.err)
+
+			// Then
+			require.Equal(t, before+1, testutil.ToFloat64(counter))
+		})
+	}
+}
+
+// countingHasher records the hashes passwords are verified against
+type countingHasher struct {
+	password.Hasher
+	verified *[]string
+}
+
+func (h countingHasher) Verify(hash, plain string) error {
+	*h.verified = append(*h.verified, hash)
+	return h.Hasher.Verify(hash, plain)
+}
+
+func TestLogin_VerifiesAHash(t *testing.T) {
+	hasher := password.Bcrypt{Cost: 4}
+	realHash, err := hasher.Hash("s3cret-password")
+	require.NoError(t, err)
+	dummyHash, err := hasher.Hash("dummy-password")
+	require.NoError(t, err)
+
+	type args struct {
+		givenEmail  string
+		expVerified string
+	}
+	tcs := map[string]args{
+		"err - wrong password": {
+			givenEmail:  "user@example.com",
+			expVerified: realHash,
+		},
+		"err - unknown email": {
+			givenEmail:  "unknown@example.com",
+			expVerified: dummyHash,
+		},
+		"err - account without password": {
+			givenEmail:  "oauth@example.com",
+			expVerified: dummyHash,
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			repo := newFakeRegistry()
+			repo.users.users[1] = model.User{ID: 1, Email: "user@example.com", Password: realHash}
+			repo.users.users[2] = model.User{ID: 2, Email: "oauth@example.com"}
+			var verified []string
+			i := impl{
+				repo:      repo,
+				attempts:  loginattempts.NewMemory(),
+				lockout:   lockoutPolicy{window: 15 * time.Minute, delayAfter: 3, baseDelay: time.Second, maxAttempts: 10, ipMaxAttempts: 50},
+				passwords: countingHasher{Hasher: hasher, verified: &verified},
+				dummyHash: func() string { return dummyHash },
+			}
+
+			// When
+			_, err := i.Login(context.Background(), ValidationInput{Email: tc.givenEmail, Password: "wrong-password"})
+
+			// Then
+			require.Error(t, err)
+			require.Equal(t, []string{tc.expVerified}, verified)
+		})
+	}
+}
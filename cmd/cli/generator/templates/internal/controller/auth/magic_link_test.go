@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/repository/loginattempts"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottleMagicLink(t *testing.T) {
+	type args struct {
+		requests      int           // Earlier requests, one second apart
+		elapsed       time.Duration // Time between the last request and the next one
+		expRetryAfter time.Duration // Zero when the request is allowed
+	}
+	tcs := map[string]args{
+		"first request": {},
+		"below limit": {
+			requests: 2,
+		},
+		"limit reached": {
+			requests:      3,
+			elapsed:       time.Minute,
+			expRetryAfter: 14 * time.Minute,
+		},
+		"window elapsed": {
+			requests: 3,
+			elapsed:  15 * time.Minute,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			ctx := context.Background()
+			i := impl{
+				attempts:  loginattempts.NewMemory(),
+				magicLink: magicLinkPolicy{window: 15 * time.Minute, maxRequests: 3},
+			}
+
+			now := time.Now()
+			for j := 0; j < tc.requests; j++ {
+				now = now.Add(time.Second)
+				require.NoError(t, i.throttleMagicLink(ctx, "Test1@Example.com", now))
+			}
+
+			// The limit applies to the email whatever its case
+			err := i.throttleMagicLink(ctx, "test1@example.com ", now.Add(tc.elapsed))
+			if tc.expRetryAfter == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var locked *LockedError
+			require.True(t, errors.As(err, &locked))
+			require.ErrorIs(t, err, ErrAccountLocked)
+			require.Equal(t, tc.expRetryAfter, locked.RetryAfter)
+		})
+	}
+}
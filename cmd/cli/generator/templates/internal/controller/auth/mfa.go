@@ -0,0 +1,278 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/jwt"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	"github.com/namf2001/go-backend-template/internal/pkg/totp"
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	"github.com/namf2001/go-backend-template/internal/repository"
+	repoMFA "github.com/namf2001/go-backend-template/internal/repository/mfa"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const (
+	recoveryCodeCount = 10
+	defaultMFAIssuer  = "go-backend-template"
+)
+
+// VerifyMFAInput is the second step of a login with two-factor authentication
+type VerifyMFAInput struct {
+	MFAToken string
+	Code     string // TOTP code or recovery code
+	IP       string
+}
+
+// MFAEnrollment is what an authenticator app needs to generate codes
+type MFAEnrollment struct {
+	Secret string
+	URI    string // otpauth:// URI, usually rendered as a QR code
+}
+
+// VerifyMFA exchanges an mfa_pending token and a second factor code for tokens.
+// Wrong codes are throttled like wrong passwords, under their own key.
+func (i impl) VerifyMFA(ctx context.Context, input VerifyMFAInput) (tokens Tokens, err error) {
+	defer func() { recordLogin(metrics.MethodMFA, tokens, err) }()
+
+	claims, err := jwt.ParseMFAToken(input.MFAToken)
+	if err != nil {
+		return Tokens{}, pkgerrors.WithStack(ErrInvalidMFAToken)
+	}
+
+	now := time.Now()
+	mfaKey := mfaLockoutKey(claims.UserID)
+	_, ipKey := loginKeys("", input.IP)
+	if err := i.checkLockout(ctx, now, mfaKey, ipKey); err != nil {
+		return Tokens{}, err
+	}
+
+	user, err := i.repo.User().GetByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return Tokens{}, pkgerrors.WithStack(ErrInvalidMFAToken)
+		}
+		return Tokens{}, err
+	}
+
+	mfa, err := i.repo.MFA().Get(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, repoMFA.ErrNotFound) {
+			return Tokens{}, pkgerrors.WithStack(ErrInvalidMFAToken)
+		}
+		return Tokens{}, err
+	}
+	if !mfa.IsEnabled() {
+		return Tokens{}, pkgerrors.WithStack(ErrInvalidMFAToken)
+	}
+
+	if err := i.verifyMFACode(ctx, i.repo, mfa, input.Code, now, true); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			if recordErr := i.recordLoginFailure(ctx, now, mfaKey, ipKey); recordErr != nil {
+				return Tokens{}, recordErr
+			}
+		}
+		return Tokens{}, err
+	}
+
+	if err := i.attempts.Reset(ctx, mfaKey); err != nil {
+		return Tokens{}, pkgerrors.WithStack(err)
+	}
+
+	return issueTokens(ctx, i.repo, user, "")
+}
+
+// EnrollMFA generates a new TOTP secret for the user. It only takes effect once confirmed
+// with a code, until then enrolling again replaces the secret.
+func (i impl) EnrollMFA(ctx context.Context, userID int64) (MFAEnrollment, error) {
+	user, err := i.repo.User().GetByID(ctx, userID)
+	if err != nil {
+		return MFAEnrollment{}, err
+	}
+
+	// The second factor is asked after the password, OAuth only users are protected by their provider
+	if user.Password == "" {
+		return MFAEnrollment{}, pkgerrors.WithStack(ErrMFARequiresPassword)
+	}
+
+	existing, err := i.repo.MFA().Get(ctx, userID)
+	if err != nil && !errors.Is(err, repoMFA.ErrNotFound) {
+		return MFAEnrollment{}, err
+	}
+	if err == nil && existing.IsEnabled() {
+		return MFAEnrollment{}, pkgerrors.WithStack(ErrMFAAlreadyEnabled)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return MFAEnrollment{}, err
+	}
+
+	encrypted, err := i.cipher.Encrypt([]byte(secret))
+	if err != nil {
+		return MFAEnrollment{}, err
+	}
+
+	if err := i.repo.MFA().Upsert(ctx, model.MFA{UserID: userID, Secret: encrypted}); err != nil {
+		return MFAEnrollment{}, err
+	}
+
+	return MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(mfaIssuer(), user.Email, secret),
+	}, nil
+}
+
+// ConfirmMFA enables the enrolled second factor once the user proved their app generates valid codes.
+// It returns the recovery codes in clear, only their hashes are stored.
+func (i impl) ConfirmMFA(ctx context.Context, userID int64, code string) ([]string, error) {
+	mfa, err := i.repo.MFA().Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoMFA.ErrNotFound) {
+			return nil, pkgerrors.WithStack(ErrMFANotEnrolled)
+		}
+		return nil, err
+	}
+	if mfa.IsEnabled() {
+		return nil, pkgerrors.WithStack(ErrMFAAlreadyEnabled)
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for j := 0; j < recoveryCodeCount; j++ {
+		recoveryCode, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, recoveryCode)
+		hashes = append(hashes, utils.HashToken(normalizeRecoveryCode(recoveryCode)))
+	}
+
+	now := time.Now()
+	err = i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		// Recovery codes do not exist yet, only a TOTP code confirms the enrollment
+		if err := i.verifyMFACode(ctx, txRepo, mfa, code, now, false); err != nil {
+			return err
+		}
+
+		if err := txRepo.MFA().Enable(ctx, userID, now); err != nil {
+			return err
+		}
+
+		return txRepo.MFA().ReplaceRecoveryCodes(ctx, userID, hashes)
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	logSecurityEvent(ctx, "mfa_enabled", []string{"user:" + strconv.FormatInt(userID, 10)})
+	return codes, nil
+}
+
+// DisableMFA removes the second factor of the user. A current TOTP or recovery code is required
+// so a stolen access token alone cannot turn it off.
+func (i impl) DisableMFA(ctx context.Context, userID int64, code string) error {
+	mfa, err := i.repo.MFA().Get(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoMFA.ErrNotFound) {
+			return pkgerrors.WithStack(ErrMFANotEnrolled)
+		}
+		return err
+	}
+
+	now := time.Now()
+	mfaKey := mfaLockoutKey(userID)
+	if err := i.checkLockout(ctx, now, mfaKey); err != nil {
+		return err
+	}
+
+	err = i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		if err := i.verifyMFACode(ctx, txRepo, mfa, code, now, mfa.IsEnabled()); err != nil {
+			return err
+		}
+
+		return txRepo.MFA().Delete(ctx, userID)
+	}, nil)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			if recordErr := i.recordLoginFailure(ctx, now, mfaKey, ""); recordErr != nil {
+				return recordErr
+			}
+		}
+		return err
+	}
+
+	logSecurityEvent(ctx, "mfa_disabled", []string{"user:" + strconv.FormatInt(userID, 10)})
+	return nil
+}
+
+// verifyMFACode accepts a TOTP code at most once, or an unused recovery code when allowed
+func (i impl) verifyMFACode(ctx context.Context, repo repository.Registry, mfa model.MFA, code string, now time.Time, allowRecovery bool) error {
+	secret, err := i.cipher.Decrypt(mfa.Secret)
+	if err != nil {
+		return err
+	}
+
+	if step, ok := totp.Validate(string(secret), code, now, 1); ok {
+		// A code seen once, even by another request, cannot be replayed
+		if err := repo.MFA().UseStep(ctx, mfa.UserID, step); err != nil {
+			if errors.Is(err, repoMFA.ErrStepAlreadyUsed) {
+				return pkgerrors.WithStack(ErrInvalidMFACode)
+			}
+			return err
+		}
+		return nil
+	}
+
+	if !allowRecovery {
+		return pkgerrors.WithStack(ErrInvalidMFACode)
+	}
+
+	if err := repo.MFA().UseRecoveryCode(ctx, mfa.UserID, utils.HashToken(normalizeRecoveryCode(code))); err != nil {
+		if errors.Is(err, repoMFA.ErrNotFound) {
+			return pkgerrors.WithStack(ErrInvalidMFACode)
+		}
+		return err
+	}
+	logSecurityEvent(ctx, "mfa_recovery_code_used", []string{"user:" + strconv.FormatInt(mfa.UserID, 10)})
+	return nil
+}
+
+// mfaLockoutKey returns the key wrong second factor codes of a user are counted under
+func mfaLockoutKey(userID int64) string {
+	return "mfa:" + strconv.FormatInt(userID, 10)
+}
+
+// generateRecoveryCode returns a code formatted as two groups of five characters, e.g. abcde-fghij
+func generateRecoveryCode() (string, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", err
+	}
+	code := strings.ToLower(secret[:10])
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode lets users type recovery codes without the dash or in upper case
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// mfaIssuer returns the name authenticator apps show next to the account
+func mfaIssuer() string {
+	if issuer := config.GetConfig().GetString("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+	if u, err := url.Parse(config.GetConfig().GetString("APP_BASE_URL")); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return defaultMFAIssuer
+}
@@ -3,26 +3,132 @@ package auth
 import (
 	"context"
 
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/encryption"
+	"github.com/namf2001/go-backend-template/internal/pkg/mailer"
+	"github.com/namf2001/go-backend-template/internal/pkg/password"
 	"github.com/namf2001/go-backend-template/internal/repository"
+	"github.com/namf2001/go-backend-template/internal/repository/loginattempts"
 )
 
 type Controller interface {
 	// Login handles manual login
-	Login(ctx context.Context, input ValidationInput) (string, error)
+	Login(ctx context.Context, input ValidationInput) (Tokens, error)
 
 	// Register handles manual registration
-	Register(ctx context.Context, input RegisterInput) (string, error)
+	Register(ctx context.Context, input RegisterInput) (Tokens, error)
 
-	// OAuthLogin handles oauth login/registration
-	OAuthLogin(ctx context.Context, input OAuthInput) (string, error)
+	// OAuthLogin handles oauth login/registration, asking for the second factor like Login
+	OAuthLogin(ctx context.Context, input OAuthInput) (Tokens, error)
+
+	// LinkAccount links a provider account to a signed in user
+	LinkAccount(ctx context.Context, userID int64, input OAuthInput) (model.Account, error)
+
+	// Refresh rotates a refresh token and issues a new token pair
+	Refresh(ctx context.Context, refreshToken string) (Tokens, error)
+
+	// ValidateSession checks that the session an access token was issued for is still active
+	ValidateSession(ctx context.Context, sessionID string) error
+
+	// Logout revokes the given session
+	Logout(ctx context.Context, sessionID string) error
+
+	// LogoutAll revokes every session of a user
+	LogoutAll(ctx context.Context, userID int64) error
+
+	// ListSessions lists the active sessions of a user
+	ListSessions(ctx context.Context, userID int64) ([]model.Session, error)
+
+	// RevokeSession revokes one of the user's own sessions
+	RevokeSession(ctx context.Context, userID int64, sessionID string) error
+
+	// RequestEmailVerification sends a new verification link to the user's email
+	RequestEmailVerification(ctx context.Context, userID int64) error
+
+	// ConfirmEmail consumes a verification token and marks the user's email as verified
+	ConfirmEmail(ctx context.Context, email, token string) error
+
+	// ForgotPassword emails a password reset link if the email belongs to a user
+	ForgotPassword(ctx context.Context, email string) error
+
+	// ResetPassword sets a new password using a reset token and revokes every session of the user
+	ResetPassword(ctx context.Context, input ResetPasswordInput) error
+
+	// RequestMagicLink emails a one-time login link if the email belongs to a user
+	RequestMagicLink(ctx context.Context, email string) error
+
+	// MagicLinkLogin consumes a login link and logs its user in like Login
+	MagicLinkLogin(ctx context.Context, email, token string) (Tokens, error)
+
+	// VerifyMFA exchanges an mfa_pending token and a second factor code for tokens
+	VerifyMFA(ctx context.Context, input VerifyMFAInput) (Tokens, error)
+
+	// EnrollMFA generates a new TOTP secret for the user, enabled once confirmed
+	EnrollMFA(ctx context.Context, userID int64) (MFAEnrollment, error)
+
+	// ConfirmMFA enables the enrolled second factor and returns single use recovery codes
+	ConfirmMFA(ctx context.Context, userID int64, code string) ([]string, error)
+
+	// DisableMFA removes the second factor of the user after checking a current code
+	DisableMFA(ctx context.Context, userID int64, code string) error
+
+	// BeginPasskeyRegistration returns the options to create a passkey for a signed in user
+	BeginPasskeyRegistration(ctx context.Context, userID int64) (*protocol.CredentialCreation, error)
+
+	// FinishPasskeyRegistration verifies and stores the passkey created by the authenticator
+	FinishPasskeyRegistration(ctx context.Context, userID int64, name string, response *protocol.ParsedCredentialCreationData) (model.WebAuthnCredential, error)
+
+	// BeginPasskeyLogin returns the options to sign in with any passkey of the relying party
+	BeginPasskeyLogin(ctx context.Context) (*protocol.CredentialAssertion, error)
+
+	// FinishPasskeyLogin verifies the assertion of a passkey and issues tokens for its user
+	FinishPasskeyLogin(ctx context.Context, response *protocol.ParsedCredentialAssertionData) (Tokens, error)
+
+	// ListPasskeys lists the passkeys of a user
+	ListPasskeys(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error)
+
+	// DeletePasskey deletes a passkey of a user, unless it is the user's last way to sign in
+	DeletePasskey(ctx context.Context, userID, id int64) error
+
+	// CreateAPIKey creates an API key for a user and returns its secret, shown only once
+	CreateAPIKey(ctx context.Context, input CreateAPIKeyInput) (NewAPIKey, error)
+
+	// ListAPIKeys lists the API keys of a user that are not revoked
+	ListAPIKeys(ctx context.Context, userID int64) ([]model.APIKey, error)
+
+	// RevokeAPIKey revokes an API key of a user
+	RevokeAPIKey(ctx context.Context, userID, id int64) error
+
+	// AuthenticateAPIKey resolves the user and permissions of an API key and records its use
+	AuthenticateAPIKey(ctx context.Context, key string) (APIKeyPrincipal, error)
 }
 
 type impl struct {
-	repo repository.Registry
+	repo      repository.Registry
+	mailer    mailer.Mailer
+	attempts  loginattempts.Repository // Failed logins and login link requests, in Postgres or in memory
+	lockout   lockoutPolicy
+	magicLink magicLinkPolicy
+	dummyHash func() string      // Verified instead of a missing hash, see newDummyHash
+	cipher    *encryption.Cipher // Encrypts TOTP secrets at rest
+	webauthn  *webauthn.WebAuthn // Passkey relying party
+	passwords password.Hasher
+	policy    password.Policy // Rules new passwords must follow
 }
 
-func New(repo repository.Registry) Controller {
-	return impl{
-		repo: repo,
-	}
+func New(repo repository.Registry, mailer mailer.Mailer, attempts loginattempts.Repository, cipher *encryption.Cipher, relyingParty *webauthn.WebAuthn, passwords password.Hasher, policy password.Policy) Controller {
+	return traced{next: impl{
+		repo:      repo,
+		mailer:    mailer,
+		attempts:  attempts,
+		lockout:   newLockoutPolicy(),
+		magicLink: newMagicLinkPolicy(),
+		dummyHash: newDummyHash(passwords),
+		cipher:    cipher,
+		webauthn:  relyingParty,
+		passwords: passwords,
+		policy:    policy,
+	}}
 }
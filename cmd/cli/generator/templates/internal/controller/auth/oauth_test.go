@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthLogin_NewUser(t *testing.T) {
+	config.Init("test")
+	config.GetConfig().Set("JWT_SECRET", "test-secret")
+	config.GetConfig().Set("APP_BASE_URL", "http://localhost:8080")
+
+	type args struct {
+		givenUser       bool // A user already has the email
+		givenUnverified bool // The provider did not verify the email
+		givenAccountErr error
+		expErr          error
+		expUsers        int
+		expAccounts     int
+	}
+	tcs := map[string]args{
+		"success - new user": {
+			expUsers:    1,
+			expAccounts: 1,
+		},
+		"success - linked to the user with the email": {
+			givenUser:   true,
+			expUsers:    1,
+			expAccounts: 1,
+		},
+		"err - new user with an unverified email": {
+			givenUnverified: true,
+			expErr:          ErrOAuthEmailNotVerified,
+		},
+		"err - user with the email and an unverified email": {
+			givenUser:       true,
+			givenUnverified: true,
+			expErr:          ErrOAuthEmailNotVerified,
+			expUsers:        1,
+		},
+		"err - linking the account fails": {
+			givenAccountErr: errors.New("connection reset"),
+			expErr:          errors.New("connection reset"),
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			repo := newFakeRegistry()
+			repo.accounts.err = tc.givenAccountErr
+			if tc.givenUser {
+				repo.users.users[1] = model.User{ID: 1, Email: "oauth@example.com"}
+			}
+			i := impl{repo: repo}
+
+			// When
+			tokens, err := i.OAuthLogin(context.Background(), OAuthInput{
+				Provider:          model.ProviderOIDC,
+				ProviderAccountID: "abc",
+				Email:             "oauth@example.com",
+				Name:              "OAuth User",
+				EmailVerified:     !tc.givenUnverified,
+			})
+
+			// Then
+			require.Len(t, repo.users.users, tc.expUsers)
+			require.Len(t, repo.accounts.accounts, tc.expAccounts)
+			if tc.expErr != nil {
+				require.ErrorContains(t, err, tc.expErr.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, tokens.AccessToken)
+			require.Equal(t, int64(1), repo.accounts.accounts[0].UserID)
+			if !tc.givenUser {
+				require.NotNil(t, repo.users.users[1].EmailVerified)
+			}
+		})
+	}
+}
+
+func TestOAuthLogin_MFA(t *testing.T) {
+	config.Init("test")
+	config.GetConfig().Set("JWT_SECRET", "test-secret")
+	config.GetConfig().Set("APP_BASE_URL", "http://localhost:8080")
+
+	type args struct {
+		givenLinked     bool // The provider account is already linked to the user
+		givenMFAEnabled bool
+		expMFA          bool // An mfa_pending token instead of a session
+	}
+	tcs := map[string]args{
+		"success - linked account without mfa": {
+			givenLinked: true,
+		},
+		"success - linked account with mfa": {
+			givenLinked:     true,
+			givenMFAEnabled: true,
+			expMFA:          true,
+		},
+		"success - account linked on the verified email with mfa": {
+			givenMFAEnabled: true,
+			expMFA:          true,
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			repo := newFakeRegistry()
+			repo.users.users[42] = model.User{ID: 42, Email: "oauth@example.com"}
+			if tc.givenLinked {
+				repo.accounts.accounts = []model.Account{{UserID: 42, Provider: model.ProviderGoogle, ProviderAccountID: "abc"}}
+			}
+			if tc.givenMFAEnabled {
+				enabledAt := time.Now()
+				repo.mfa.mfa[42] = model.MFA{UserID: 42, EnabledAt: &enabledAt}
+			}
+			i := impl{repo: repo}
+
+			// When
+			tokens, err := i.OAuthLogin(context.Background(), OAuthInput{
+				Provider:          model.ProviderGoogle,
+				ProviderAccountID: "abc",
+				Email:             "oauth@example.com",
+				EmailVerified:     true,
+			})
+
+			// Then
+			require.NoError(t, err)
+			if tc.expMFA {
+				require.NotEmpty(t, tokens.MFAToken)
+				require.Empty(t, tokens.AccessToken)
+				require.Empty(t, repo.sessions.sessions)
+				return
+			}
+			require.Empty(t, tokens.MFAToken)
+			require.NotEmpty(t, tokens.AccessToken)
+			require.Len(t, repo.sessions.sessions, 1)
+		})
+	}
+}
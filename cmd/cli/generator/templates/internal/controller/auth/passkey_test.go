@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/passkey"
+	"github.com/namf2001/go-backend-template/internal/pkg/passkey/passkeytest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCeremonySession(t *testing.T) {
+	rp, err := passkey.New(passkey.Config{RPID: "example.com", RPName: "Example", RPOrigins: []string{"https://example.com"}})
+	require.NoError(t, err)
+	i := impl{webauthn: rp}
+
+	user := passkey.User{User: model.User{ID: 42, Email: "passkey@example.com"}}
+	authenticator := passkeytest.New("https://example.com")
+
+	// Registration, verified against a session rebuilt from the challenge only
+	creation, _, err := rp.BeginRegistration(user)
+	require.NoError(t, err)
+	body, err := authenticator.Register(creation)
+	require.NoError(t, err)
+	created, err := protocol.ParseCredentialCreationResponseBytes(body)
+	require.NoError(t, err)
+
+	challenge := created.Response.CollectedClientData.Challenge
+	require.Equal(t, creation.Response.Challenge.String(), challenge)
+	credential, err := rp.CreateCredential(user, i.ceremonySession(challenge, user.WebAuthnID(), true), created)
+	require.NoError(t, err)
+	user.Credentials = []model.WebAuthnCredential{passkey.FromCredential(*credential)}
+
+	// Discoverable login
+	assertion, _, err := rp.BeginDiscoverableLogin()
+	require.NoError(t, err)
+	body, err = authenticator.Login(assertion)
+	require.NoError(t, err)
+	asserted, err := protocol.ParseCredentialRequestResponseBytes(body)
+	require.NoError(t, err)
+
+	session := i.ceremonySession(asserted.Response.CollectedClientData.Challenge, nil, false)
+	_, err = rp.ValidateDiscoverableLogin(func(_, _ []byte) (webauthn.User, error) { return user, nil }, session, asserted)
+	require.NoError(t, err)
+
+	// A response signed for another challenge is rejected
+	session.Challenge = creation.Response.Challenge.String()
+	_, err = rp.ValidateDiscoverableLogin(func(_, _ []byte) (webauthn.User, error) { return user, nil }, session, asserted)
+	require.Error(t, err)
+}
+
+func TestInvalidPasskey(t *testing.T) {
+	err := invalidPasskey(protocol.ErrVerification.WithDetails("Error validating origin"))
+	require.ErrorIs(t, err, ErrInvalidPasskey)
+	require.Contains(t, err.Error(), "Error validating origin")
+}
+
+func TestFinishPasskeyLogin(t *testing.T) {
+	config.Init("test")
+	config.GetConfig().Set("JWT_SECRET", "test-secret")
+	config.GetConfig().Set("APP_BASE_URL", "http://localhost:8080")
+
+	type args struct {
+		givenNoUV       bool
+		givenMFAEnabled bool
+		expMFA          bool // An mfa_pending token instead of a session
+	}
+	tcs := map[string]args{
+		"success - user verified": {
+			givenMFAEnabled: true,
+		},
+		"success - no user verification without mfa": {
+			givenNoUV: true,
+		},
+		"success - no user verification with mfa": {
+			givenNoUV:       true,
+			givenMFAEnabled: true,
+			expMFA:          true,
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			rp, err := passkey.New(passkey.Config{RPID: "example.com", RPName: "Example", RPOrigins: []string{"https://example.com"}})
+			require.NoError(t, err)
+			repo := newFakeRegistry()
+			i := impl{repo: repo, webauthn: rp}
+
+			user := passkey.User{User: model.User{ID: 42, Email: "passkey@example.com"}}
+			repo.users.users[42] = user.User
+			if tc.givenMFAEnabled {
+				enabledAt := time.Now()
+				repo.mfa.mfa[42] = model.MFA{UserID: 42, EnabledAt: &enabledAt}
+			}
+
+			authenticator := passkeytest.New("https://example.com")
+			authenticator.NoUV = tc.givenNoUV
+			creation, session, err := rp.BeginRegistration(user)
+			require.NoError(t, err)
+			body, err := authenticator.Register(creation)
+			require.NoError(t, err)
+			created, err := protocol.ParseCredentialCreationResponseBytes(body)
+			require.NoError(t, err)
+			credential, err := rp.CreateCredential(user, *session, created)
+			require.NoError(t, err)
+			stored := passkey.FromCredential(*credential)
+			stored.ID, stored.UserID = 1, 42
+			repo.credentials.credentials = append(repo.credentials.credentials, stored)
+
+			assertion, err := i.BeginPasskeyLogin(context.Background())
+			require.NoError(t, err)
+			body, err = authenticator.Login(assertion)
+			require.NoError(t, err)
+			asserted, err := protocol.ParseCredentialRequestResponseBytes(body)
+			require.NoError(t, err)
+
+			// When
+			tokens, err := i.FinishPasskeyLogin(context.Background(), asserted)
+
+			// Then
+			require.NoError(t, err)
+			if tc.expMFA {
+				require.NotEmpty(t, tokens.MFAToken)
+				require.Empty(t, tokens.AccessToken)
+				require.Empty(t, repo.sessions.sessions)
+				return
+			}
+			require.Empty(t, tokens.MFAToken)
+			require.NotEmpty(t, tokens.AccessToken)
+			require.Len(t, repo.sessions.sessions, 1)
+		})
+	}
+}
+
+func TestDeletePasskey(t *testing.T) {
+	type args struct {
+		givenPassword    string
+		givenCredentials int
+		givenAccounts    []model.Account
+		expErr           error
+		expCredentials   int
+		expAccounts      int
+	}
+	webauthnAccount := model.Account{UserID: 42, Provider: model.ProviderWebAuthn, ProviderAccountID: "42"}
+	tcs := map[string]args{
+		"success - another passkey left": {
+			givenCredentials: 2,
+			givenAccounts:    []model.Account{webauthnAccount},
+			expCredentials:   1,
+			expAccounts:      1,
+		},
+		"success - password and the passkey": {
+			givenPassword:    "hash",
+			givenCredentials: 1,
+			givenAccounts:    []model.Account{webauthnAccount},
+		},
+		"err - passkey is the last sign-in method": {
+			givenCredentials: 1,
+			givenAccounts:    []model.Account{webauthnAccount},
+			expErr:           ErrLastSignInMethod,
+			expCredentials:   1,
+			expAccounts:      1,
+		},
+		"err - accounts without a provider are no sign-in method": {
+			givenCredentials: 1,
+			givenAccounts:    []model.Account{{UserID: 42, Type: "personal"}, webauthnAccount},
+			expErr:           ErrLastSignInMethod,
+			expCredentials:   1,
+			expAccounts:      2,
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			repo := newFakeRegistry()
+			repo.users.users[42] = model.User{ID: 42, Email: "passkey@example.com", Password: tc.givenPassword}
+			repo.accounts.accounts = tc.givenAccounts
+			for id := 1; id <= tc.givenCredentials; id++ {
+				repo.credentials.credentials = append(repo.credentials.credentials, model.WebAuthnCredential{ID: int64(id), UserID: 42})
+			}
+			i := impl{repo: repo}
+
+			// When
+			err := i.DeletePasskey(context.Background(), 42, 1)
+
+			// Then
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, repo.accounts.accounts, tc.expAccounts)
+			if tc.expAccounts == 0 {
+				// The database deletes the passkeys with their account
+				return
+			}
+			require.Len(t, repo.credentials.credentials, tc.expCredentials)
+		})
+	}
+}
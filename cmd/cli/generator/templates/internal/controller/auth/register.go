@@ -4,9 +4,10 @@ import (
 	"context"
 
 	"github.com/namf2001/go-backend-template/internal/model"
-	"github.com/namf2001/go-backend-template/internal/pkg/jwt"
-	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
 	"github.com/namf2001/go-backend-template/internal/repository"
+	"go.uber.org/zap"
 )
 
 type RegisterInput struct {
@@ -16,14 +17,26 @@ type RegisterInput struct {
 }
 
 // Register performs manual registration
-func (i impl) Register(ctx context.Context, input RegisterInput) (string, error) {
-	// 1. Hash password
-	hashedPassword, err := utils.HashPassword(input.Password)
+func (i impl) Register(ctx context.Context, input RegisterInput) (tokens Tokens, err error) {
+	defer func() {
+		result := metrics.ResultSuccess
+		if err != nil {
+			result = metrics.ResultFailure
+		}
+		metrics.AuthRegistrations.WithLabelValues(metrics.MethodPassword, result).Inc()
+	}()
+
+	// 1. Check and hash password
+	if err := i.policy.Check(input.Password, input.Email, input.Name); err != nil {
+		return Tokens{}, err
+	}
+	hashedPassword, err := i.passwords.Hash(input.Password)
 	if err != nil {
-		return "", err
+		return Tokens{}, err
 	}
 
-	// 2. Create user + account in a single transaction
+	// 2. Create user + default role in a single transaction. The password is the user's sign-in method,
+	// accounts are only for providers.
 	user := model.User{
 		Name:     input.Name,
 		Email:    input.Email,
@@ -38,21 +51,18 @@ func (i impl) Register(ctx context.Context, input RegisterInput) (string, error)
 			return txErr
 		}
 
-		_, txErr = txRepo.Account().Create(ctx, model.Account{
-			UserID: createdUser.ID,
-			Type:   "personal",
-		})
-		return txErr
+		return txRepo.Role().AssignToUser(ctx, createdUser.ID, model.RoleUser)
 	}, nil)
 	if err != nil {
-		return "", err
+		return Tokens{}, err
 	}
 
-	// 3. Login (generate token)
-	token, err := jwt.GenerateToken(createdUser.ID, createdUser.Email)
-	if err != nil {
-		return "", err
+	// 3. Send the email verification link. Registration still succeeds if this fails,
+	// the user can ask for a new link later.
+	if err := i.sendEmailVerification(ctx, createdUser); err != nil {
+		logger.Error(ctx, "send email verification failed", zap.Error(err))
 	}
 
-	return token, nil
+	// 4. Login (issue tokens)
+	return issueTokens(ctx, i.repo, createdUser, "")
 }
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/pkg/mailer"
+	"github.com/namf2001/go-backend-template/internal/pkg/password"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	config.Init("test")
+	config.GetConfig().Set("JWT_SECRET", "test-secret")
+	config.GetConfig().Set("APP_BASE_URL", "http://localhost:8080")
+
+	// Given
+	repo := newFakeRegistry()
+	var sent []mailer.Message
+	i := impl{repo: repo, mailer: fakeMailer{sent: &sent}, passwords: password.Bcrypt{Cost: 4}}
+
+	// When
+	first, err := i.Register(context.Background(), RegisterInput{Name: "First", Email: "first@example.com", Password: "first-s3cret-password"})
+	require.NoError(t, err)
+	second, err := i.Register(context.Background(), RegisterInput{Name: "Second", Email: "second@example.com", Password: "second-s3cret-password"})
+	require.NoError(t, err)
+
+	// Then
+	require.NotEmpty(t, first.AccessToken)
+	require.NotEmpty(t, second.AccessToken)
+	require.Len(t, repo.users.users, 2)
+	// The password is the sign-in method, no account is linked
+	require.Empty(t, repo.accounts.accounts)
+	require.Len(t, sent, 2)
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// ValidateSession checks that the session an access token was issued for has not been revoked
+func (i impl) ValidateSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return pkgerrors.WithStack(ErrSessionRevoked)
+	}
+
+	active, err := i.repo.Session().IsFamilyActive(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if !active {
+		return pkgerrors.WithStack(ErrSessionRevoked)
+	}
+
+	return nil
+}
+
+// Logout revokes the given session
+func (i impl) Logout(ctx context.Context, sessionID string) error {
+	return i.repo.Session().RevokeFamily(ctx, sessionID)
+}
+
+// LogoutAll revokes every session of a user
+func (i impl) LogoutAll(ctx context.Context, userID int64) error {
+	return i.repo.Session().RevokeByUserID(ctx, userID)
+}
+
+// ListSessions lists the active sessions of a user
+func (i impl) ListSessions(ctx context.Context, userID int64) ([]model.Session, error) {
+	return i.repo.Session().ListActiveByUserID(ctx, userID)
+}
+
+// RevokeSession revokes one of the user's own sessions
+func (i impl) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	sessions, err := i.repo.Session().ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sessions {
+		if s.FamilyID == sessionID {
+			return i.repo.Session().RevokeFamily(ctx, sessionID)
+		}
+	}
+
+	return pkgerrors.WithStack(ErrSessionNotFound)
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/jwt"
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	"github.com/namf2001/go-backend-template/internal/repository"
+)
+
+const defaultRefreshDuration = 30 * 24 * time.Hour
+
+// Tokens is the set of credentials issued after a successful authentication.
+// When the user still has to enter a second factor only MFAToken is set.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	MFAToken     string
+	ExpiresIn    time.Duration
+}
+
+// issueTokens stores a new refresh token for the user and signs an access token bound to its family
+// that carries the user's current roles and permissions.
+// An empty familyID starts a new family, i.e. a new login session.
+func issueTokens(ctx context.Context, repo repository.Registry, user model.User, familyID string) (Tokens, error) {
+	if familyID == "" {
+		var err error
+		if familyID, err = utils.GenerateRandomToken(16); err != nil {
+			return Tokens{}, err
+		}
+	}
+
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	if _, err = repo.Session().Create(ctx, model.Session{
+		UserID:       user.ID,
+		Expires:      time.Now().Add(durationFromConfig("JWT_REFRESH_DURATION", defaultRefreshDuration)),
+		SessionToken: utils.HashToken(refreshToken),
+		FamilyID:     familyID,
+	}); err != nil {
+		return Tokens{}, err
+	}
+
+	roles, err := repo.Role().ListByUserID(ctx, user.ID)
+	if err != nil {
+		return Tokens{}, err
+	}
+	roleNames := make([]string, 0, len(roles))
+	for _, r := range roles {
+		roleNames = append(roleNames, r.Name)
+	}
+
+	permissions, err := repo.Role().ListPermissionsByUserID(ctx, user.ID)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	accessToken, err := jwt.GenerateToken(jwt.Subject{
+		UserID:      user.ID,
+		Email:       user.Email,
+		SessionID:   familyID,
+		Roles:       roleNames,
+		Permissions: permissions,
+	})
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    jwt.AccessDuration(),
+	}, nil
+}
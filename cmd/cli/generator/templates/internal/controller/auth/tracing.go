@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/tracing"
+)
+
+// traced records a span around each call of the Controller it wraps
+type traced struct {
+	next Controller
+}
+
+func (t traced) Login(ctx context.Context, input ValidationInput) (Tokens, error) {
+	ctx, span := tracing.Start(ctx, "auth.Login")
+	tokens, err := t.next.Login(ctx, input)
+	tracing.End(span, err)
+	return tokens, err
+}
+
+func (t traced) Register(ctx context.Context, input RegisterInput) (Tokens, error) {
+	ctx, span := tracing.Start(ctx, "auth.Register")
+	tokens, err := t.next.Register(ctx, input)
+	tracing.End(span, err)
+	return tokens, err
+}
+
+func (t traced) OAuthLogin(ctx context.Context, input OAuthInput) (Tokens, error) {
+	ctx, span := tracing.Start(ctx, "auth.OAuthLogin")
+	tokens, err := t.next.OAuthLogin(ctx, input)
+	tracing.End(span, err)
+	return tokens, err
+}
+
+func (t traced) LinkAccount(ctx context.Context, userID int64, input OAuthInput) (model.Account, error) {
+	ctx, span := tracing.Start(ctx, "auth.LinkAccount")
+	account, err := t.next.LinkAccount(ctx, userID, input)
+	tracing.End(span, err)
+	return account, err
+}
+
+func (t traced) Refresh(ctx context.Context, refreshToken string) (Tokens, error) {
+	ctx, span := tracing.Start(ctx, "auth.Refresh")
+	tokens, err := t.next.Refresh(ctx, refreshToken)
+	tracing.End(span, err)
+	return tokens, err
+}
+
+func (t traced) ValidateSession(ctx context.Context, sessionID string) error {
+	ctx, span := tracing.Start(ctx, "auth.ValidateSession")
+	err := t.next.ValidateSession(ctx, sessionID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) Logout(ctx context.Context, sessionID string) error {
+	ctx, span := tracing.Start(ctx, "auth.Logout")
+	err := t.next.Logout(ctx, sessionID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) LogoutAll(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "auth.LogoutAll")
+	err := t.next.LogoutAll(ctx, userID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) ListSessions(ctx context.Context, userID int64) ([]model.Session, error) {
+	ctx, span := tracing.Start(ctx, "auth.ListSessions")
+	sessions, err := t.next.ListSessions(ctx, userID)
+	tracing.End(span, err)
+	return sessions, err
+}
+
+func (t traced) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	ctx, span := tracing.Start(ctx, "auth.RevokeSession")
+	err := t.next.RevokeSession(ctx, userID, sessionID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) RequestEmailVerification(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "auth.RequestEmailVerification")
+	err := t.next.RequestEmailVerification(ctx, userID)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) ConfirmEmail(ctx context.Context, email, token string) error {
+	ctx, span := tracing.Start(ctx, "auth.ConfirmEmail")
+	err := t.next.ConfirmEmail(ctx, email, token)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) ForgotPassword(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "auth.ForgotPassword")
+	err := t.next.ForgotPassword(ctx, email)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) ResetPassword(ctx context.Context, input ResetPasswordInput) error {
+	ctx, span := tracing.Start(ctx, "auth.ResetPassword")
+	err := t.next.ResetPassword(ctx, input)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) RequestMagicLink(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "auth.RequestMagicLink")
+	err := t.next.RequestMagicLink(ctx, email)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) MagicLinkLogin(ctx context.Context, email, token string) (Tokens, error) {
+	ctx, span := tracing.Start(ctx, "auth.MagicLinkLogin")
+	tokens, err := t.next.MagicLinkLogin(ctx, email, token)
+	tracing.End(span, err)
+	return tokens, err
+}
+
+func (t traced) VerifyMFA(ctx context.Context, input VerifyMFAInput) (Tokens, error) {
+	ctx, span := tracing.Start(ctx, "auth.VerifyMFA")
+	tokens, err := t.next.VerifyMFA(ctx, input)
+	tracing.End(span, err)
+	return tokens, err
+}
+
+func (t traced) EnrollMFA(ctx context.Context, userID int64) (MFAEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "auth.EnrollMFA")
+	enrollment, err := t.next.EnrollMFA(ctx, userID)
+	tracing.End(span, err)
+	return enrollment, err
+}
+
+func (t traced) ConfirmMFA(ctx context.Context, userID int64, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "auth.ConfirmMFA")
+	codes, err := t.next.ConfirmMFA(ctx, userID, code)
+	tracing.End(span, err)
+	return codes, err
+}
+
+func (t traced) DisableMFA(ctx context.Context, userID int64, code string) error {
+	ctx, span := tracing.Start(ctx, "auth.DisableMFA")
+	err := t.next.DisableMFA(ctx, userID, code)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) BeginPasskeyRegistration(ctx context.Context, userID int64) (*protocol.CredentialCreation, error) {
+	ctx, span := tracing.Start(ctx, "auth.BeginPasskeyRegistration")
+	creation, err := t.next.BeginPasskeyRegistration(ctx, userID)
+	tracing.End(span, err)
+	return creation, err
+}
+
+func (t traced) FinishPasskeyRegistration(ctx context.Context, userID int64, name string, response *protocol.ParsedCredentialCreationData) (model.WebAuthnCredential, error) {
+	ctx, span := tracing.Start(ctx, "auth.FinishPasskeyRegistration")
+	credential, err := t.next.FinishPasskeyRegistration(ctx, userID, name, response)
+	tracing.End(span, err)
+	return credential, err
+}
+
+func (t traced) BeginPasskeyLogin(ctx context.Context) (*protocol.CredentialAssertion, error) {
+	ctx, span := tracing.Start(ctx, "auth.BeginPasskeyLogin")
+	assertion, err := t.next.BeginPasskeyLogin(ctx)
+	tracing.End(span, err)
+	return assertion, err
+}
+
+func (t traced) FinishPasskeyLogin(ctx context.Context, response *protocol.ParsedCredentialAssertionData) (Tokens, error) {
+	ctx, span := tracing.Start(ctx, "auth.FinishPasskeyLogin")
+	tokens, err := t.next.FinishPasskeyLogin(ctx, response)
+	tracing.End(span, err)
+	return tokens, err
+}
+
+func (t traced) ListPasskeys(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error) {
+	ctx, span := tracing.Start(ctx, "auth.ListPasskeys")
+	credentials, err := t.next.ListPasskeys(ctx, userID)
+	tracing.End(span, err)
+	return credentials, err
+}
+
+func (t traced) DeletePasskey(ctx context.Context, userID, id int64) error {
+	ctx, span := tracing.Start(ctx, "auth.DeletePasskey")
+	err := t.next.DeletePasskey(ctx, userID, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) CreateAPIKey(ctx context.Context, input CreateAPIKeyInput) (NewAPIKey, error) {
+	ctx, span := tracing.Start(ctx, "auth.CreateAPIKey")
+	key, err := t.next.CreateAPIKey(ctx, input)
+	tracing.End(span, err)
+	return key, err
+}
+
+func (t traced) ListAPIKeys(ctx context.Context, userID int64) ([]model.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "auth.ListAPIKeys")
+	keys, err := t.next.ListAPIKeys(ctx, userID)
+	tracing.End(span, err)
+	return keys, err
+}
+
+func (t traced) RevokeAPIKey(ctx context.Context, userID, id int64) error {
+	ctx, span := tracing.Start(ctx, "auth.RevokeAPIKey")
+	err := t.next.RevokeAPIKey(ctx, userID, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) AuthenticateAPIKey(ctx context.Context, key string) (APIKeyPrincipal, error) {
+	ctx, span := tracing.Start(ctx, "auth.AuthenticateAPIKey")
+	principal, err := t.next.AuthenticateAPIKey(ctx, key)
+	tracing.End(span, err)
+	return principal, err
+}
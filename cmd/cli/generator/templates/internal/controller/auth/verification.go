@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	"github.com/namf2001/go-backend-template/internal/repository/verificationtokens"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const defaultBaseURL = "http://localhost:8080"
+
+// verificationIdentifier scopes a verification token to a purpose and an email,
+// so a token issued for one flow cannot be used in another
+func verificationIdentifier(purpose, email string) string {
+	return purpose + ":" + email
+}
+
+// issueVerificationToken replaces any pending token for the purpose and email with a new one and returns it.
+// Only the hash of the token is stored.
+func (i impl) issueVerificationToken(ctx context.Context, purpose, email string, ttl time.Duration) (string, error) {
+	identifier := verificationIdentifier(purpose, email)
+	if err := i.repo.VerificationToken().DeleteByIdentifier(ctx, identifier); err != nil {
+		return "", err
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = i.repo.VerificationToken().Create(ctx, model.VerificationToken{
+		Identifier: identifier,
+		Expires:    time.Now().Add(ttl),
+		Token:      utils.HashToken(token),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeVerificationToken uses up a verification token, failing if it does not exist or has expired
+func (i impl) consumeVerificationToken(ctx context.Context, purpose, email, token string) error {
+	consumed, err := i.repo.VerificationToken().Consume(ctx, verificationIdentifier(purpose, email), utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, verificationtokens.ErrNotFound) {
+			return pkgerrors.WithStack(ErrInvalidVerificationToken)
+		}
+		return err
+	}
+
+	if !consumed.Expires.After(time.Now()) {
+		return pkgerrors.WithStack(ErrInvalidVerificationToken)
+	}
+
+	return nil
+}
+
+// buildLink returns an absolute link with the given query. A relative path is resolved against APP_BASE_URL.
+func buildLink(urlOrPath string, query url.Values) string {
+	if !strings.HasPrefix(urlOrPath, "http://") && !strings.HasPrefix(urlOrPath, "https://") {
+		baseURL := config.GetConfig().GetString("APP_BASE_URL")
+		if baseURL == "" {
+			baseURL = defaultBaseURL
+		}
+		urlOrPath = strings.TrimRight(baseURL, "/") + urlOrPath
+	}
+	return urlOrPath + "?" + query.Encode()
+}
+
+// durationFromConfig reads a duration from config, falling back to def when unset
+func durationFromConfig(key string, def time.Duration) time.Duration {
+	if d := config.GetConfig().GetDuration(key); d > 0 {
+		return d
+	}
+	return def
+}
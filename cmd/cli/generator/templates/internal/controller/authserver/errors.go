@@ -0,0 +1,21 @@
+package authserver
+
+import "errors"
+
+// The errors of RFC 6749 section 4.1.2.1 and 5.2, each handled as its error code
+var (
+	ErrInvalidRequest          = errors.New("invalid or missing request parameter")
+	ErrPKCERequired            = errors.New("a code_challenge with the S256 method is required")
+	ErrInvalidClient           = errors.New("unknown client or client authentication failed")
+	ErrInvalidRedirectURI      = errors.New("redirect_uri is not registered for the client")
+	ErrInvalidGrant            = errors.New("invalid, expired or already used authorization code or refresh token")
+	ErrUnauthorizedClient      = errors.New("client is not allowed to use this grant type")
+	ErrUnsupportedGrantType    = errors.New("unsupported grant type")
+	ErrUnsupportedResponseType = errors.New("unsupported response type")
+	ErrInvalidScope            = errors.New("scope is not allowed for the client")
+	ErrAccessDenied            = errors.New("the user denied the authorization request")
+	ErrUnsupportedTokenType    = errors.New("tokens of this type cannot be revoked")
+
+	ErrInvalidClientMetadata = errors.New("invalid client metadata")
+	ErrClientNotFound        = errors.New("oauth client not found")
+)
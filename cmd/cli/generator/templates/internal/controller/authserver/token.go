@@ -0,0 +1,262 @@
+package authserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/jwt"
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	"github.com/namf2001/go-backend-template/internal/repository"
+	"github.com/namf2001/go-backend-template/internal/repository/oauthcodes"
+	"github.com/namf2001/go-backend-template/internal/repository/sessions"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// TokenRequest holds the parameters of a token request (RFC 6749 sections 4.1.3, 4.4.2 and 6)
+type TokenRequest struct {
+	GrantType    string
+	Client       ClientCredentials
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string // Space separated, narrows the scopes of a refresh or client credentials grant
+}
+
+// Tokens are the tokens issued by the token endpoint. RefreshToken is empty when the client
+// is not allowed the refresh token grant.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
+	Scopes       []string
+}
+
+// Token implements Controller.
+func (i impl) Token(ctx context.Context, req TokenRequest) (Tokens, error) {
+	switch req.GrantType {
+	case model.GrantTypeAuthorizationCode, model.GrantTypeRefreshToken, model.GrantTypeClientCredentials:
+	default:
+		return Tokens{}, pkgerrors.WithStack(ErrUnsupportedGrantType)
+	}
+
+	client, err := i.authenticateClient(ctx, req.Client)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	if !client.AllowsGrant(req.GrantType) {
+		return Tokens{}, pkgerrors.WithStack(ErrUnauthorizedClient)
+	}
+
+	switch req.GrantType {
+	case model.GrantTypeAuthorizationCode:
+		return i.exchangeCode(ctx, client, req)
+	case model.GrantTypeRefreshToken:
+		return i.refresh(ctx, client, req)
+	default:
+		return i.clientCredentials(client, req)
+	}
+}
+
+// exchangeCode redeems an authorization code. The code is marked as used before it is checked, so a code
+// that was intercepted is useless even if the first exchange failed. A code presented again revokes the
+// tokens issued for it (RFC 6749 section 4.1.2), they may have been obtained by whoever intercepted it.
+func (i impl) exchangeCode(ctx context.Context, client model.OAuthClient, req TokenRequest) (Tokens, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return Tokens{}, pkgerrors.WithStack(ErrInvalidRequest)
+	}
+
+	familyID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	code, err := i.repo.OAuthCode().Redeem(ctx, utils.HashToken(req.Code), familyID)
+	if err != nil {
+		if errors.Is(err, oauthcodes.ErrNotFound) {
+			return Tokens{}, pkgerrors.WithStack(ErrInvalidGrant)
+		}
+		return Tokens{}, err
+	}
+
+	if code.UsedAt != nil {
+		return Tokens{}, i.revokeReusedFamily(ctx, code.FamilyID)
+	}
+
+	if code.ClientID != client.ClientID || !time.Now().Before(code.ExpiresAt) ||
+		(req.RedirectURI != "" && req.RedirectURI != code.RedirectURI) ||
+		!verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return Tokens{}, pkgerrors.WithStack(ErrInvalidGrant)
+	}
+
+	return i.issueUserTokens(ctx, i.repo, client, code.UserID, code.Scopes, familyID)
+}
+
+// refresh rotates a refresh token of the client like Refresh does for first-party sessions,
+// optionally narrowing its scopes
+func (i impl) refresh(ctx context.Context, client model.OAuthClient, req TokenRequest) (Tokens, error) {
+	if req.RefreshToken == "" {
+		return Tokens{}, pkgerrors.WithStack(ErrInvalidRequest)
+	}
+
+	hashedToken := utils.HashToken(req.RefreshToken)
+	session, err := i.repo.Session().GetByToken(ctx, hashedToken)
+	if err != nil {
+		if errors.Is(err, sessions.ErrNotFound) {
+			return Tokens{}, pkgerrors.WithStack(ErrInvalidGrant)
+		}
+		return Tokens{}, err
+	}
+
+	if session.ClientID != client.ClientID {
+		return Tokens{}, pkgerrors.WithStack(ErrInvalidGrant)
+	}
+
+	// A rotated token presented again was stolen from the client or the client is misbehaving
+	if session.RevokedAt != nil {
+		return Tokens{}, i.revokeReusedFamily(ctx, session.FamilyID)
+	}
+
+	if !session.IsActive(time.Now()) {
+		return Tokens{}, pkgerrors.WithStack(ErrInvalidGrant)
+	}
+
+	scopes, err := grantScopes(session.Scopes, req.Scope)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	var tokens Tokens
+	err = i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		if txErr := txRepo.Session().Revoke(ctx, hashedToken); txErr != nil {
+			return txErr
+		}
+
+		var txErr error
+		tokens, txErr = i.issueUserTokens(ctx, txRepo, client, session.UserID, scopes, session.FamilyID)
+		return txErr
+	}, nil)
+	if err != nil {
+		if errors.Is(err, sessions.ErrNotFound) {
+			// Another request rotated the same token first
+			return Tokens{}, i.revokeReusedFamily(ctx, session.FamilyID)
+		}
+		return Tokens{}, err
+	}
+
+	return tokens, nil
+}
+
+// clientCredentials issues an access token to the client itself, there is no user and no refresh token
+func (i impl) clientCredentials(client model.OAuthClient, req TokenRequest) (Tokens, error) {
+	scopes, err := grantScopes(client.Scopes, req.Scope)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	accessToken, err := jwt.GenerateClientToken(client.ClientID, scopes)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return Tokens{
+		AccessToken: accessToken,
+		ExpiresIn:   jwt.AccessDuration(),
+		Scopes:      scopes,
+	}, nil
+}
+
+// issueUserTokens issues an access token for the client to act on behalf of the user. The access token
+// is bound to a session in the family so revoking the grant also rejects its access tokens.
+func (i impl) issueUserTokens(ctx context.Context, repo repository.Registry, client model.OAuthClient, userID int64, scopes []string, familyID string) (Tokens, error) {
+	user, err := repo.User().GetByID(ctx, userID)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	permissions, err := repo.Role().ListPermissionsByUserID(ctx, user.ID)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	// Without the refresh token grant the session only lives as long as the access token
+	expires := time.Now().Add(i.refreshTTL)
+	if !client.AllowsGrant(model.GrantTypeRefreshToken) {
+		expires = time.Now().Add(jwt.AccessDuration())
+	}
+
+	if _, err := repo.Session().Create(ctx, model.Session{
+		UserID:       user.ID,
+		Expires:      expires,
+		SessionToken: utils.HashToken(refreshToken),
+		FamilyID:     familyID,
+		ClientID:     client.ClientID,
+		Scopes:       scopes,
+	}); err != nil {
+		return Tokens{}, err
+	}
+
+	// No roles, RequireRole would otherwise let the client past its scopes
+	accessToken, err := jwt.GenerateToken(jwt.Subject{
+		UserID:      user.ID,
+		Email:       user.Email,
+		SessionID:   familyID,
+		Permissions: scopedPermissions(permissions, scopes),
+		ClientID:    client.ClientID,
+		Scopes:      scopes,
+	})
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	tokens := Tokens{
+		AccessToken: accessToken,
+		ExpiresIn:   jwt.AccessDuration(),
+		Scopes:      scopes,
+	}
+	if client.AllowsGrant(model.GrantTypeRefreshToken) {
+		tokens.RefreshToken = refreshToken
+	}
+	return tokens, nil
+}
+
+// revokeReusedFamily revokes a token family after reuse was detected and returns ErrInvalidGrant
+func (i impl) revokeReusedFamily(ctx context.Context, familyID string) error {
+	if err := i.repo.Session().RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return pkgerrors.WithStack(ErrInvalidGrant)
+}
+
+// verifyCodeChallenge checks a PKCE code verifier against its S256 challenge (RFC 7636 section 4.6)
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+// scopedPermissions returns the permissions of the user the scopes allow.
+// Permissions the user lost since the grant are not given back by its scopes.
+func scopedPermissions(permissions, scopes []string) []string {
+	scoped := make([]string, 0, len(scopes))
+	for _, p := range permissions {
+		if slices.Contains(scopes, p) {
+			scoped = append(scoped, p)
+		}
+	}
+	return scoped
+}
@@ -0,0 +1,57 @@
+package users
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository"
+	repoAccounts "github.com/namf2001/go-backend-template/internal/repository/accounts"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// ListAccounts lists the provider accounts linked to a user
+func (i impl) ListAccounts(ctx context.Context, id int64) ([]model.Account, error) {
+	return i.repo.Account().GetByUserID(ctx, id)
+}
+
+// UnlinkAccount unlinks a provider from a user, unless it is the user's last way to sign in
+func (i impl) UnlinkAccount(ctx context.Context, id int64, provider model.Provider) error {
+	return i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		user, err := txRepo.User().GetByID(ctx, id)
+		if err != nil {
+			return pkgerrors.WithStack(err)
+		}
+
+		accounts, err := txRepo.Account().GetByUserID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		linked := false
+		for _, a := range accounts {
+			if a.Provider == provider {
+				linked = true
+				break
+			}
+		}
+		if !linked {
+			return pkgerrors.WithStack(repoAccounts.ErrNotFound)
+		}
+
+		// A password counts as a sign-in method, every linked provider as another one
+		signInMethods := 0
+		for _, a := range accounts {
+			if a.Provider != "" {
+				signInMethods++
+			}
+		}
+		if user.Password != "" {
+			signInMethods++
+		}
+		if signInMethods <= 1 {
+			return pkgerrors.WithStack(ErrLastSignInMethod)
+		}
+
+		return txRepo.Account().DeleteByUserID(ctx, id, provider)
+	}, nil)
+}
@@ -0,0 +1,107 @@
+package users
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository"
+	"github.com/namf2001/go-backend-template/internal/repository/accounts"
+	"github.com/namf2001/go-backend-template/internal/repository/users"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry keeps a user and their accounts in memory
+type fakeRegistry struct {
+	repository.Registry
+	user     fakeUsers
+	accounts *fakeAccounts
+}
+
+func (f fakeRegistry) User() users.Repository       { return f.user }
+func (f fakeRegistry) Account() accounts.Repository { return f.accounts }
+
+func (f fakeRegistry) DoInTx(ctx context.Context, txFunc func(ctx context.Context, txRepo repository.Registry) error, _ backoff.BackOff) error {
+	return txFunc(ctx, f)
+}
+
+type fakeUsers struct {
+	users.Repository
+	user model.User
+}
+
+func (f fakeUsers) GetByID(context.Context, int64) (model.User, error) {
+	return f.user, nil
+}
+
+type fakeAccounts struct {
+	accounts.Repository
+	accounts []model.Account
+}
+
+func (f *fakeAccounts) GetByUserID(context.Context, int64) ([]model.Account, error) {
+	return f.accounts, nil
+}
+
+func (f *fakeAccounts) DeleteByUserID(_ context.Context, _ int64, provider model.Provider) error {
+	f.accounts = slices.DeleteFunc(f.accounts, func(a model.Account) bool { return a.Provider == provider })
+	return nil
+}
+
+func TestUnlinkAccount(t *testing.T) {
+	type args struct {
+		givenPassword string
+		givenAccounts []model.Account
+		expErr        error
+		expAccounts   int
+	}
+	tcs := map[string]args{
+		"success - another provider left": {
+			givenAccounts: []model.Account{{Provider: model.ProviderGoogle}, {Provider: model.ProviderGitHub}},
+			expAccounts:   1,
+		},
+		"success - password and the provider": {
+			givenPassword: "hash",
+			givenAccounts: []model.Account{{Provider: model.ProviderGoogle}},
+		},
+		"err - provider is the last sign-in method": {
+			givenAccounts: []model.Account{{Provider: model.ProviderGoogle}},
+			expErr:        ErrLastSignInMethod,
+			expAccounts:   1,
+		},
+		"err - accounts without a provider are no sign-in method": {
+			givenAccounts: []model.Account{{Type: "personal"}, {Provider: model.ProviderGoogle}},
+			expErr:        ErrLastSignInMethod,
+			expAccounts:   2,
+		},
+		"err - provider not linked": {
+			givenPassword: "hash",
+			givenAccounts: []model.Account{{Provider: model.ProviderGitHub}},
+			expErr:        accounts.ErrNotFound,
+			expAccounts:   1,
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			repo := fakeRegistry{
+				user:     fakeUsers{user: model.User{ID: 1001, Password: tc.givenPassword}},
+				accounts: &fakeAccounts{accounts: tc.givenAccounts},
+			}
+			i := impl{repo: repo}
+
+			// When
+			err := i.UnlinkAccount(context.Background(), 1001, model.ProviderGoogle)
+
+			// Then
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, repo.accounts.accounts, tc.expAccounts)
+		})
+	}
+}
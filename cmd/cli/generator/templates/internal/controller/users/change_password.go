@@ -0,0 +1,45 @@
+package users
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/repository"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// ChangePasswordInput represents input for changing a user's password
+type ChangePasswordInput struct {
+	CurrentPassword string
+	NewPassword     string
+	// SessionID is the session making the change. It stays signed in while every other session is revoked.
+	SessionID string
+}
+
+// ChangePassword verifies the current password, sets the new one and revokes the user's other sessions
+func (i impl) ChangePassword(ctx context.Context, id int64, input ChangePasswordInput) error {
+	user, err := i.repo.User().GetByID(ctx, id)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if err := i.passwords.Verify(user.Password, input.CurrentPassword); err != nil {
+		return pkgerrors.WithStack(ErrInvalidCurrentPassword)
+	}
+
+	if err := i.policy.Check(input.NewPassword, user.Email, user.Name); err != nil {
+		return err
+	}
+
+	hashedPassword, err := i.passwords.Hash(input.NewPassword)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+	user.Password = hashedPassword
+
+	return i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		if err := txRepo.User().Update(ctx, user); err != nil {
+			return err
+		}
+		return txRepo.Session().RevokeByUserIDExcept(ctx, user.ID, input.SessionID)
+	}, nil)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/namf2001/go-backend-template/internal/model"
 	"github.com/namf2001/go-backend-template/internal/pkg/validator"
+	"github.com/namf2001/go-backend-template/internal/repository"
 	pkgerrors "github.com/pkg/errors"
 )
 
@@ -34,7 +35,16 @@ func (i impl) CreateUser(ctx context.Context, input CreateUserInput) (model.User
 		Name:  input.Name,
 	}
 
-	created, err := i.repo.User().Create(ctx, user)
+	var created model.User
+	err = i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		var txErr error
+		created, txErr = txRepo.User().Create(ctx, user)
+		if txErr != nil {
+			return txErr
+		}
+
+		return txRepo.Role().AssignToUser(ctx, created.ID, model.RoleUser)
+	}, nil)
 	if err != nil {
 		return UserOutput, pkgerrors.WithStack(err)
 	}
@@ -3,5 +3,7 @@ package users
 import "errors"
 
 var (
-	ErrUserExited = errors.New("user with this email already exists")
-)
\ No newline at end of file
+	ErrUserExited             = errors.New("user with this email already exists")
+	ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+	ErrLastSignInMethod       = errors.New("cannot unlink the last sign-in method")
+)
@@ -0,0 +1,31 @@
+package users
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// ListRoles lists the roles granted to a user
+func (i impl) ListRoles(ctx context.Context, id int64) ([]model.Role, error) {
+	if _, err := i.repo.User().GetByID(ctx, id); err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+
+	return i.repo.Role().ListByUserID(ctx, id)
+}
+
+// AssignRole grants a role to a user
+func (i impl) AssignRole(ctx context.Context, id int64, role string) error {
+	if _, err := i.repo.User().GetByID(ctx, id); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return i.repo.Role().AssignToUser(ctx, id, role)
+}
+
+// RemoveRole revokes a role from a user
+func (i impl) RemoveRole(ctx context.Context, id int64, role string) error {
+	return i.repo.Role().RemoveFromUser(ctx, id, role)
+}
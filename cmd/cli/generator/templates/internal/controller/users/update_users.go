@@ -26,9 +26,10 @@ func (i impl) UpdateUser(ctx context.Context, id int64, input UpdateUserInput) e
 		return pkgerrors.WithStack(err)
 	}
 
-	// Update fields
-	if input.Email != "" {
+	// Update fields, a new email has to be verified again
+	if input.Email != "" && input.Email != user.Email {
 		user.Email = input.Email
+		user.EmailVerified = nil
 	}
 	if input.Name != "" {
 		user.Name = input.Name
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	type args struct {
+		path      string
+		expRoute  string
+		expStatus string
+	}
+	tcs := map[string]args{
+		"success - route pattern": {
+			path:      "/metrics-test/users/1001",
+			expRoute:  "/metrics-test/users/{id}",
+			expStatus: "200",
+		},
+		"err - server error": {
+			path:      "/metrics-test/fail",
+			expRoute:  "/metrics-test/fail",
+			expStatus: "500",
+		},
+		"err - unmatched": {
+			path:      "/metrics-test/unknown/1001",
+			expRoute:  routeUnmatched,
+			expStatus: "404",
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			r := chi.NewRouter()
+			r.Use(Metrics)
+			r.Get("/metrics-test/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+			r.Get("/metrics-test/fail", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+			counter := metrics.HTTPRequests.WithLabelValues(tc.expRoute, http.MethodGet, tc.expStatus)
+			before := testutil.ToFloat64(counter)
+
+			// When
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			// Then
+			require.Equal(t, before+1, testutil.ToFloat64(counter))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/namf2001/go-backend-template/internal/repository/ratelimits"
+	pkgerrors "github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Headers of draft-ietf-httpapi-ratelimit-headers, sent on every rate limited response
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+var (
+	webErrRateLimited = &httpserv.Error{Status: http.StatusTooManyRequests, Code: "rate_limited", Desc: "Too many requests, retry later"}
+)
+
+// KeyFunc returns who a request is counted for
+type KeyFunc func(r *http.Request) string
+
+// RateLimitPolicy limits the requests of the routes it is used on. Routes sharing a policy share its buckets.
+type RateLimitPolicy struct {
+	Name  string // Prefixes the keys so every policy has its own buckets
+	Limit model.RateLimit
+	Key   KeyFunc
+}
+
+// KeyByIP counts requests per client IP. RemoteAddr is set from X-Forwarded-For by RealIP,
+// only for requests coming from a trusted proxy.
+func KeyByIP(r *http.Request) string {
+	return "ip:" + remoteHost(r)
+}
+
+// KeyByUser counts requests per authenticated user, per IP before authentication
+func KeyByUser(r *http.Request) string {
+	if userID, ok := UserIDFromContext(r.Context()); ok {
+		return "user:" + strconv.FormatInt(userID, 10)
+	}
+	return KeyByIP(r)
+}
+
+// KeyByAPIKey counts requests per API key, so each key of a user has its own limit.
+// Other requests are counted like KeyByUser.
+func KeyByAPIKey(r *http.Request) string {
+	if apiKeyID, ok := APIKeyIDFromContext(r.Context()); ok {
+		return "api_key:" + strconv.FormatInt(apiKeyID, 10)
+	}
+	return KeyByUser(r)
+}
+
+// ParseRateLimit parses a rate limit written <limit>/<period>, e.g. 10/1m or 1000/1h
+func ParseRateLimit(s string) (model.RateLimit, error) {
+	limit, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return model.RateLimit{}, pkgerrors.Errorf("rate limit %q is not <limit>/<period>", s)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(limit))
+	if err != nil || n <= 0 {
+		return model.RateLimit{}, pkgerrors.Errorf("rate limit %q needs a positive limit", s)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return model.RateLimit{}, pkgerrors.Errorf("rate limit %q needs a positive period", s)
+	}
+
+	return model.RateLimit{Limit: n, Period: d}, nil
+}
+
+// RateLimit refuses the requests of a key over the limit of the policy with 429 Too Many Requests.
+// Requests are let through when the store fails, the limiter must not take the API down with it.
+func RateLimit(store ratelimits.Repository, policy RateLimitPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := store.Take(r.Context(), policy.Name+":"+policy.Key(r), time.Now(), policy.Limit)
+			if err != nil {
+				logger.Error(r.Context(), "rate limit failed", zap.String("policy", policy.Name), zap.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+			h.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+			h.Set(HeaderRateLimitReset, seconds(result.ResetAfter))
+			h.Set(HeaderRateLimitPolicy, strconv.Itoa(policy.Limit.Limit)+";w="+seconds(policy.Limit.Period))
+
+			if !result.Allowed {
+				h.Set(HeaderRetryAfter, seconds(result.RetryAfter))
+				httpserv.RespondJSON(r.Context(), w, webErrRateLimited)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// seconds formats a duration as whole seconds, rounded up so clients never retry too early
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
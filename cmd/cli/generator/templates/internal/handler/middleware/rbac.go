@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+)
+
+var (
+	webErrForbidden = &httpserv.Error{Status: http.StatusForbidden, Code: "forbidden", Desc: "You do not have permission to perform this action"}
+)
+
+// HasRole reports whether the authenticated user has the role. It must be used behind RequireAuth.
+func HasRole(ctx context.Context, role string) bool {
+	roles, _ := ctx.Value(contextKeyRoles).([]string)
+	return slices.Contains(roles, role)
+}
+
+// HasPermission reports whether the authenticated user has the permission. It must be used behind RequireAuth.
+func HasPermission(ctx context.Context, permission string) bool {
+	permissions, _ := ctx.Value(contextKeyPermissions).([]string)
+	return slices.Contains(permissions, permission)
+}
+
+// RequireRole allows the request when the authenticated user has any of the roles. It must be used behind RequireAuth.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, role := range roles {
+				if HasRole(r.Context(), role) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			httpserv.RespondJSON(r.Context(), w, webErrForbidden)
+		})
+	}
+}
+
+// RequirePermission allows the request when the authenticated user has all the permissions.
+// It must be used behind RequireAuth.
+func RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, permission := range permissions {
+				if !HasPermission(r.Context(), permission) {
+					httpserv.RespondJSON(r.Context(), w, webErrForbidden)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireOwnerOrPermission allows the request when the user ID in the URL param is the authenticated user's own ID,
+// or when the authenticated user has the permission. Requests authenticated with an API key or an OAuth client token
+// always need the permission, so their scopes apply to the user's own record too. It must be used behind RequireAuth.
+func RequireOwnerOrPermission(urlParam string, permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if HasPermission(r.Context(), permission) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if isDelegated(r.Context()) {
+				httpserv.RespondJSON(r.Context(), w, webErrForbidden)
+				return
+			}
+
+			userID, ok := UserIDFromContext(r.Context())
+			ownerID, err := strconv.ParseInt(chi.URLParam(r, urlParam), 10, 64)
+			if !ok || err != nil || ownerID != userID {
+				httpserv.RespondJSON(r.Context(), w, webErrForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+const (
+	headerForwardedFor = "X-Forwarded-For"
+	headerRealIP       = "X-Real-IP"
+)
+
+// TrustedProxies are the networks of the reverse proxies allowed to tell the client IP in X-Forwarded-For
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses a comma separated list of CIDRs or IPs, e.g. 10.0.0.0/8,192.168.1.10
+func ParseTrustedProxies(s string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, pkgerrors.Errorf("trusted proxy %q is not an IP or a CIDR", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, pkgerrors.Errorf("trusted proxy %q is not an IP or a CIDR", entry)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+// contains reports whether the IP is one of a trusted proxy
+func (p TrustedProxies) contains(ip net.IP) bool {
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// RealIP sets RemoteAddr to the IP of the client. X-Forwarded-For and X-Real-IP are only read when the request
+// comes from a trusted proxy, anyone else could send them to pass for another IP and dodge the per-IP rate limits
+// and lockouts. The client is the last address of X-Forwarded-For that is not a trusted proxy, as the ones before it
+// were added by the client itself.
+func RealIP(proxies TrustedProxies) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := proxies.clientIP(r); ip != "" {
+				r.RemoteAddr = ip
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the client IP told by trusted proxies, empty when RemoteAddr is the client
+func (p TrustedProxies) clientIP(r *http.Request) string {
+	peer := net.ParseIP(remoteHost(r))
+	if peer == nil || !p.contains(peer) {
+		return ""
+	}
+
+	forwarded := r.Header.Values(headerForwardedFor)
+	if len(forwarded) == 0 {
+		if ip := net.ParseIP(strings.TrimSpace(r.Header.Get(headerRealIP))); ip != nil {
+			return ip.String()
+		}
+		return ""
+	}
+
+	hops := strings.Split(strings.Join(forwarded, ","), ",")
+	var client net.IP
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			// Proxies do not write garbage, the client did. Nothing before it can be trusted.
+			break
+		}
+		client = ip
+		if !p.contains(ip) {
+			break
+		}
+	}
+	if client == nil {
+		return ""
+	}
+	return client.String()
+}
+
+// remoteHost returns the host of RemoteAddr, which has no port when set by RealIP
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+)
+
+// RequestEmailVerification sends a new email verification link to the current user
+// @Summary      Request email verification
+// @Description  Send a new verification link to the current user's email
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object} httpserv.Success
+// @Failure      401  {object} httpserv.Error
+// @Failure      409  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/verify-email/request [post]
+func (h *Handler) RequestEmailVerification() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		if err := h.ctrl.RequestEmailVerification(r.Context(), userID); err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, httpserv.Success{Message: "Verification email sent"})
+		return nil
+	})
+}
+
+// ConfirmEmail consumes an email verification link
+// @Summary      Confirm email
+// @Description  Mark the user's email as verified using the token from the verification link
+// @Tags         auth
+// @Produce      json
+// @Param        email query string true "Email"
+// @Param        token query string true "Verification token"
+// @Success      200  {object} httpserv.Success
+// @Failure      400  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Router       /auth/verify-email/confirm [get]
+func (h *Handler) ConfirmEmail() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		email := r.URL.Query().Get("email")
+		token := r.URL.Query().Get("token")
+		if email == "" || token == "" {
+			return webErrValidationFailed
+		}
+
+		if err := h.ctrl.ConfirmEmail(r.Context(), email, token); err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, httpserv.Success{Message: "Email verified"})
+		return nil
+	})
+}
@@ -2,14 +2,19 @@ package auth
 
 import (
 	"github.com/namf2001/go-backend-template/internal/controller/auth"
+	"github.com/namf2001/go-backend-template/internal/pkg/oauth"
 )
 
 type Handler struct {
-	ctrl auth.Controller
+	ctrl      auth.Controller
+	providers *oauth.Registry
+	states    *oauth.StateCookie
 }
 
-func New(ctrl auth.Controller) *Handler {
+func New(ctrl auth.Controller, providers *oauth.Registry, states *oauth.StateCookie) *Handler {
 	return &Handler{
-		ctrl: ctrl,
+		ctrl:      ctrl,
+		providers: providers,
+		states:    states,
 	}
 }
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/validator"
+)
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// RequestMagicLink sends a one-time login link
+// @Summary      Request magic link
+// @Description  Email a one-time login link. The response is the same whether or not the email is registered.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input body auth.MagicLinkRequest true "Email"
+// @Success      200  {object} httpserv.Success
+// @Failure      400  {object} httpserv.Error
+// @Failure      429  {object} httpserv.Error "account_locked, see the Retry-After header"
+// @Router       /auth/magic-link [post]
+func (h *Handler) RequestMagicLink() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var req MagicLinkRequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		if err := h.ctrl.RequestMagicLink(r.Context(), req.Email); err != nil {
+			if lockedErr := lockedError(w, err); lockedErr != nil {
+				return lockedErr
+			}
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, httpserv.Success{Message: "If the email is registered, a login link has been sent"})
+		return nil
+	})
+}
+
+// MagicLinkCallback logs in with a magic link
+// @Summary      Magic link callback
+// @Description  Consume the link sent by /auth/magic-link and return tokens, or an auth.MFARequiredResponse
+// @Description  when the user enabled two-factor authentication
+// @Tags         auth
+// @Produce      json
+// @Param        email  query     string  true  "Email"
+// @Param        token  query     string  true  "Login token"
+// @Success      200  {object} auth.LoginResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Router       /auth/magic-link/callback [get]
+func (h *Handler) MagicLinkCallback() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		email := r.URL.Query().Get("email")
+		token := r.URL.Query().Get("token")
+		if email == "" || token == "" {
+			return webErrValidationFailed
+		}
+
+		tokens, err := h.ctrl.MagicLinkLogin(r.Context(), email, token)
+		if err != nil {
+			return convertError(err)
+		}
+
+		respondLogin(w, r, tokens)
+		return nil
+	})
+}
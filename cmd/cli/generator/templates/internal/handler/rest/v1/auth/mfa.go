@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"net/http"
+
+	ctrlAuth "github.com/namf2001/go-backend-template/internal/controller/auth"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/validator"
+)
+
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"` // TOTP code or recovery code
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// EnrollMFAResponse holds the secret to add to an authenticator app
+type EnrollMFAResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// ConfirmMFAResponse holds the recovery codes, shown only once
+type ConfirmMFAResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// VerifyMFA completes a login with two-factor authentication
+// @Summary      Verify MFA code
+// @Description  Exchange the mfa_token returned by login and a TOTP or recovery code for tokens
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input body auth.VerifyMFARequest true "MFA token and code"
+// @Success      200  {object} auth.LoginResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      429  {object} httpserv.Error "account_locked, see the Retry-After header"
+// @Failure      500  {object} httpserv.Error
+// @Router       /auth/mfa/verify [post]
+func (h *Handler) VerifyMFA() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var req VerifyMFARequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		tokens, err := h.ctrl.VerifyMFA(r.Context(), ctrlAuth.VerifyMFAInput{
+			MFAToken: req.MFAToken,
+			Code:     req.Code,
+			IP:       clientIP(r),
+		})
+		if err != nil {
+			if lockedErr := lockedError(w, err); lockedErr != nil {
+				return lockedErr
+			}
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, LoginResponse{TokenResponse: newTokenResponse(tokens)})
+		return nil
+	})
+}
+
+// EnrollMFA starts two-factor authentication enrollment for the current user
+// @Summary      Enroll MFA
+// @Description  Generate a TOTP secret. It is enabled once a code is sent to /auth/mfa/enroll/confirm
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object} auth.EnrollMFAResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      409  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/mfa/enroll [post]
+func (h *Handler) EnrollMFA() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		enrollment, err := h.ctrl.EnrollMFA(r.Context(), userID)
+		if err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, EnrollMFAResponse{
+			Secret: enrollment.Secret,
+			URI:    enrollment.URI,
+		})
+		return nil
+	})
+}
+
+// ConfirmMFA enables two-factor authentication for the current user
+// @Summary      Confirm MFA enrollment
+// @Description  Enable two-factor authentication with a first TOTP code and return the recovery codes
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input body auth.MFACodeRequest true "TOTP code"
+// @Success      200  {object} auth.ConfirmMFAResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Failure      409  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/mfa/enroll/confirm [post]
+func (h *Handler) ConfirmMFA() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		var req MFACodeRequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		codes, err := h.ctrl.ConfirmMFA(r.Context(), userID, req.Code)
+		if err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, ConfirmMFAResponse{RecoveryCodes: codes})
+		return nil
+	})
+}
+
+// DisableMFA turns off two-factor authentication for the current user
+// @Summary      Disable MFA
+// @Description  Disable two-factor authentication, a current TOTP or recovery code is required
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input body auth.MFACodeRequest true "TOTP or recovery code"
+// @Success      204  {object} nil
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Failure      429  {object} httpserv.Error "account_locked, see the Retry-After header"
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/mfa/disable [post]
+func (h *Handler) DisableMFA() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		var req MFACodeRequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		if err := h.ctrl.DisableMFA(r.Context(), userID, req.Code); err != nil {
+			if lockedErr := lockedError(w, err); lockedErr != nil {
+				return lockedErr
+			}
+			return convertError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	ctrlAuth "github.com/namf2001/go-backend-template/internal/controller/auth"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	"github.com/namf2001/go-backend-template/internal/pkg/oauth"
+	"golang.org/x/oauth2"
+)
+
+// OAuthLoginResponse represents the response for OAuth login
+type OAuthLoginResponse struct {
+	URL string `json:"url"`
+}
+
+// LinkAccountResponse represents the provider account linked by the OAuth callback
+type LinkAccountResponse struct {
+	Provider          model.Provider `json:"provider"`
+	ProviderAccountID string         `json:"provider_account_id"`
+	Type              string         `json:"type"`
+}
+
+// OAuthLogin handles oauth login
+// @Summary      OAuth login
+// @Description  Get the consent page URL of an OAuth provider (google, github, microsoft, oidc).
+// @Description  The state and PKCE verifier are bound to the browser with a signed cookie.
+// @Tags         auth
+// @Produce      json
+// @Param        provider path string true "Provider name"
+// @Success      200  {object} auth.OAuthLoginResponse
+// @Failure      404  {object} httpserv.Error
+// @Router       /auth/{provider}/login [get]
+func (h *Handler) OAuthLogin() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		provider, err := h.providers.Get(model.Provider(chi.URLParam(r, "provider")))
+		if err != nil {
+			return webErrProviderNotFound
+		}
+
+		url, err := h.authCodeURL(w, provider, oauth.AuthRequest{Provider: provider.Name()})
+		if err != nil {
+			return err
+		}
+
+		httpserv.RespondJSON(r.Context(), w, OAuthLoginResponse{URL: url})
+		return nil
+	})
+}
+
+// LinkAccount handles linking a provider to the current user
+// @Summary      Link account
+// @Description  Get the consent page URL of an OAuth provider to link it to the authenticated user.
+// @Description  The provider redirects to /auth/{provider}/callback, which links the account instead of signing in.
+// @Tags         me
+// @Produce      json
+// @Param        provider path string true "Provider name"
+// @Success      200  {object} auth.OAuthLoginResponse
+// @Failure      401  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /me/accounts/{provider}/link [post]
+func (h *Handler) LinkAccount() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+		sessionID, _ := middleware.SessionIDFromContext(r.Context())
+
+		provider, err := h.providers.Get(model.Provider(chi.URLParam(r, "provider")))
+		if err != nil {
+			return webErrProviderNotFound
+		}
+
+		url, err := h.authCodeURL(w, provider, oauth.AuthRequest{
+			Provider:      provider.Name(),
+			LinkUserID:    userID,
+			LinkSessionID: sessionID,
+		})
+		if err != nil {
+			return err
+		}
+
+		httpserv.RespondJSON(r.Context(), w, OAuthLoginResponse{URL: url})
+		return nil
+	})
+}
+
+// authCodeURL binds a new authorization request to the browser and returns the provider's consent page URL
+func (h *Handler) authCodeURL(w http.ResponseWriter, provider oauth.Provider, req oauth.AuthRequest) (string, error) {
+	authReq, err := h.states.Issue(w, req)
+	if err != nil {
+		return "", err
+	}
+
+	return provider.AuthCodeURL(authReq.State,
+		oauth2.S256ChallengeOption(authReq.CodeVerifier),
+		oauth2.SetAuthURLParam("nonce", authReq.Nonce),
+	), nil
+}
+
+// OAuthCallback handles oauth callback
+// @Summary      OAuth callback
+// @Description  Handle the OAuth provider callback and return tokens, or an auth.MFARequiredResponse when the
+// @Description  user enabled two-factor authentication. When the flow was started from
+// @Description  /me/accounts/{provider}/link, the provider account is linked to that user instead.
+// @Tags         auth
+// @Produce      json
+// @Param        provider path string true "Provider name"
+// @Param        state query string true "OAuth state"
+// @Param        code  query string true "OAuth code"
+// @Success      200  {object} auth.LoginResponse
+// @Success      200  {object} auth.LinkAccountResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Failure      409  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Router       /auth/{provider}/callback [get]
+func (h *Handler) OAuthCallback() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) (err error) {
+		providerName := "unknown" // Not the URL param, so unknown providers do not grow the number of series
+		defer func() { metrics.AuthOAuthCallbacks.WithLabelValues(providerName, callbackResult(err)).Inc() }()
+
+		provider, err := h.providers.Get(model.Provider(chi.URLParam(r, "provider")))
+		if err != nil {
+			return webErrProviderNotFound
+		}
+		providerName = string(provider.Name())
+
+		authReq, err := h.states.Verify(w, r, provider.Name(), r.FormValue("state"))
+		if err != nil {
+			return webErrInvalidOAuthState
+		}
+
+		if r.FormValue("error") != "" {
+			return webErrOAuthDenied
+		}
+
+		code := r.FormValue("code")
+		token, err := provider.Exchange(r.Context(), code, oauth2.VerifierOption(authReq.CodeVerifier))
+		if err != nil {
+			return webErrCodeExchangeFailed
+		}
+
+		userInfo, err := provider.UserInfo(r.Context(), token, authReq.Nonce)
+		if err != nil {
+			if errors.Is(err, oauth.ErrInvalidIDToken) {
+				return webErrInvalidIDToken
+			}
+			return webErrGetUserInfoFailed
+		}
+
+		input := ctrlAuth.OAuthInput{
+			Provider:          provider.Name(),
+			ProviderAccountID: userInfo.ID,
+			Type:              "oauth",
+			AccessToken:       token.AccessToken,
+			RefreshToken:      token.RefreshToken,
+			ExpiresAt:         token.Expiry.Unix(),
+			TokenType:         token.TokenType,
+
+			Name:          userInfo.Name,
+			Email:         userInfo.Email,
+			Image:         userInfo.Image,
+			EmailVerified: userInfo.EmailVerified,
+		}
+		if idToken, ok := token.Extra("id_token").(string); ok {
+			input.IDToken = idToken
+		}
+		if scope, ok := token.Extra("scope").(string); ok {
+			input.Scope = scope
+		}
+
+		if authReq.LinkUserID != 0 {
+			return h.linkAccount(w, r, authReq, input)
+		}
+
+		if userInfo.Email == "" {
+			return webErrOAuthEmailMissing
+		}
+
+		tokens, err := h.ctrl.OAuthLogin(r.Context(), input)
+		if err != nil {
+			return convertError(err)
+		}
+
+		respondLogin(w, r, tokens)
+		return nil
+	})
+}
+
+// callbackResult returns the result of an OAuth callback for metrics, the error code when it failed
+func callbackResult(err error) string {
+	if err == nil {
+		return metrics.ResultSuccess
+	}
+	var webErr *httpserv.Error
+	if errors.As(err, &webErr) && webErr.Code != "" {
+		return webErr.Code
+	}
+	return metrics.ResultFailure
+}
+
+// linkAccount links the provider account to the user who started the flow, as long as their session is still active
+func (h *Handler) linkAccount(w http.ResponseWriter, r *http.Request, authReq oauth.AuthRequest, input ctrlAuth.OAuthInput) error {
+	if err := h.ctrl.ValidateSession(r.Context(), authReq.LinkSessionID); err != nil {
+		if errors.Is(err, ctrlAuth.ErrSessionRevoked) {
+			return webErrUnauthenticated
+		}
+		return err
+	}
+
+	account, err := h.ctrl.LinkAccount(r.Context(), authReq.LinkUserID, input)
+	if err != nil {
+		return convertError(err)
+	}
+
+	httpserv.RespondJSON(r.Context(), w, LinkAccountResponse{
+		Provider:          account.Provider,
+		ProviderAccountID: account.ProviderAccountID,
+		Type:              account.Type,
+	})
+	return nil
+}
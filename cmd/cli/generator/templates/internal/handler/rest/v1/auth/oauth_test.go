@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/namf2001/go-backend-template/config"
+	ctrlAuth "github.com/namf2001/go-backend-template/internal/controller/auth"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/model"
+	jwtpkg "github.com/namf2001/go-backend-template/internal/pkg/jwt"
+	"github.com/namf2001/go-backend-template/internal/pkg/oauth"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthServer is a minimal OpenID provider that enforces PKCE on the token endpoint
+// and issues ID tokens carrying the nonce of the authorization request
+type fakeAuthServer struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	s := &fakeAuthServer{key: key}
+	mux := http.NewServeMux()
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/authorize",
+			"token_endpoint":         s.URL + "/token",
+			"userinfo_endpoint":      s.URL + "/userinfo",
+			"jwks_uri":               s.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "the-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != s.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+
+		idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            s.URL,
+			"aud":            "client",
+			"sub":            "abc",
+			"nonce":          s.nonce,
+			"email":          "test1@example.com",
+			"email_verified": true,
+			"name":           "Test User",
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		})
+		idToken.Header["kid"] = "key-1"
+		raw, err := idToken.SignedString(s.key)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     raw,
+		})
+	})
+
+	return s
+}
+
+type fakeOAuthController struct {
+	ctrlAuth.Controller
+	input        ctrlAuth.OAuthInput
+	linkedUserID int64
+	revoked      bool
+}
+
+func (c *fakeOAuthController) OAuthLogin(ctx context.Context, input ctrlAuth.OAuthInput) (ctrlAuth.Tokens, error) {
+	c.input = input
+	return ctrlAuth.Tokens{AccessToken: "jwt", RefreshToken: "refresh"}, nil
+}
+
+func (c *fakeOAuthController) LinkAccount(ctx context.Context, userID int64, input ctrlAuth.OAuthInput) (model.Account, error) {
+	c.input = input
+	c.linkedUserID = userID
+	return model.Account{UserID: userID, Provider: input.Provider, ProviderAccountID: input.ProviderAccountID, Type: input.Type}, nil
+}
+
+func (c *fakeOAuthController) ValidateSession(ctx context.Context, sessionID string) error {
+	if c.revoked {
+		return ctrlAuth.ErrSessionRevoked
+	}
+	return nil
+}
+
+func TestHandler_OAuthCallback(t *testing.T) {
+	type args struct {
+		state     func(state string) string
+		cookie    bool
+		code      string
+		nonce     string // Overrides the nonce put in the ID token
+		expStatus int
+		expCode   string
+	}
+	tcs := map[string]args{
+		"success": {
+			state:     func(state string) string { return state },
+			cookie:    true,
+			code:      "the-code",
+			expStatus: http.StatusOK,
+		},
+		"state_mismatch": {
+			state:     func(state string) string { return "random-string" },
+			cookie:    true,
+			code:      "the-code",
+			expStatus: http.StatusBadRequest,
+			expCode:   "invalid_oauth_state",
+		},
+		"missing_cookie": {
+			state:     func(state string) string { return state },
+			code:      "the-code",
+			expStatus: http.StatusBadRequest,
+			expCode:   "invalid_oauth_state",
+		},
+		"nonce_mismatch": {
+			state:     func(state string) string { return state },
+			cookie:    true,
+			code:      "the-code",
+			nonce:     "other-nonce",
+			expStatus: http.StatusBadRequest,
+			expCode:   "invalid_id_token",
+		},
+		"code_rejected": {
+			state:     func(state string) string { return state },
+			cookie:    true,
+			code:      "other-code",
+			expStatus: http.StatusBadRequest,
+			expCode:   "code_exchange_failed",
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			srv := newFakeAuthServer(t)
+			provider, err := oauth.NewOIDCProvider(context.Background(), srv.URL, oauth.Config{ClientID: "client", RedirectURL: "http://localhost/callback"})
+			require.NoError(t, err)
+
+			ctrl := &fakeOAuthController{}
+			h := New(ctrl, oauth.NewRegistry(provider), oauth.NewStateCookie([]byte("secret"), false))
+			r := chi.NewRouter()
+			r.Get("/api/v1/auth/{provider}/login", h.OAuthLogin())
+			r.Get("/api/v1/auth/{provider}/callback", h.OAuthCallback())
+
+			// Login
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/login", nil))
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var loginRs OAuthLoginResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&loginRs))
+			authURL, err := url.Parse(loginRs.URL)
+			require.NoError(t, err)
+			require.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+			srv.challenge = authURL.Query().Get("code_challenge")
+			srv.nonce = authURL.Query().Get("nonce")
+			require.NotEmpty(t, srv.nonce)
+			if tc.nonce != "" {
+				srv.nonce = tc.nonce
+			}
+			cookies := w.Result().Cookies()
+			require.Len(t, cookies, 1)
+
+			// Callback
+			q := url.Values{"state": {tc.state(authURL.Query().Get("state"))}, "code": {tc.code}}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?"+q.Encode(), nil)
+			if tc.cookie {
+				req.AddCookie(cookies[0])
+			}
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.expStatus, w.Code)
+			if tc.expCode != "" {
+				require.Contains(t, w.Body.String(), tc.expCode)
+				return
+			}
+			require.Equal(t, model.ProviderOIDC, ctrl.input.Provider)
+			require.Equal(t, "abc", ctrl.input.ProviderAccountID)
+			require.Equal(t, "test1@example.com", ctrl.input.Email)
+			require.Contains(t, w.Body.String(), `"token":"jwt"`)
+		})
+	}
+}
+
+func TestHandler_OAuthLogin_ProviderNotFound(t *testing.T) {
+	h := New(&fakeOAuthController{}, oauth.NewRegistry(), oauth.NewStateCookie([]byte("secret"), false))
+	r := chi.NewRouter()
+	r.Get("/api/v1/auth/{provider}/login", h.OAuthLogin())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/unknown/login", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandler_LinkAccount(t *testing.T) {
+	config.Init("test")
+	config.GetConfig().Set("JWT_SECRET", "test-secret")
+	config.GetConfig().Set("APP_BASE_URL", "http://localhost:8080")
+
+	accessToken, err := jwtpkg.GenerateToken(jwtpkg.Subject{UserID: 1001, Email: "test1@example.com", SessionID: "active-session"})
+	require.NoError(t, err)
+
+	type args struct {
+		revokedBeforeCallback bool
+		expStatus             int
+		expBody               string
+	}
+	tcs := map[string]args{
+		"success": {
+			expStatus: http.StatusOK,
+			expBody:   `"provider_account_id":"abc"`,
+		},
+		"err - session revoked before callback": {
+			revokedBeforeCallback: true,
+			expStatus:             http.StatusUnauthorized,
+			expBody:               "unauthenticated",
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			srv := newFakeAuthServer(t)
+			provider, err := oauth.NewOIDCProvider(context.Background(), srv.URL, oauth.Config{ClientID: "client", RedirectURL: "http://localhost/callback"})
+			require.NoError(t, err)
+
+			ctrl := &fakeOAuthController{}
+			h := New(ctrl, oauth.NewRegistry(provider), oauth.NewStateCookie([]byte("secret"), false))
+			r := chi.NewRouter()
+			r.With(middleware.RequireAuth(ctrl)).Post("/api/v1/me/accounts/{provider}/link", h.LinkAccount())
+			r.Get("/api/v1/auth/{provider}/callback", h.OAuthCallback())
+
+			// Start linking as the signed in user
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/me/accounts/oidc/link", nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var loginRs OAuthLoginResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&loginRs))
+			authURL, err := url.Parse(loginRs.URL)
+			require.NoError(t, err)
+			srv.challenge = authURL.Query().Get("code_challenge")
+			srv.nonce = authURL.Query().Get("nonce")
+
+			// Callback, without the access token since it is a browser redirect
+			ctrl.revoked = tc.revokedBeforeCallback
+			q := url.Values{"state": {authURL.Query().Get("state")}, "code": {"the-code"}}
+			req = httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?"+q.Encode(), nil)
+			req.AddCookie(w.Result().Cookies()[0])
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, tc.expStatus, w.Code)
+			require.Contains(t, w.Body.String(), tc.expBody)
+			if tc.expStatus == http.StatusOK {
+				require.Equal(t, int64(1001), ctrl.linkedUserID)
+				require.Equal(t, model.ProviderOIDC, ctrl.input.Provider)
+			}
+		})
+	}
+}
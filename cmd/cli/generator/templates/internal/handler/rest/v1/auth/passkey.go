@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+)
+
+// PasskeyResponse represents a passkey of the current user
+type PasskeyResponse struct {
+	ID             int64      `json:"id"`
+	Name           string     `json:"name"`
+	BackupEligible bool       `json:"backup_eligible"` // Synced passkey, e.g. stored in a password manager
+	CreatedAt      time.Time  `json:"created_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+}
+
+// ListPasskeysResponse represents the response for listing passkeys
+type ListPasskeysResponse struct {
+	Passkeys []PasskeyResponse `json:"passkeys"`
+}
+
+func newPasskeyResponse(c model.WebAuthnCredential) PasskeyResponse {
+	return PasskeyResponse{
+		ID:             c.ID,
+		Name:           c.Name,
+		BackupEligible: c.BackupEligible,
+		CreatedAt:      c.CreatedAt,
+		LastUsedAt:     c.LastUsedAt,
+	}
+}
+
+// BeginPasskeyRegistration starts adding a passkey to the current user
+// @Summary      Begin passkey registration
+// @Description  Return the options to pass to navigator.credentials.create()
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object} protocol.CredentialCreation
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/webauthn/register/begin [post]
+func (h *Handler) BeginPasskeyRegistration() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		creation, err := h.ctrl.BeginPasskeyRegistration(r.Context(), userID)
+		if err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, creation)
+		return nil
+	})
+}
+
+// FinishPasskeyRegistration verifies and stores the passkey created by the authenticator
+// @Summary      Finish passkey registration
+// @Description  Verify the PublicKeyCredential returned by navigator.credentials.create() and store the passkey
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        name  query     string  false  "Name of the passkey, e.g. the device it is on"
+// @Success      200  {object} auth.PasskeyResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      409  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/webauthn/register/finish [post]
+func (h *Handler) FinishPasskeyRegistration() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		response, err := protocol.ParseCredentialCreationResponseBody(r.Body)
+		if err != nil {
+			return webErrInvalidPasskey
+		}
+
+		created, err := h.ctrl.FinishPasskeyRegistration(r.Context(), userID, r.URL.Query().Get("name"), response)
+		if err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, newPasskeyResponse(created))
+		return nil
+	})
+}
+
+// BeginPasskeyLogin starts a passkey sign in
+// @Summary      Begin passkey login
+// @Description  Return the options to pass to navigator.credentials.get(), any passkey of this site is accepted
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object} protocol.CredentialAssertion
+// @Failure      500  {object} httpserv.Error
+// @Router       /auth/webauthn/login/begin [post]
+func (h *Handler) BeginPasskeyLogin() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		assertion, err := h.ctrl.BeginPasskeyLogin(r.Context())
+		if err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, assertion)
+		return nil
+	})
+}
+
+// FinishPasskeyLogin signs in with a passkey
+// @Summary      Finish passkey login
+// @Description  Verify the PublicKeyCredential returned by navigator.credentials.get() and return tokens
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Success      200  {object} auth.LoginResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Router       /auth/webauthn/login/finish [post]
+func (h *Handler) FinishPasskeyLogin() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		response, err := protocol.ParseCredentialRequestResponseBody(r.Body)
+		if err != nil {
+			return webErrInvalidPasskey
+		}
+
+		tokens, err := h.ctrl.FinishPasskeyLogin(r.Context(), response)
+		if err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, LoginResponse{TokenResponse: newTokenResponse(tokens)})
+		return nil
+	})
+}
+
+// ListPasskeys lists the passkeys of the current user
+// @Summary      List passkeys
+// @Description  List the passkeys of the current user
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object} auth.ListPasskeysResponse
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/webauthn/credentials [get]
+func (h *Handler) ListPasskeys() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		credentials, err := h.ctrl.ListPasskeys(r.Context(), userID)
+		if err != nil {
+			return convertError(err)
+		}
+
+		resp := ListPasskeysResponse{Passkeys: make([]PasskeyResponse, 0, len(credentials))}
+		for _, c := range credentials {
+			resp.Passkeys = append(resp.Passkeys, newPasskeyResponse(c))
+		}
+
+		httpserv.RespondJSON(r.Context(), w, resp)
+		return nil
+	})
+}
+
+// DeletePasskey deletes a passkey of the current user
+// @Summary      Delete passkey
+// @Description  Delete a passkey of the current user, unless it is their last way to sign in
+// @Tags         auth
+// @Produce      json
+// @Param        id   path      int  true  "Passkey ID"
+// @Success      204  {object} nil
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Failure      409  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/webauthn/credentials/{id} [delete]
+func (h *Handler) DeletePasskey() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			return webErrPasskeyNotFound
+		}
+
+		if err := h.ctrl.DeletePasskey(r.Context(), userID, id); err != nil {
+			return convertError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
@@ -11,16 +11,16 @@ import (
 type RegisterRequest struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Password string `json:"password" validate:"required"` // Checked against the password policy
 }
 
 type RegisterResponse struct {
-	Token string `json:"token"`
+	TokenResponse
 }
 
 // Register handles manual registration
 // @Summary      Register user
-// @Description  Register a new user account
+// @Description  Register a new user account. A password breaking the password policy is refused with every broken rule in fields.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -46,12 +46,12 @@ func (h *Handler) Register() http.HandlerFunc {
 			Password: req.Password,
 		}
 
-		token, err := h.ctrl.Register(r.Context(), input)
+		tokens, err := h.ctrl.Register(r.Context(), input)
 		if err != nil {
-			return err
+			return convertError(err)
 		}
 
-		httpserv.RespondJSON(r.Context(), w, RegisterResponse{Token: token})
+		httpserv.RespondJSON(r.Context(), w, RegisterResponse{TokenResponse: newTokenResponse(tokens)})
 		return nil
 	})
 }
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+)
+
+// SessionResponse represents an active session of the current user
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Current    bool      `json:"current"`
+	ClientID   string    `json:"client_id,omitempty"` // OAuth client the session was granted to
+	Scopes     []string  `json:"scopes,omitempty"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ListSessionsResponse represents the response for listing sessions
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+// ListSessions lists the active sessions of the current user
+// @Summary      List sessions
+// @Description  List the active sessions of the current user
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object} auth.ListSessionsResponse
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/sessions [get]
+func (h *Handler) ListSessions() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+		currentSessionID, _ := middleware.SessionIDFromContext(r.Context())
+
+		sessions, err := h.ctrl.ListSessions(r.Context(), userID)
+		if err != nil {
+			return convertError(err)
+		}
+
+		resp := ListSessionsResponse{Sessions: make([]SessionResponse, 0, len(sessions))}
+		for _, s := range sessions {
+			resp.Sessions = append(resp.Sessions, SessionResponse{
+				ID:         s.FamilyID,
+				Current:    s.FamilyID == currentSessionID,
+				ClientID:   s.ClientID,
+				Scopes:     s.Scopes,
+				LastUsedAt: s.CreatedAt,
+				ExpiresAt:  s.Expires,
+			})
+		}
+
+		httpserv.RespondJSON(r.Context(), w, resp)
+		return nil
+	})
+}
+
+// RevokeSession revokes one of the current user's sessions
+// @Summary      Revoke session
+// @Description  Revoke one of the current user's sessions
+// @Tags         auth
+// @Produce      json
+// @Param        id   path      string  true  "Session ID"
+// @Success      204  {object} nil
+// @Failure      401  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/sessions/{id} [delete]
+func (h *Handler) RevokeSession() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		if err := h.ctrl.RevokeSession(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+			return convertError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
@@ -0,0 +1,636 @@
+package authserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-chi/chi/v5"
+	"github.com/namf2001/go-backend-template/config"
+	ctrlAuth "github.com/namf2001/go-backend-template/internal/controller/auth"
+	ctrlAuthServer "github.com/namf2001/go-backend-template/internal/controller/authserver"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/model"
+	jwtpkg "github.com/namf2001/go-backend-template/internal/pkg/jwt"
+	"github.com/namf2001/go-backend-template/internal/repository"
+	"github.com/namf2001/go-backend-template/internal/repository/oauthclients"
+	"github.com/namf2001/go-backend-template/internal/repository/oauthcodes"
+	"github.com/namf2001/go-backend-template/internal/repository/roles"
+	"github.com/namf2001/go-backend-template/internal/repository/sessions"
+	"github.com/namf2001/go-backend-template/internal/repository/users"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry keeps the tables the authorization server uses in memory
+type fakeRegistry struct {
+	repository.Registry
+	clients  fakeClients
+	codes    fakeCodes
+	sessions *fakeSessions
+	users    fakeUsers
+	roles    fakeRoles
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{
+		clients:  fakeClients{clients: map[string]*model.OAuthClient{}},
+		codes:    fakeCodes{codes: map[string]model.OAuthAuthorizationCode{}},
+		sessions: &fakeSessions{},
+		users:    fakeUsers{users: map[int64]model.User{1001: {ID: 1001, Email: "test1@example.com"}}},
+		roles: fakeRoles{permissions: map[int64][]string{
+			1001: {model.PermissionUsersRead, model.PermissionUsersUpdate, model.PermissionOAuthClientsManage},
+		}},
+	}
+}
+
+func (f *fakeRegistry) OAuthClient() oauthclients.Repository { return f.clients }
+func (f *fakeRegistry) OAuthCode() oauthcodes.Repository     { return f.codes }
+func (f *fakeRegistry) Session() sessions.Repository         { return f.sessions }
+func (f *fakeRegistry) User() users.Repository               { return f.users }
+func (f *fakeRegistry) Role() roles.Repository               { return f.roles }
+
+func (f *fakeRegistry) DoInTx(ctx context.Context, txFunc func(ctx context.Context, txRepo repository.Registry) error, _ backoff.BackOff) error {
+	return txFunc(ctx, f)
+}
+
+type fakeClients struct {
+	oauthclients.Repository
+	clients map[string]*model.OAuthClient
+}
+
+func (f fakeClients) Create(_ context.Context, client model.OAuthClient) (model.OAuthClient, error) {
+	client.ID = int64(len(f.clients) + 1)
+	client.CreatedAt = time.Now()
+	f.clients[client.ClientID] = &client
+	return client, nil
+}
+
+func (f fakeClients) GetByClientID(_ context.Context, clientID string) (model.OAuthClient, error) {
+	client, ok := f.clients[clientID]
+	if !ok {
+		return model.OAuthClient{}, oauthclients.ErrNotFound
+	}
+	return *client, nil
+}
+
+type fakeCodes struct {
+	oauthcodes.Repository
+	codes map[string]model.OAuthAuthorizationCode
+}
+
+func (f fakeCodes) Create(_ context.Context, code model.OAuthAuthorizationCode) error {
+	f.codes[code.CodeHash] = code
+	return nil
+}
+
+func (f fakeCodes) Redeem(_ context.Context, codeHash, familyID string) (model.OAuthAuthorizationCode, error) {
+	code, ok := f.codes[codeHash]
+	if !ok {
+		return model.OAuthAuthorizationCode{}, oauthcodes.ErrNotFound
+	}
+	redeemed := code
+	if code.UsedAt == nil {
+		now := time.Now()
+		code.UsedAt, code.FamilyID = &now, familyID
+		f.codes[codeHash] = code
+	}
+	return redeemed, nil
+}
+
+type fakeSessions struct {
+	sessions.Repository
+	sessions []*model.Session
+}
+
+func (f *fakeSessions) Create(_ context.Context, session model.Session) (model.Session, error) {
+	session.ID = int64(len(f.sessions) + 1)
+	session.CreatedAt = time.Now()
+	f.sessions = append(f.sessions, &session)
+	return session, nil
+}
+
+func (f *fakeSessions) GetByToken(_ context.Context, token string) (model.Session, error) {
+	for _, s := range f.sessions {
+		if s.SessionToken == token {
+			return *s, nil
+		}
+	}
+	return model.Session{}, sessions.ErrNotFound
+}
+
+func (f *fakeSessions) Revoke(_ context.Context, token string) error {
+	for _, s := range f.sessions {
+		if s.SessionToken == token && s.RevokedAt == nil {
+			now := time.Now()
+			s.RevokedAt = &now
+			return nil
+		}
+	}
+	return sessions.ErrNotFound
+}
+
+func (f *fakeSessions) RevokeFamily(_ context.Context, familyID string) error {
+	for _, s := range f.sessions {
+		if s.FamilyID == familyID && s.RevokedAt == nil {
+			now := time.Now()
+			s.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (f *fakeSessions) IsFamilyActive(_ context.Context, familyID string) (bool, error) {
+	for _, s := range f.sessions {
+		if s.FamilyID == familyID && s.IsActive(time.Now()) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type fakeUsers struct {
+	users.Repository
+	users map[int64]model.User
+}
+
+func (f fakeUsers) GetByID(_ context.Context, id int64) (model.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return model.User{}, users.ErrNotFound
+	}
+	return user, nil
+}
+
+type fakeRoles struct {
+	roles.Repository
+	permissions map[int64][]string
+}
+
+func (f fakeRoles) ListPermissionsByUserID(_ context.Context, userID int64) ([]string, error) {
+	return f.permissions[userID], nil
+}
+
+// fakeAuthenticator validates first-party sessions like the auth controller, against the fake sessions
+type fakeAuthenticator struct {
+	sessions *fakeSessions
+}
+
+func (f fakeAuthenticator) ValidateSession(ctx context.Context, sessionID string) error {
+	if active, _ := f.sessions.IsFamilyActive(ctx, sessionID); !active {
+		return ctrlAuth.ErrSessionRevoked
+	}
+	return nil
+}
+
+func (f fakeAuthenticator) AuthenticateAPIKey(context.Context, string) (ctrlAuth.APIKeyPrincipal, error) {
+	return ctrlAuth.APIKeyPrincipal{}, ctrlAuth.ErrInvalidAPIKey
+}
+
+type testServer struct {
+	*httptest.Server
+	repo      *fakeRegistry
+	userToken string // First-party access token of user 1001
+}
+
+// newTestServer serves the authorization server with the routes and middlewares of cmd/server,
+// plus a protected resource answering with the client acting for the user
+func newTestServer(t *testing.T) *testServer {
+	config.Init("test")
+	config.GetConfig().Set("JWT_SECRET", "test-secret")
+	config.GetConfig().Set("APP_BASE_URL", "http://localhost:8080")
+
+	repo := newFakeRegistry()
+	authenticator := fakeAuthenticator{sessions: repo.sessions}
+	_, err := repo.sessions.Create(context.Background(), model.Session{UserID: 1001, FamilyID: "first-party", Expires: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	userToken, err := jwtpkg.GenerateToken(jwtpkg.Subject{
+		UserID:      1001,
+		Email:       "test1@example.com",
+		SessionID:   "first-party",
+		Permissions: repo.roles.permissions[1001],
+	})
+	require.NoError(t, err)
+
+	h := New(ctrlAuthServer.New(repo), "http://localhost:3000/oauth/consent")
+	r := chi.NewRouter()
+	r.Route("/api/v1/oauth", func(r chi.Router) {
+		r.Get("/authorize", h.Authorize())
+		r.Post("/token", h.Token())
+		r.Post("/introspect", h.Introspect())
+		r.Post("/revoke", h.Revoke())
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireAuth(authenticator))
+			r.Use(middleware.RequireSession)
+			r.Post("/authorize", h.Consent())
+
+			r.With(middleware.RequirePermission(model.PermissionOAuthClientsManage)).Post("/clients", h.CreateClient())
+		})
+	})
+	r.With(middleware.RequireAuth(authenticator), middleware.RequirePermission(model.PermissionUsersRead)).
+		Get("/api/v1/users", func(w http.ResponseWriter, r *http.Request) {
+			clientID, _ := middleware.ClientIDFromContext(r.Context())
+			_, _ = w.Write([]byte(clientID))
+		})
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return &testServer{Server: srv, repo: repo, userToken: userToken}
+}
+
+func (s *testServer) do(t *testing.T, req *http.Request) (*http.Response, map[string]interface{}) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body := map[string]interface{}{}
+	if res.Header.Get("Content-Type") == "application/json" {
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	}
+	return res, body
+}
+
+func (s *testServer) postJSON(t *testing.T, path, token string, body interface{}) (*http.Response, map[string]interface{}) {
+	raw, err := json.Marshal(body)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, s.URL+path, strings.NewReader(string(raw)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	return s.do(t, req)
+}
+
+func (s *testServer) postForm(t *testing.T, path string, client ctrlAuthServer.ClientCredentials, form url.Values) (*http.Response, map[string]interface{}) {
+	// Confidential clients use HTTP Basic, public ones only identify themselves
+	if client.ClientSecret == "" {
+		body := url.Values{"client_id": {client.ClientID}}
+		for key, values := range form {
+			body[key] = values
+		}
+		form = body
+	}
+	req, err := http.NewRequest(http.MethodPost, s.URL+path, strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if client.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(client.ClientID), url.QueryEscape(client.ClientSecret))
+	}
+	return s.do(t, req)
+}
+
+func (s *testServer) get(t *testing.T, path, token string) (*http.Response, map[string]interface{}) {
+	req, err := http.NewRequest(http.MethodGet, s.URL+path, nil)
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return s.do(t, req)
+}
+
+func (s *testServer) registerClient(t *testing.T, body CreateClientRequest) ctrlAuthServer.ClientCredentials {
+	res, rs := s.postJSON(t, "/api/v1/oauth/clients", s.userToken, body)
+	require.Equal(t, http.StatusOK, res.StatusCode, rs)
+	credentials := ctrlAuthServer.ClientCredentials{ClientID: rs["client_id"].(string)}
+	if secret, ok := rs["client_secret"].(string); ok {
+		credentials.ClientSecret = secret
+	}
+	return credentials
+}
+
+func pkce() (verifier, challenge string) {
+	verifier = strings.Repeat("verifier-", 6)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// authorize runs the authorization request, the user's approval and returns the code sent to the redirect URI
+func (s *testServer) authorize(t *testing.T, clientID, scope, challenge string) string {
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"scope":                 {scope},
+		"state":                 {"xyz"},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	res, _ := s.get(t, "/api/v1/oauth/authorize?"+q.Encode(), "")
+	require.Equal(t, http.StatusFound, res.StatusCode)
+	consent, err := url.Parse(res.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "/oauth/consent", consent.Path)
+	require.Equal(t, "Web app", consent.Query().Get("client_name"))
+	require.Equal(t, "http://localhost:3000/callback", consent.Query().Get("redirect_uri"))
+
+	// The consent page posts the request back with the user's decision
+	decision := map[string]interface{}{"approved": true}
+	for key := range consent.Query() {
+		decision[key] = consent.Query().Get(key)
+	}
+	res, rs := s.postJSON(t, "/api/v1/oauth/authorize", s.userToken, decision)
+	require.Equal(t, http.StatusOK, res.StatusCode, rs)
+	redirectTo, err := url.Parse(rs["redirect_to"].(string))
+	require.NoError(t, err)
+	require.Equal(t, "xyz", redirectTo.Query().Get("state"))
+	require.NotEmpty(t, redirectTo.Query().Get("code"))
+	return redirectTo.Query().Get("code")
+}
+
+func TestAuthorizationCodeGrant(t *testing.T) {
+	s := newTestServer(t)
+	client := s.registerClient(t, CreateClientRequest{
+		Name:         "Web app",
+		RedirectURIs: []string{"http://localhost:3000/callback"},
+		GrantTypes:   []string{model.GrantTypeAuthorizationCode, model.GrantTypeRefreshToken},
+		Scopes:       []string{model.PermissionUsersRead, model.PermissionUsersUpdate},
+	})
+	require.Empty(t, client.ClientSecret)
+	verifier, challenge := pkce()
+
+	// Wrong verifier, the code is burnt anyway
+	code := s.authorize(t, client.ClientID, model.PermissionUsersRead, challenge)
+	res, rs := s.postForm(t, "/api/v1/oauth/token", client, url.Values{
+		"grant_type":    {model.GrantTypeAuthorizationCode},
+		"code":          {code},
+		"code_verifier": {strings.Repeat("x", 43)},
+	})
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, "invalid_grant", rs["error"])
+
+	// Exchange
+	code = s.authorize(t, client.ClientID, model.PermissionUsersRead, challenge)
+	exchange := url.Values{
+		"grant_type":    {model.GrantTypeAuthorizationCode},
+		"code":          {code},
+		"redirect_uri":  {"http://localhost:3000/callback"},
+		"code_verifier": {verifier},
+	}
+	res, rs = s.postForm(t, "/api/v1/oauth/token", client, exchange)
+	require.Equal(t, http.StatusOK, res.StatusCode, rs)
+	require.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+	require.Equal(t, "Bearer", rs["token_type"])
+	require.Equal(t, model.PermissionUsersRead, rs["scope"])
+	accessToken, refreshToken := rs["access_token"].(string), rs["refresh_token"].(string)
+	require.NotEmpty(t, refreshToken)
+
+	// The token only carries the granted scope and cannot manage the user's credentials
+	res, _ = s.get(t, "/api/v1/users", accessToken)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res, rs = s.postJSON(t, "/api/v1/oauth/authorize", accessToken, map[string]interface{}{})
+	require.Equal(t, http.StatusForbidden, res.StatusCode)
+	require.Equal(t, "session_required", rs["error"])
+
+	// Public clients cannot introspect, anyone knows their client_id
+	res, rs = s.postForm(t, "/api/v1/oauth/introspect", client, url.Values{"token": {accessToken}})
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	require.Equal(t, "invalid_client", rs["error"])
+
+	// Refresh rotates the token, presenting the old one again revokes the grant
+	refresh := url.Values{"grant_type": {model.GrantTypeRefreshToken}, "refresh_token": {refreshToken}}
+	res, rs = s.postForm(t, "/api/v1/oauth/token", client, refresh)
+	require.Equal(t, http.StatusOK, res.StatusCode, rs)
+	newRefreshToken := rs["refresh_token"].(string)
+	require.NotEqual(t, refreshToken, newRefreshToken)
+
+	res, rs = s.postForm(t, "/api/v1/oauth/token", client, refresh)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, "invalid_grant", rs["error"])
+	res, _ = s.postForm(t, "/api/v1/oauth/token", client, url.Values{"grant_type": {model.GrantTypeRefreshToken}, "refresh_token": {newRefreshToken}})
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	// Revocation also rejects the access tokens of the grant
+	code = s.authorize(t, client.ClientID, "", challenge)
+	exchange.Set("code", code)
+	res, rs = s.postForm(t, "/api/v1/oauth/token", client, exchange)
+	require.Equal(t, http.StatusOK, res.StatusCode, rs)
+	require.Equal(t, model.PermissionUsersRead+" "+model.PermissionUsersUpdate, rs["scope"])
+	accessToken = rs["access_token"].(string)
+
+	res, _ = s.postForm(t, "/api/v1/oauth/revoke", client, url.Values{"token": {rs["refresh_token"].(string)}})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res, _ = s.get(t, "/api/v1/users", accessToken)
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	// Unknown tokens are ignored
+	res, _ = s.postForm(t, "/api/v1/oauth/revoke", client, url.Values{"token": {"unknown"}})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestAuthorizationCodeGrant_Replay(t *testing.T) {
+	s := newTestServer(t)
+	client := s.registerClient(t, CreateClientRequest{
+		Name:         "Web app",
+		RedirectURIs: []string{"http://localhost:3000/callback"},
+		GrantTypes:   []string{model.GrantTypeAuthorizationCode, model.GrantTypeRefreshToken},
+		Scopes:       []string{model.PermissionUsersRead},
+	})
+	verifier, challenge := pkce()
+	exchange := url.Values{
+		"grant_type":    {model.GrantTypeAuthorizationCode},
+		"code":          {s.authorize(t, client.ClientID, model.PermissionUsersRead, challenge)},
+		"code_verifier": {verifier},
+	}
+	res, rs := s.postForm(t, "/api/v1/oauth/token", client, exchange)
+	require.Equal(t, http.StatusOK, res.StatusCode, rs)
+	accessToken, refreshToken := rs["access_token"].(string), rs["refresh_token"].(string)
+	res, _ = s.get(t, "/api/v1/users", accessToken)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// Codes are single use
+	res, rs = s.postForm(t, "/api/v1/oauth/token", client, exchange)
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, "invalid_grant", rs["error"])
+
+	// The replay revokes the tokens issued for the code
+	res, _ = s.get(t, "/api/v1/users", accessToken)
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	res, rs = s.postForm(t, "/api/v1/oauth/token", client, url.Values{"grant_type": {model.GrantTypeRefreshToken}, "refresh_token": {refreshToken}})
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, "invalid_grant", rs["error"])
+}
+
+func TestIntrospection(t *testing.T) {
+	s := newTestServer(t)
+	client := s.registerClient(t, CreateClientRequest{
+		Name:         "Web app",
+		RedirectURIs: []string{"http://localhost:3000/callback"},
+		GrantTypes:   []string{model.GrantTypeAuthorizationCode, model.GrantTypeRefreshToken},
+		Scopes:       []string{model.PermissionUsersRead},
+		Confidential: true,
+	})
+	other := s.registerClient(t, CreateClientRequest{
+		Name:         "Billing",
+		GrantTypes:   []string{model.GrantTypeClientCredentials},
+		Scopes:       []string{model.PermissionUsersRead},
+		Confidential: true,
+	})
+	verifier, challenge := pkce()
+	res, rs := s.postForm(t, "/api/v1/oauth/token", client, url.Values{
+		"grant_type":    {model.GrantTypeAuthorizationCode},
+		"code":          {s.authorize(t, client.ClientID, model.PermissionUsersRead, challenge)},
+		"code_verifier": {verifier},
+	})
+	require.Equal(t, http.StatusOK, res.StatusCode, rs)
+	accessToken, refreshToken := rs["access_token"].(string), rs["refresh_token"].(string)
+
+	// Tokens are described to the client they were issued to
+	res, rs = s.postForm(t, "/api/v1/oauth/introspect", client, url.Values{"token": {accessToken}})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, true, rs["active"])
+	require.Equal(t, client.ClientID, rs["client_id"])
+	require.Equal(t, "test1@example.com", rs["username"])
+	require.Equal(t, model.PermissionUsersRead, rs["scope"])
+	require.NotZero(t, rs["exp"])
+	res, rs = s.postForm(t, "/api/v1/oauth/introspect", client, url.Values{"token": {refreshToken}})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, true, rs["active"])
+
+	// Other clients only learn that they are inactive
+	for _, token := range []string{accessToken, refreshToken, s.userToken} {
+		res, rs = s.postForm(t, "/api/v1/oauth/introspect", other, url.Values{"token": {token}})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, map[string]interface{}{"active": false}, rs)
+	}
+
+	// Revoked grants are inactive
+	res, _ = s.postForm(t, "/api/v1/oauth/revoke", client, url.Values{"token": {refreshToken}})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	for _, token := range []string{accessToken, refreshToken} {
+		res, rs = s.postForm(t, "/api/v1/oauth/introspect", client, url.Values{"token": {token}})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, false, rs["active"])
+	}
+}
+
+func TestClientCredentialsGrant(t *testing.T) {
+	s := newTestServer(t)
+	client := s.registerClient(t, CreateClientRequest{
+		Name:         "Billing",
+		GrantTypes:   []string{model.GrantTypeClientCredentials},
+		Scopes:       []string{model.PermissionUsersRead},
+		Confidential: true,
+	})
+	require.NotEmpty(t, client.ClientSecret)
+
+	res, rs := s.postForm(t, "/api/v1/oauth/token", ctrlAuthServer.ClientCredentials{ClientID: client.ClientID, ClientSecret: "wrong"}, url.Values{
+		"grant_type": {model.GrantTypeClientCredentials},
+	})
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	require.Equal(t, "invalid_client", rs["error"])
+	require.NotEmpty(t, res.Header.Get("WWW-Authenticate"))
+
+	res, rs = s.postForm(t, "/api/v1/oauth/token", client, url.Values{"grant_type": {model.GrantTypeAuthorizationCode}})
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, "unauthorized_client", rs["error"])
+
+	res, rs = s.postForm(t, "/api/v1/oauth/token", client, url.Values{
+		"grant_type": {model.GrantTypeClientCredentials},
+		"scope":      {model.PermissionUsersUpdate},
+	})
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, "invalid_scope", rs["error"])
+
+	res, rs = s.postForm(t, "/api/v1/oauth/token", client, url.Values{"grant_type": {model.GrantTypeClientCredentials}})
+	require.Equal(t, http.StatusOK, res.StatusCode, rs)
+	require.Nil(t, rs["refresh_token"])
+	accessToken := rs["access_token"].(string)
+
+	res, rs = s.postForm(t, "/api/v1/oauth/introspect", client, url.Values{"token": {accessToken}})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, true, rs["active"])
+	require.Equal(t, "client:"+client.ClientID, rs["sub"])
+	require.Equal(t, model.PermissionUsersRead, rs["scope"])
+
+	// Client tokens are not user tokens
+	res, _ = s.get(t, "/api/v1/users", accessToken)
+	require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	res, rs = s.postForm(t, "/api/v1/oauth/revoke", client, url.Values{"token": {accessToken}})
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	require.Equal(t, "unsupported_token_type", rs["error"])
+}
+
+func TestHandler_Authorize(t *testing.T) {
+	type args struct {
+		query       url.Values
+		expStatus   int
+		expError    string // Error code in the JSON body
+		expRedirect string // Error code sent to the redirect URI
+	}
+	_, challenge := pkce()
+	tcs := map[string]args{
+		"err - unknown client": {
+			query:     url.Values{"client_id": {"unknown"}},
+			expStatus: http.StatusUnauthorized,
+			expError:  "invalid_client",
+		},
+		"err - redirect uri not registered": {
+			query:     url.Values{"redirect_uri": {"http://evil.example.com/callback"}},
+			expStatus: http.StatusBadRequest,
+			expError:  "invalid_request",
+		},
+		"err - unsupported response type": {
+			query:       url.Values{"response_type": {"token"}},
+			expStatus:   http.StatusFound,
+			expRedirect: "unsupported_response_type",
+		},
+		"err - missing pkce": {
+			query:       url.Values{"code_challenge": {""}},
+			expStatus:   http.StatusFound,
+			expRedirect: "invalid_request",
+		},
+		"err - plain pkce": {
+			query:       url.Values{"code_challenge_method": {"plain"}},
+			expStatus:   http.StatusFound,
+			expRedirect: "invalid_request",
+		},
+		"err - scope not allowed": {
+			query:       url.Values{"scope": {model.PermissionOAuthClientsManage}},
+			expStatus:   http.StatusFound,
+			expRedirect: "invalid_scope",
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			s := newTestServer(t)
+			client := s.registerClient(t, CreateClientRequest{
+				Name:         "Web app",
+				RedirectURIs: []string{"http://localhost:3000/callback"},
+				GrantTypes:   []string{model.GrantTypeAuthorizationCode},
+				Scopes:       []string{model.PermissionUsersRead},
+			})
+
+			q := url.Values{
+				"response_type":         {"code"},
+				"client_id":             {client.ClientID},
+				"state":                 {"xyz"},
+				"code_challenge":        {challenge},
+				"code_challenge_method": {"S256"},
+			}
+			for key := range tc.query {
+				q.Set(key, tc.query.Get(key))
+			}
+			res, rs := s.get(t, "/api/v1/oauth/authorize?"+q.Encode(), "")
+
+			require.Equal(t, tc.expStatus, res.StatusCode)
+			if tc.expError != "" {
+				require.Equal(t, tc.expError, rs["error"])
+				return
+			}
+			location, err := url.Parse(res.Header.Get("Location"))
+			require.NoError(t, err)
+			require.Equal(t, "localhost:3000", location.Host)
+			require.Equal(t, tc.expRedirect, location.Query().Get("error"))
+			require.Equal(t, "xyz", location.Query().Get("state"))
+		})
+	}
+}
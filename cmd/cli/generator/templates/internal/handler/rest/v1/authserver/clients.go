@@ -0,0 +1,173 @@
+package authserver
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	ctrlAuthServer "github.com/namf2001/go-backend-template/internal/controller/authserver"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/validator"
+)
+
+// CreateClientRequest represents the request body for registering an OAuth client
+type CreateClientRequest struct {
+	Name         string   `json:"name" validate:"required,max=255"`
+	RedirectURIs []string `json:"redirect_uris" validate:"omitempty,dive,required,url"`
+	GrantTypes   []string `json:"grant_types" validate:"required,min=1,dive,oneof=authorization_code refresh_token client_credentials"`
+	Scopes       []string `json:"scopes" validate:"omitempty,dive,required"` // Permissions the client may ask for
+	Confidential bool     `json:"confidential"`                              // Server side clients able to keep a secret
+}
+
+// ClientResponse represents a registered OAuth client
+type ClientResponse struct {
+	ID           int64     `json:"id"`
+	ClientID     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	Confidential bool      `json:"confidential"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types"`
+	Scopes       []string  `json:"scopes"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CreateClientResponse represents a new OAuth client, the secret is not shown again
+type CreateClientResponse struct {
+	ClientResponse
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// ListClientsResponse represents the response for listing OAuth clients
+type ListClientsResponse struct {
+	Clients []ClientResponse `json:"clients"`
+}
+
+func newClientResponse(c model.OAuthClient) ClientResponse {
+	return ClientResponse{
+		ID:           c.ID,
+		ClientID:     c.ClientID,
+		Name:         c.Name,
+		Confidential: c.IsConfidential(),
+		RedirectURIs: nonNil(c.RedirectURIs),
+		GrantTypes:   nonNil(c.GrantTypes),
+		Scopes:       nonNil(c.Scopes),
+		CreatedAt:    c.CreatedAt,
+	}
+}
+
+// CreateClient registers an OAuth client
+// @Summary      Create OAuth client
+// @Description  Register an app allowed to get tokens from this service. The secret of confidential clients is only returned once.
+// @Tags         oauth
+// @Accept       json
+// @Produce      json
+// @Param        input body authserver.CreateClientRequest true "Client metadata"
+// @Success      200  {object} authserver.CreateClientResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /oauth/clients [post]
+func (h *Handler) CreateClient() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		var req CreateClientRequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		created, err := h.ctrl.CreateClient(r.Context(), ctrlAuthServer.CreateClientInput{
+			Name:         req.Name,
+			RedirectURIs: req.RedirectURIs,
+			GrantTypes:   req.GrantTypes,
+			Scopes:       req.Scopes,
+			Confidential: req.Confidential,
+			CreatedBy:    userID,
+		})
+		if err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, CreateClientResponse{
+			ClientResponse: newClientResponse(created.OAuthClient),
+			ClientSecret:   created.Secret,
+		})
+		return nil
+	})
+}
+
+// ListClients lists the OAuth clients
+// @Summary      List OAuth clients
+// @Description  List every registered OAuth client, without their secret
+// @Tags         oauth
+// @Produce      json
+// @Success      200  {object} authserver.ListClientsResponse
+// @Failure      401  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /oauth/clients [get]
+func (h *Handler) ListClients() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		clients, err := h.ctrl.ListClients(r.Context())
+		if err != nil {
+			return convertError(err)
+		}
+
+		resp := ListClientsResponse{Clients: make([]ClientResponse, 0, len(clients))}
+		for _, c := range clients {
+			resp.Clients = append(resp.Clients, newClientResponse(c))
+		}
+
+		httpserv.RespondJSON(r.Context(), w, resp)
+		return nil
+	})
+}
+
+// DeleteClient deletes an OAuth client
+// @Summary      Delete OAuth client
+// @Description  Delete an OAuth client, the refresh tokens it holds stop working
+// @Tags         oauth
+// @Produce      json
+// @Param        id   path      int  true  "Client ID"
+// @Success      204  {object} nil
+// @Failure      401  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /oauth/clients/{id} [delete]
+func (h *Handler) DeleteClient() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			return webErrClientNotFound
+		}
+
+		if err := h.ctrl.DeleteClient(r.Context(), id); err != nil {
+			return convertError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
+
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
@@ -0,0 +1,65 @@
+package authserver
+
+import (
+	"errors"
+	"net/http"
+
+	ctrlAuthServer "github.com/namf2001/go-backend-template/internal/controller/authserver"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+)
+
+// Error codes follow RFC 6749 section 5.2, httpserv.Error already has its error and error_description fields
+var (
+	webErrValidationFailed        = &httpserv.Error{Status: http.StatusBadRequest, Code: "validation_failed", Desc: "Validation failed"}
+	webErrUnauthenticated         = &httpserv.Error{Status: http.StatusUnauthorized, Code: "unauthenticated", Desc: "Authentication required"}
+	webErrInvalidRequest          = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_request", Desc: "Invalid or missing request parameter"}
+	webErrPKCERequired            = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_request", Desc: "A code_challenge with the S256 code_challenge_method is required"}
+	webErrInvalidRedirectURI      = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_request", Desc: "The redirect_uri is not registered for the client"}
+	webErrInvalidClient           = &httpserv.Error{Status: http.StatusUnauthorized, Code: "invalid_client", Desc: "Unknown client or client authentication failed"}
+	webErrInvalidGrant            = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_grant", Desc: "Invalid, expired or already used authorization code or refresh token"}
+	webErrUnauthorizedClient      = &httpserv.Error{Status: http.StatusBadRequest, Code: "unauthorized_client", Desc: "The client is not allowed to use this grant type"}
+	webErrUnsupportedGrantType    = &httpserv.Error{Status: http.StatusBadRequest, Code: "unsupported_grant_type", Desc: "Unsupported grant type"}
+	webErrUnsupportedResponseType = &httpserv.Error{Status: http.StatusBadRequest, Code: "unsupported_response_type", Desc: "Only the code response type is supported"}
+	webErrInvalidScope            = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_scope", Desc: "The scope is not allowed for the client"}
+	webErrAccessDenied            = &httpserv.Error{Status: http.StatusForbidden, Code: "access_denied", Desc: "The user denied the authorization request"}
+	webErrUnsupportedTokenType    = &httpserv.Error{Status: http.StatusBadRequest, Code: "unsupported_token_type", Desc: "Client credentials tokens cannot be revoked, they expire on their own"}
+	webErrInvalidClientMetadata   = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_client_metadata", Desc: "Invalid client metadata"}
+	webErrClientNotFound          = &httpserv.Error{Status: http.StatusNotFound, Code: "client_not_found", Desc: "OAuth client not found"}
+)
+
+func convertError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, ctrlAuthServer.ErrInvalidRequest):
+		return webErrInvalidRequest
+	case errors.Is(err, ctrlAuthServer.ErrPKCERequired):
+		return webErrPKCERequired
+	case errors.Is(err, ctrlAuthServer.ErrInvalidRedirectURI):
+		return webErrInvalidRedirectURI
+	case errors.Is(err, ctrlAuthServer.ErrInvalidClient):
+		return webErrInvalidClient
+	case errors.Is(err, ctrlAuthServer.ErrInvalidGrant):
+		return webErrInvalidGrant
+	case errors.Is(err, ctrlAuthServer.ErrUnauthorizedClient):
+		return webErrUnauthorizedClient
+	case errors.Is(err, ctrlAuthServer.ErrUnsupportedGrantType):
+		return webErrUnsupportedGrantType
+	case errors.Is(err, ctrlAuthServer.ErrUnsupportedResponseType):
+		return webErrUnsupportedResponseType
+	case errors.Is(err, ctrlAuthServer.ErrInvalidScope):
+		return webErrInvalidScope
+	case errors.Is(err, ctrlAuthServer.ErrAccessDenied):
+		return webErrAccessDenied
+	case errors.Is(err, ctrlAuthServer.ErrUnsupportedTokenType):
+		return webErrUnsupportedTokenType
+	case errors.Is(err, ctrlAuthServer.ErrInvalidClientMetadata):
+		return &httpserv.Error{Status: webErrInvalidClientMetadata.Status, Code: webErrInvalidClientMetadata.Code, Desc: err.Error()}
+	case errors.Is(err, ctrlAuthServer.ErrClientNotFound):
+		return webErrClientNotFound
+	default:
+		return err
+	}
+}
@@ -0,0 +1,17 @@
+package authserver
+
+import (
+	"github.com/namf2001/go-backend-template/internal/controller/authserver"
+)
+
+type Handler struct {
+	ctrl       authserver.Controller
+	consentURL string // Frontend page asking the signed in user to approve an authorization request
+}
+
+func New(ctrl authserver.Controller, consentURL string) *Handler {
+	return &Handler{
+		ctrl:       ctrl,
+		consentURL: consentURL,
+	}
+}
@@ -0,0 +1,196 @@
+package authserver
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	ctrlAuthServer "github.com/namf2001/go-backend-template/internal/controller/authserver"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+)
+
+// TokenResponse is the successful response of the token endpoint (RFC 6749 section 5.1)
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+// IntrospectionResponse describes a token (RFC 7662 section 2.2)
+type IntrospectionResponse struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Nbf       int64    `json:"nbf,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	Aud       []string `json:"aud,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+}
+
+// Token issues tokens to OAuth clients
+// @Summary      OAuth token endpoint
+// @Description  Exchange an authorization code (with its PKCE code_verifier), a refresh token or the client's own
+// @Description  credentials for tokens. Confidential clients authenticate with HTTP Basic or client_id and client_secret.
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        grant_type     formData  string  true   "authorization_code, refresh_token or client_credentials"
+// @Param        code           formData  string  false  "Authorization code"
+// @Param        redirect_uri   formData  string  false  "Redirect URI of the authorization request"
+// @Param        code_verifier  formData  string  false  "PKCE code verifier"
+// @Param        refresh_token  formData  string  false  "Refresh token"
+// @Param        scope          formData  string  false  "Space separated scopes, to narrow a refresh or client credentials grant"
+// @Param        client_id      formData  string  false  "Client ID, when not sent with HTTP Basic"
+// @Param        client_secret  formData  string  false  "Client secret, when not sent with HTTP Basic"
+// @Success      200  {object} authserver.TokenResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Router       /oauth/token [post]
+func (h *Handler) Token() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if err := r.ParseForm(); err != nil {
+			return webErrInvalidRequest
+		}
+
+		tokens, err := h.ctrl.Token(r.Context(), ctrlAuthServer.TokenRequest{
+			GrantType:    r.PostForm.Get("grant_type"),
+			Client:       clientCredentials(r),
+			Code:         r.PostForm.Get("code"),
+			RedirectURI:  r.PostForm.Get("redirect_uri"),
+			CodeVerifier: r.PostForm.Get("code_verifier"),
+			RefreshToken: r.PostForm.Get("refresh_token"),
+			Scope:        r.PostForm.Get("scope"),
+		})
+		if err != nil {
+			return clientError(w, r, err)
+		}
+
+		httpserv.RespondJSONWithHeaders(r.Context(), w, TokenResponse{
+			AccessToken:  tokens.AccessToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int64(tokens.ExpiresIn.Seconds()),
+			RefreshToken: tokens.RefreshToken,
+			Scope:        strings.Join(tokens.Scopes, " "),
+		}, noStore)
+		return nil
+	})
+}
+
+// Introspect describes a token to the confidential client it was issued to
+// @Summary      OAuth token introspection
+// @Description  Tell a confidential client whether one of its tokens is active and what it grants (RFC 7662). Public clients are refused, tokens of other clients are reported inactive.
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token            formData  string  true   "Access or refresh token"
+// @Param        token_type_hint  formData  string  false  "Ignored, every token type is looked up"
+// @Success      200  {object} authserver.IntrospectionResponse
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Router       /oauth/introspect [post]
+func (h *Handler) Introspect() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("token") == "" {
+			return webErrInvalidRequest
+		}
+
+		introspection, err := h.ctrl.Introspect(r.Context(), clientCredentials(r), r.PostForm.Get("token"))
+		if err != nil {
+			return clientError(w, r, err)
+		}
+
+		httpserv.RespondJSONWithHeaders(r.Context(), w, IntrospectionResponse{
+			Active:    introspection.Active,
+			Scope:     strings.Join(introspection.Scopes, " "),
+			ClientID:  introspection.ClientID,
+			Username:  introspection.Username,
+			TokenType: introspection.TokenType,
+			Exp:       unix(introspection.ExpiresAt),
+			Iat:       unix(introspection.IssuedAt),
+			Nbf:       unix(introspection.NotBefore),
+			Sub:       introspection.Subject,
+			Aud:       introspection.Audience,
+			Iss:       introspection.Issuer,
+			Jti:       introspection.JTI,
+		}, noStore)
+		return nil
+	})
+}
+
+// Revoke revokes a token of a client
+// @Summary      OAuth token revocation
+// @Description  Revoke a refresh or access token issued to the authenticated client, with every token of the same grant (RFC 7009).
+// @Description  Unknown tokens are ignored.
+// @Tags         oauth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token            formData  string  true   "Access or refresh token"
+// @Param        token_type_hint  formData  string  false  "Ignored, every token type is looked up"
+// @Success      200
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Router       /oauth/revoke [post]
+func (h *Handler) Revoke() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("token") == "" {
+			return webErrInvalidRequest
+		}
+
+		if err := h.ctrl.Revoke(r.Context(), clientCredentials(r), r.PostForm.Get("token")); err != nil {
+			return clientError(w, r, err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+}
+
+// noStore keeps tokens out of caches (RFC 6749 section 5.1)
+var noStore = map[string]string{"Cache-Control": "no-store", "Pragma": "no-cache"}
+
+// clientCredentials reads the client authentication of RFC 6749 section 2.3.1, HTTP Basic first
+func clientCredentials(r *http.Request) ctrlAuthServer.ClientCredentials {
+	if clientID, clientSecret, ok := r.BasicAuth(); ok {
+		// Both are form encoded before being put in the header
+		if id, err := url.QueryUnescape(clientID); err == nil {
+			clientID = id
+		}
+		if secret, err := url.QueryUnescape(clientSecret); err == nil {
+			clientSecret = secret
+		}
+		return ctrlAuthServer.ClientCredentials{ClientID: clientID, ClientSecret: clientSecret}
+	}
+
+	return ctrlAuthServer.ClientCredentials{
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+	}
+}
+
+// clientError converts an error of an endpoint clients authenticate on, challenging clients that sent wrong credentials
+func clientError(w http.ResponseWriter, r *http.Request, err error) error {
+	webErr := convertError(err)
+	if webErr == webErrInvalidClient {
+		if _, _, ok := r.BasicAuth(); ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+	}
+	return webErr
+}
+
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -6,6 +6,9 @@ import (
 
 	ctrlUsers "github.com/namf2001/go-backend-template/internal/controller/users"
 	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/password"
+	repoAccounts "github.com/namf2001/go-backend-template/internal/repository/accounts"
+	repoRoles "github.com/namf2001/go-backend-template/internal/repository/roles"
 	repoUsers "github.com/namf2001/go-backend-template/internal/repository/users"
 )
 
@@ -15,6 +18,13 @@ var (
 	webErrValidationFailed = &httpserv.Error{Status: http.StatusBadRequest, Code: "validation_failed", Desc: "Validation failed"}
 	webErrUserExists       = &httpserv.Error{Status: http.StatusConflict, Code: "user_exists", Desc: "User with this email already exists"}
 	webErrUserNotFound     = &httpserv.Error{Status: http.StatusNotFound, Code: "user_not_found", Desc: "User not found"}
+
+	webErrUnauthenticated        = &httpserv.Error{Status: http.StatusUnauthorized, Code: "unauthenticated", Desc: "Authentication required"}
+	webErrRoleNotFound           = &httpserv.Error{Status: http.StatusNotFound, Code: "role_not_found", Desc: "Role not found"}
+	webErrInvalidCurrentPassword = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_current_password", Desc: "Current password is incorrect"}
+	webErrEmailChangeForbidden   = &httpserv.Error{Status: http.StatusForbidden, Code: "email_change_forbidden", Desc: "Only an administrator can change the email of a user"}
+	webErrAccountNotFound        = &httpserv.Error{Status: http.StatusNotFound, Code: "account_not_found", Desc: "No account of this provider is linked"}
+	webErrLastSignInMethod       = &httpserv.Error{Status: http.StatusConflict, Code: "last_sign_in_method", Desc: "Cannot unlink the last way to sign in, set a password or link another provider first"}
 )
 
 func convertError(err error) error {
@@ -22,12 +32,32 @@ func convertError(err error) error {
 		return nil
 	}
 
+	var policyErr *password.PolicyError
 	switch {
+	case errors.As(err, &policyErr):
+		return passwordPolicyError("new_password", policyErr)
 	case errors.Is(err, ctrlUsers.ErrUserExited):
 		return webErrUserExists
 	case errors.Is(err, repoUsers.ErrNotFound):
 		return webErrUserNotFound
+	case errors.Is(err, ctrlUsers.ErrInvalidCurrentPassword):
+		return webErrInvalidCurrentPassword
+	case errors.Is(err, repoRoles.ErrNotFound):
+		return webErrRoleNotFound
+	case errors.Is(err, repoAccounts.ErrNotFound):
+		return webErrAccountNotFound
+	case errors.Is(err, ctrlUsers.ErrLastSignInMethod):
+		return webErrLastSignInMethod
 	default:
 		return err
 	}
 }
+
+// passwordPolicyError reports every rule of the password policy the field breaks
+func passwordPolicyError(field string, err *password.PolicyError) *httpserv.Error {
+	webErr := &httpserv.Error{Status: webErrValidationFailed.Status, Code: webErrValidationFailed.Code, Desc: "Password does not meet the password policy"}
+	for _, v := range err.Violations {
+		webErr.Fields = append(webErr.Fields, httpserv.FieldError{Field: field, Rule: v.Rule, Message: v.Message})
+	}
+	return webErr
+}
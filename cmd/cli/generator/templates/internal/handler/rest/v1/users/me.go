@@ -0,0 +1,145 @@
+package users
+
+import (
+	"net/http"
+
+	ctrlUsers "github.com/namf2001/go-backend-template/internal/controller/users"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/validator"
+)
+
+// MeResponse represents the response for the current user's profile
+type MeResponse struct {
+	User model.User `json:"user"`
+}
+
+// UpdateMeRequest represents the request for updating the current user's profile
+type UpdateMeRequest struct {
+	Name  string `json:"name" validate:"omitempty,min=2,max=100"`
+	Image string `json:"image" validate:"omitempty,url"`
+}
+
+// ChangePasswordRequest represents the request for changing the current user's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"` // Checked against the password policy
+}
+
+// Me handles the retrieval of the current user's profile
+// @Summary      Get current user
+// @Description  Get the profile of the authenticated user
+// @Tags         me
+// @Produce      json
+// @Success      200  {object} users.MeResponse
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /me [get]
+func (h Handler) Me() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		user, err := h.userCtrl.GetUser(r.Context(), userID)
+		if err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, MeResponse{User: user})
+		return nil
+	})
+}
+
+// UpdateMe handles the update of the current user's profile
+// @Summary      Update current user
+// @Description  Update the profile of the authenticated user. API keys and OAuth client tokens are refused.
+// @Tags         me
+// @Accept       json
+// @Produce      json
+// @Param        input  body      users.UpdateMeRequest  true  "Profile info"
+// @Success      200  {object} users.MeResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /me [patch]
+func (h Handler) UpdateMe() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		var req UpdateMeRequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		input := ctrlUsers.UpdateProfileInput{
+			Name:  req.Name,
+			Image: req.Image,
+		}
+
+		user, err := h.userCtrl.UpdateProfile(r.Context(), userID, input)
+		if err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, MeResponse{User: user})
+		return nil
+	})
+}
+
+// ChangePassword handles the password change of the current user
+// @Summary      Change password
+// @Description  Change the password of the authenticated user. Other sessions are revoked. A password breaking the password policy is refused with every broken rule in fields.
+// @Tags         me
+// @Accept       json
+// @Produce      json
+// @Param        input  body      users.ChangePasswordRequest  true  "Passwords"
+// @Success      204  {object} nil
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /me/password [post]
+func (h Handler) ChangePassword() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+		sessionID, _ := middleware.SessionIDFromContext(r.Context())
+
+		var req ChangePasswordRequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		input := ctrlUsers.ChangePasswordInput{
+			CurrentPassword: req.CurrentPassword,
+			NewPassword:     req.NewPassword,
+			SessionID:       sessionID,
+		}
+
+		if err := h.userCtrl.ChangePassword(r.Context(), userID, input); err != nil {
+			return convertError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	ctrlUsers "github.com/namf2001/go-backend-template/internal/controller/users"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/model"
 	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
 	"github.com/namf2001/go-backend-template/internal/pkg/validator"
 )
@@ -16,9 +18,10 @@ type UpdateUserRequest struct {
 	Name  string `json:"name" validate:"omitempty,min=2,max=100"`
 }
 
-// UpdateUser handles the updating of a user by ID
+// UpdateUser handles the updating of a user by ID. Users without the users:update permission editing their own
+// record can only change their profile, as on PATCH /me.
 // @Summary      Update user
-// @Description  Update user details
+// @Description  Update user details. Changing the email requires the users:update permission and resets its verification.
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -26,6 +29,7 @@ type UpdateUserRequest struct {
 // @Param        input  body      users.UpdateUserRequest  true  "Update info"
 // @Success      204  {object} nil
 // @Failure      400  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
 // @Failure      500  {object} httpserv.Error
 // @Security     BearerAuth
 // @Router       /users/{id} [put]
@@ -46,6 +50,19 @@ func (h Handler) UpdateUser() http.HandlerFunc {
 			return webErrValidationFailed
 		}
 
+		if !middleware.HasPermission(r.Context(), model.PermissionUsersUpdate) {
+			// Reached as the owner
+			if req.Email != "" {
+				return webErrEmailChangeForbidden
+			}
+			if _, err := h.userCtrl.UpdateProfile(r.Context(), id, ctrlUsers.UpdateProfileInput{Name: req.Name}); err != nil {
+				return convertError(err)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+
 		input := ctrlUsers.UpdateUserInput{
 			Email: req.Email,
 			Name:  req.Name,
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// APIKey is a personal key a user gives to a machine client instead of their password
+type APIKey struct {
+	ID         int64      `json:"id" db:"id"`
+	UserID     int64      `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"` // Start of the key, shown to tell keys apart
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"` // Permissions the key is limited to, all of the user's when empty
+	ExpiresAt  *time.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsActive reports whether the key can still authenticate requests
+func (k APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// LoginAttempt tracks the recent failed logins of an account or of a client IP
+type LoginAttempt struct {
+	Key           string     `json:"key" db:"key"`
+	Failures      int        `json:"failures" db:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at" db:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until" db:"locked_until"`
+}
+
+// IsLocked reports whether the key is locked out at the given time
+func (a LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && a.LockedUntil.After(now)
+}
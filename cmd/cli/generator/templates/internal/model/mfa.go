@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// MFA holds the TOTP second factor of a user
+type MFA struct {
+	UserID       int64      `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"` // Encrypted
+	EnabledAt    *time.Time `json:"enabled_at" db:"enabled_at"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsEnabled reports whether the user confirmed enrollment
+func (m MFA) IsEnabled() bool {
+	return m.EnabledAt != nil
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// RateLimit allows Limit requests per Period. Requests are let through as a token bucket refilled
+// one token every Period/Limit, so a full bucket allows a burst of Limit requests.
+type RateLimit struct {
+	Limit  int
+	Period time.Duration
+}
+
+// Interval is the time it takes to refill one token
+func (l RateLimit) Interval() time.Duration {
+	return l.Period / time.Duration(l.Limit)
+}
+
+// RateLimitResult is the outcome of taking a token from the bucket of a key
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int           // Tokens left in the bucket
+	ResetAfter time.Duration // Until the bucket is full again
+	RetryAfter time.Duration // Until the next token when the request was refused, zero otherwise
+}
+
+// NewRateLimitResult describes the bucket of a key from its theoretical arrival time (GCRA): the time
+// the bucket is full again, pushed back by Interval for every token taken.
+func NewRateLimitResult(limit RateLimit, tat, now time.Time, allowed bool) RateLimitResult {
+	result := RateLimitResult{Allowed: allowed, Limit: limit.Limit}
+
+	resetAfter := tat.Sub(now)
+	if resetAfter < 0 {
+		resetAfter = 0
+	}
+	result.ResetAfter = resetAfter
+	result.Remaining = int((limit.Period - resetAfter) / limit.Interval())
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+
+	if !allowed {
+		result.RetryAfter = resetAfter + limit.Interval() - limit.Period
+		if result.RetryAfter < 0 {
+			result.RetryAfter = 0
+		}
+	}
+	return result
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+const (
+	// RoleAdmin has every permission
+	RoleAdmin = "admin"
+	// RoleUser is granted to every new user
+	RoleUser = "user"
+)
+
+const (
+	PermissionUsersRead   = "users:read"
+	PermissionUsersCreate = "users:create"
+	PermissionUsersUpdate = "users:update"
+	PermissionUsersDelete = "users:delete"
+	PermissionRolesManage = "roles:manage"
+
+	PermissionOAuthClientsManage = "oauth_clients:manage"
+
+	// PermissionProfileRead lets API keys and OAuth clients read the user's own profile, every user has it
+	PermissionProfileRead = "profile:read"
+)
+
+// Role represents a named set of permissions granted to users
+type Role struct {
+	ID          int64     `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
@@ -24,9 +24,17 @@ var (
 // Error represents a handler error. It contains web-related information such as HTTP status code, error code and
 // error description
 type Error struct {
-	Status int    `json:"-"`
-	Code   string `json:"error"`             // Since there is existing dependency at infinity end, unable to fix the json key name
-	Desc   string `json:"error_description"` // Since there is existing dependency at infinity end, unable to fix the json key name
+	Status int          `json:"-"`
+	Code   string       `json:"error"`             // Since there is existing dependency at infinity end, unable to fix the json key name
+	Desc   string       `json:"error_description"` // Since there is existing dependency at infinity end, unable to fix the json key name
+	Fields []FieldError `json:"fields,omitempty"`  // Details of a validation error
+}
+
+// FieldError is a rule a field of the request breaks
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 // Error satisfies the error interface
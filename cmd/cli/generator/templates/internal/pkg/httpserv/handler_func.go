@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"go.uber.org/zap"
 )
 
 // ErrHandlerFunc is a convenience wrapper for http.HandlerFunc that handles error transformation and reporting.
@@ -22,7 +23,7 @@ func ErrHandlerFunc(h func(w http.ResponseWriter, r *http.Request) error) http.H
 				}
 			}
 
-			logger.ERROR.Printf("[ErrHandlerFunc] %v", err)
+			logger.Error(ctx, "request failed", zap.Error(err))
 		}
 	}
 }
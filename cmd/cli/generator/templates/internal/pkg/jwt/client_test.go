@@ -0,0 +1,36 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring_ClientToken(t *testing.T) {
+	k := loadTestKeyring(t, map[string]string{"APP_BASE_URL": testBaseURL, "JWT_SECRET": "test-secret"})
+
+	clientToken, err := k.GenerateClientToken("billing", []string{"users:read", "users:update"})
+	require.NoError(t, err)
+	accessToken, err := k.GenerateToken(Subject{UserID: 1001, ClientID: "web-app", Scopes: []string{"users:read"}})
+	require.NoError(t, err)
+
+	claims, err := k.ParseClientToken(clientToken)
+	require.NoError(t, err)
+	require.Equal(t, "client:billing", claims.Subject)
+	require.Equal(t, "billing", claims.ClientID)
+	require.Equal(t, []string{"users:read", "users:update"}, claims.Scopes())
+	require.Zero(t, claims.UserID)
+
+	// A token issued on behalf of a user keeps the user as subject
+	claims, err = k.ParseToken(accessToken)
+	require.NoError(t, err)
+	require.Equal(t, int64(1001), claims.UserID)
+	require.Equal(t, "web-app", claims.ClientID)
+	require.Equal(t, "users:read", claims.Scope)
+
+	// A client token never authenticates a user, nor the other way around
+	_, err = k.ParseToken(clientToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = k.ParseClientToken(accessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,215 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyPair writes the private key and its public key as PEM files and returns their paths
+func writeKeyPair(t *testing.T, private crypto.Signer) (string, string) {
+	dir := t.TempDir()
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	privatePath := filepath.Join(dir, "private.pem")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	der, err = x509.MarshalPKIXPublicKey(private.Public())
+	require.NoError(t, err)
+	publicPath := filepath.Join(dir, "public.pem")
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	return privatePath, publicPath
+}
+
+// testBaseURL is the APP_BASE_URL tokens are issued for in tests
+const testBaseURL = "http://localhost:8080"
+
+var keyringSettings = []string{
+	"JWT_SECRET", "JWT_ALGORITHM", "JWT_SIGNING_KEY_FILE", "JWT_VERIFICATION_KEY_FILES",
+	"APP_BASE_URL", "JWT_ISSUER", "JWT_AUDIENCE", "JWT_LEEWAY", "JWT_SUBJECT_PREFIX",
+}
+
+// loadTestKeyring loads a keyring from the given settings
+func loadTestKeyring(t *testing.T, settings map[string]string) *Keyring {
+	config.Init("test")
+	cfg := config.GetConfig()
+	for _, name := range keyringSettings {
+		cfg.Set(name, settings[name])
+	}
+
+	k, err := LoadKeyring()
+	require.NoError(t, err)
+	return k
+}
+
+func TestKeyring_GenerateToken(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPrivate, _ := writeKeyPair(t, rsaKey)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edPrivate, _ := writeKeyPair(t, edKey)
+
+	type args struct {
+		settings map[string]string
+		expAlg   string
+		expKid   bool
+		expJWKS  int
+	}
+	tcs := map[string]args{
+		"HS256": {
+			settings: map[string]string{"APP_BASE_URL": testBaseURL, "JWT_SECRET": "test-secret"},
+			expAlg:   AlgorithmHS256,
+		},
+		"RS256": {
+			settings: map[string]string{"APP_BASE_URL": testBaseURL, "JWT_ALGORITHM": AlgorithmRS256, "JWT_SIGNING_KEY_FILE": rsaPrivate},
+			expAlg:   AlgorithmRS256,
+			expKid:   true,
+			expJWKS:  1,
+		},
+		"EdDSA": {
+			settings: map[string]string{"APP_BASE_URL": testBaseURL, "JWT_ALGORITHM": AlgorithmEdDSA, "JWT_SIGNING_KEY_FILE": edPrivate, "JWT_SECRET": "test-secret"},
+			expAlg:   AlgorithmEdDSA,
+			expKid:   true,
+			expJWKS:  1,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			k := loadTestKeyring(t, tc.settings)
+
+			token, err := k.GenerateToken(Subject{UserID: 1001, Email: "test1@example.com", SessionID: "session"})
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			require.NoError(t, err)
+			require.Equal(t, tc.expAlg, parsed.Method.Alg())
+			_, hasKid := parsed.Header["kid"]
+			require.Equal(t, tc.expKid, hasKid)
+
+			claims, err := k.ParseToken(token)
+			require.NoError(t, err)
+			require.Equal(t, int64(1001), claims.UserID)
+			require.Equal(t, "session", claims.SessionID)
+
+			// The shared secret is never published
+			require.Len(t, k.JWKS().Keys, tc.expJWKS)
+		})
+	}
+}
+
+func TestKeyring_ParseToken_Rotation(t *testing.T) {
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	oldPrivate, oldPublic := writeKeyPair(t, oldKey)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newPrivate, _ := writeKeyPair(t, rsaKey)
+
+	hsToken, err := loadTestKeyring(t, map[string]string{"APP_BASE_URL": testBaseURL, "JWT_SECRET": "test-secret"}).
+		GenerateToken(Subject{UserID: 1001})
+	require.NoError(t, err)
+	oldToken, err := loadTestKeyring(t, map[string]string{"APP_BASE_URL": testBaseURL, "JWT_ALGORITHM": AlgorithmEdDSA, "JWT_SIGNING_KEY_FILE": oldPrivate}).
+		GenerateToken(Subject{UserID: 1001})
+	require.NoError(t, err)
+
+	type args struct {
+		settings map[string]string
+		token    string
+		expErr   bool
+	}
+	tcs := map[string]args{
+		"previous key kept for verification": {
+			settings: map[string]string{"APP_BASE_URL": testBaseURL, "JWT_ALGORITHM": AlgorithmRS256, "JWT_SIGNING_KEY_FILE": newPrivate, "JWT_VERIFICATION_KEY_FILES": oldPublic},
+			token:    oldToken,
+		},
+		"err - previous key dropped": {
+			settings: map[string]string{"APP_BASE_URL": testBaseURL, "JWT_ALGORITHM": AlgorithmRS256, "JWT_SIGNING_KEY_FILE": newPrivate},
+			token:    oldToken,
+			expErr:   true,
+		},
+		"shared secret kept after switching to RS256": {
+			settings: map[string]string{"APP_BASE_URL": testBaseURL, "JWT_ALGORITHM": AlgorithmRS256, "JWT_SIGNING_KEY_FILE": newPrivate, "JWT_SECRET": "test-secret"},
+			token:    hsToken,
+		},
+		"err - shared secret dropped": {
+			settings: map[string]string{"APP_BASE_URL": testBaseURL, "JWT_ALGORITHM": AlgorithmRS256, "JWT_SIGNING_KEY_FILE": newPrivate},
+			token:    hsToken,
+			expErr:   true,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			k := loadTestKeyring(t, tc.settings)
+			_, err := k.ParseToken(tc.token)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestKeyring_ParseToken_AlgorithmConfusion(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPrivate, rsaPublic := writeKeyPair(t, rsaKey)
+
+	k := loadTestKeyring(t, map[string]string{"APP_BASE_URL": testBaseURL, "JWT_ALGORITHM": AlgorithmRS256, "JWT_SIGNING_KEY_FILE": rsaPrivate})
+	publicPEM, err := os.ReadFile(rsaPublic)
+	require.NoError(t, err)
+
+	// An HS256 token "signed" with the published public key under the RSA kid must be rejected
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: 1001})
+	token.Header["kid"] = k.signing.id
+	forged, err := token.SignedString(publicPEM)
+	require.NoError(t, err)
+
+	_, err = k.ParseToken(forged)
+	require.Error(t, err)
+}
+
+func TestLoadKeyring_Errors(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaPrivate, _ := writeKeyPair(t, rsaKey)
+
+	tcs := map[string]map[string]string{
+		"missing secret":      {"APP_BASE_URL": testBaseURL},
+		"missing signing key": {"APP_BASE_URL": testBaseURL, "JWT_ALGORITHM": AlgorithmRS256},
+		"algorithm mismatch":  {"APP_BASE_URL": testBaseURL, "JWT_ALGORITHM": AlgorithmEdDSA, "JWT_SIGNING_KEY_FILE": rsaPrivate},
+		"unknown algorithm":   {"APP_BASE_URL": testBaseURL, "JWT_ALGORITHM": "none", "JWT_SECRET": "test-secret"},
+		// Without an issuer iss and aud could not be checked
+		"missing issuer": {"JWT_SECRET": "test-secret"},
+	}
+
+	for desc, settings := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			config.Init("test")
+			cfg := config.GetConfig()
+			for _, name := range keyringSettings {
+				cfg.Set(name, settings[name])
+			}
+
+			_, err := LoadKeyring()
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package jwt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring_MFAToken(t *testing.T) {
+	k := loadTestKeyring(t, map[string]string{"APP_BASE_URL": testBaseURL, "JWT_SECRET": "test-secret"})
+
+	mfaToken, err := k.GenerateMFAToken(1001)
+	require.NoError(t, err)
+	accessToken, err := k.GenerateToken(Subject{UserID: 1001})
+	require.NoError(t, err)
+
+	claims, err := k.ParseMFAToken(mfaToken)
+	require.NoError(t, err)
+	require.Equal(t, int64(1001), claims.UserID)
+	require.Equal(t, TokenUseMFA, claims.TokenUse)
+
+	// An mfa_pending token must never grant access, nor an access token skip the second factor
+	_, err = k.ParseToken(mfaToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = k.ParseMFAToken(accessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,10 @@
+package logger
+
+import "errors"
+
+var (
+	// ErrInvalidFormat means LOG_FORMAT is neither json nor console
+	ErrInvalidFormat = errors.New("invalid log format")
+	// ErrInvalidLevel means the log level is not one of debug, info, warn, error, dpanic, panic or fatal
+	ErrInvalidLevel = errors.New("invalid log level")
+)
@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// capture makes the default logger write JSON at debug level to the returned buffer for the rest of the test
+func capture(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	l, err := New(&buf, FormatJSON, zap.NewAtomicLevelAt(zapcore.DebugLevel))
+	require.NoError(t, err)
+
+	previous := L()
+	SetDefault(l)
+	t.Cleanup(func() { SetDefault(previous) })
+	return &buf
+}
+
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		out = append(out, entry)
+	}
+	return out
+}
+
+func TestNew(t *testing.T) {
+	type args struct {
+		format string
+		expErr error
+	}
+	tcs := map[string]args{
+		"success - json":    {format: FormatJSON},
+		"success - console": {format: FormatConsole},
+		"err - unknown":     {format: "logfmt", expErr: ErrInvalidFormat},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			var buf bytes.Buffer
+
+			// When
+			l, err := New(&buf, tc.format, zap.NewAtomicLevelAt(zapcore.InfoLevel))
+
+			// Then
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			l.Info("hello")
+			require.Contains(t, buf.String(), "hello")
+		})
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	type args struct {
+		given    string
+		expLevel zapcore.Level
+		expErr   error
+	}
+	tcs := map[string]args{
+		"success - debug":     {given: "debug", expLevel: zapcore.DebugLevel},
+		"success - uppercase": {given: "WARN", expLevel: zapcore.WarnLevel},
+		"err - unknown":       {given: "verbose", expLevel: zapcore.InfoLevel, expErr: ErrInvalidLevel},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			level.SetLevel(zapcore.InfoLevel)
+			t.Cleanup(func() { level.SetLevel(zapcore.InfoLevel) })
+
+			// When
+			err := SetLevel(tc.given)
+
+			// Then
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expLevel, Level().Level())
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	// Given
+	buf := capture(t)
+	ctx := NewContext(context.Background(), zap.String(FieldRequestID, "req-1"))
+	child := NewContext(ctx, zap.String("step", "child"))
+
+	// When
+	AddFields(ctx, zap.Int64(FieldUserID, 1001))
+	Info(ctx, "parent")
+	Error(child, "child", zap.String("reason", "test"))
+	AddFields(context.Background(), zap.Int64(FieldUserID, 1002))
+	Warn(context.Background(), "no scope")
+
+	// Then
+	logged := entries(t, buf)
+	require.Len(t, logged, 3)
+
+	require.Equal(t, "parent", logged[0]["message"])
+	require.Equal(t, "info", logged[0]["level"])
+	require.Equal(t, "req-1", logged[0][FieldRequestID])
+	require.Equal(t, float64(1001), logged[0][FieldUserID])
+	require.Contains(t, logged[0]["caller"], "logger_test.go")
+
+	// A child scope copies the fields the parent had when it was created
+	require.Equal(t, "child", logged[1]["message"])
+	require.Equal(t, "req-1", logged[1][FieldRequestID])
+	require.Equal(t, "child", logged[1]["step"])
+	require.Equal(t, "test", logged[1]["reason"])
+	require.NotContains(t, logged[1], FieldUserID)
+
+	require.Equal(t, "no scope", logged[2]["message"])
+	require.NotContains(t, logged[2], FieldUserID)
+}
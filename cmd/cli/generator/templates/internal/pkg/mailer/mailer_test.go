@@ -0,0 +1,85 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNew(t *testing.T) {
+	type args struct {
+		givenDriver string
+		givenEnv    string
+		expErr      bool
+	}
+	tcs := map[string]args{
+		"success - log by default in dev": {
+			givenEnv: "dev",
+		},
+		"success - log when asked in production": {
+			givenDriver: "log",
+			givenEnv:    "production",
+		},
+		"success - file": {
+			givenDriver: "file",
+			givenEnv:    "production",
+		},
+		"err - no driver in production": {
+			givenEnv: "production",
+			expErr:   true,
+		},
+		"err - unknown driver": {
+			givenDriver: "carrier-pigeon",
+			givenEnv:    "dev",
+			expErr:      true,
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			config.Init("test")
+			config.GetConfig().Set("MAILER_DRIVER", tc.givenDriver)
+			config.GetConfig().Set("APP_ENV", tc.givenEnv)
+
+			// When
+			m, err := New()
+
+			// Then
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, m)
+		})
+	}
+}
+
+func TestLogMailer_Send(t *testing.T) {
+	// Given
+	var buf bytes.Buffer
+	l, err := logger.New(&buf, logger.FormatJSON, zap.NewAtomicLevelAt(zapcore.DebugLevel))
+	require.NoError(t, err)
+	previous := logger.L()
+	logger.SetDefault(l)
+	t.Cleanup(func() { logger.SetDefault(previous) })
+
+	// When
+	err = NewLogMailer().Send(context.Background(), Message{
+		To:      "test1@example.com",
+		Subject: "Reset your password",
+		Body:    "https://example.com/reset?token=secret-token",
+	})
+
+	// Then
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "test1@example.com")
+	require.Contains(t, buf.String(), "Reset your password")
+	require.NotContains(t, buf.String(), "secret-token")
+}
@@ -0,0 +1,10 @@
+package oauth
+
+import "errors"
+
+var (
+	ErrProviderNotFound = errors.New("oauth provider not found")
+	ErrUserInfoFailed   = errors.New("failed to get user info from provider")
+	ErrInvalidState     = errors.New("invalid oauth state")
+	ErrInvalidIDToken   = errors.New("invalid id token")
+)
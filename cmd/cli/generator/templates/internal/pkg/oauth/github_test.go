@@ -0,0 +1,50 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestGitHubProvider_UserInfo(t *testing.T) {
+	type args struct {
+		emails string
+		expRs  UserInfo
+	}
+	tcs := map[string]args{
+		"success": {
+			emails: `[{"email":"other@example.com","primary":false,"verified":true},{"email":"test1@example.com","primary":true,"verified":true}]`,
+			expRs:  UserInfo{ID: "42", Email: "test1@example.com", EmailVerified: true, Name: "Test User", Image: "https://avatars.example.com/42"},
+		},
+		"unverified_primary_email": {
+			emails: `[{"email":"test1@example.com","primary":true,"verified":false}]`,
+			expRs:  UserInfo{ID: "42", Email: "test1@example.com", Name: "Test User", Image: "https://avatars.example.com/42"},
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+				_, _ = w.Write([]byte(`{"id":42,"login":"test1","name":"Test User","avatar_url":"https://avatars.example.com/42"}`))
+			})
+			mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tc.emails))
+			})
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			p := NewGitHubProvider(Config{ClientID: "client"}).(*githubProvider)
+			p.apiURL = srv.URL
+
+			rs, err := p.UserInfo(context.Background(), &oauth2.Token{AccessToken: "access-token", TokenType: "Bearer"}, "")
+			require.NoError(t, err)
+			require.Equal(t, tc.expRs, rs)
+		})
+	}
+}
@@ -1,24 +1,17 @@
 package oauth
 
 import (
-	"github.com/namf2001/go-backend-template/config"
-	"golang.org/x/oauth2"
+	"github.com/namf2001/go-backend-template/internal/model"
 	"golang.org/x/oauth2/google"
 )
 
-var (
-	GoogleOauthConfig *oauth2.Config
-	OauthStateString  = "random-string"
-	Scopes            = []string{"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/userinfo.profile"}
+const (
+	googleIssuer      = "https://accounts.google.com"
+	googleJWKSURL     = "https://www.googleapis.com/oauth2/v3/certs"
+	googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
 )
 
-func Init() {
-	cfg := config.GetConfig()
-	GoogleOauthConfig = &oauth2.Config{
-		RedirectURL:  cfg.GetString("GOOGLE_REDIRECT_URL"),
-		ClientID:     cfg.GetString("GOOGLE_CLIENT_ID"),
-		ClientSecret: cfg.GetString("GOOGLE_CLIENT_SECRET"),
-		Scopes:       Scopes,
-		Endpoint:     google.Endpoint,
-	}
+// NewGoogleProvider returns the Google provider
+func NewGoogleProvider(cfg Config) Provider {
+	return newOIDCProvider(model.ProviderGoogle, cfg, google.Endpoint, googleIssuer, googleJWKSURL, googleUserInfoURL, true)
 }
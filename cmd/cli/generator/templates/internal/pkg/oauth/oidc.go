@@ -0,0 +1,194 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+var oidcScopes = []string{"openid", "email", "profile"}
+
+// oidcProvider is an OpenID Connect provider that reads the identity from the verified ID token
+type oidcProvider struct {
+	name            model.Provider
+	config          *oauth2.Config
+	verifier        *idTokenVerifier
+	userInfoURL     string // Used only when the ID token has no email claim
+	trustEmailClaim bool   // Whether email_verified reported by the provider can be trusted
+}
+
+// discoveryDocument is the subset of the OpenID Provider Metadata that is used
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcUserInfo holds the standard claims returned by a userinfo endpoint
+type oidcUserInfo struct {
+	Sub           string       `json:"sub"`
+	Email         string       `json:"email"`
+	EmailVerified flexibleBool `json:"email_verified"`
+	Name          string       `json:"name"`
+	Picture       string       `json:"picture"`
+}
+
+// newOIDCProvider returns an OpenID Connect provider with the given endpoints
+func newOIDCProvider(name model.Provider, cfg Config, endpoint oauth2.Endpoint, issuer, jwksURL, userInfoURL string, trustEmailClaim bool) *oidcProvider {
+	return &oidcProvider{
+		name:   name,
+		config: cfg.oauth2Config(endpoint, oidcScopes),
+		verifier: &idTokenVerifier{
+			issuer:   issuer,
+			clientID: cfg.ClientID,
+			keys:     NewKeySet(jwksURL),
+			now:      time.Now,
+		},
+		userInfoURL:     userInfoURL,
+		trustEmailClaim: trustEmailClaim,
+	}
+}
+
+// NewOIDCProvider returns a generic OpenID Connect provider whose endpoints are read from
+// the issuer's discovery document
+func NewOIDCProvider(ctx context.Context, issuer string, cfg Config) (Provider, error) {
+	doc, err := discover(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	return newOIDCProvider(model.ProviderOIDC, cfg, oauth2.Endpoint{
+		AuthURL:  doc.AuthorizationEndpoint,
+		TokenURL: doc.TokenEndpoint,
+	}, doc.Issuer, doc.JWKSURI, doc.UserInfoEndpoint, true), nil
+}
+
+func discover(ctx context.Context, issuer string) (discoveryDocument, error) {
+	issuer = strings.TrimRight(issuer, "/")
+	url := issuer + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return discoveryDocument{}, pkgerrors.WithStack(err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return discoveryDocument{}, pkgerrors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return discoveryDocument{}, pkgerrors.WithStack(fmt.Errorf("oidc discovery %s: unexpected status %d", url, resp.StatusCode))
+	}
+
+	var doc discoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return discoveryDocument{}, pkgerrors.WithStack(err)
+	}
+
+	// The issuer must match exactly, otherwise ID tokens from another issuer could be accepted
+	if strings.TrimRight(doc.Issuer, "/") != issuer {
+		return discoveryDocument{}, pkgerrors.WithStack(fmt.Errorf("oidc discovery %s: issuer %q does not match", url, doc.Issuer))
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return discoveryDocument{}, pkgerrors.WithStack(fmt.Errorf("oidc discovery %s: missing endpoints", url))
+	}
+
+	return doc, nil
+}
+
+// Name implements Provider.
+func (p *oidcProvider) Name() model.Provider {
+	return p.name
+}
+
+// AuthCodeURL implements Provider.
+func (p *oidcProvider) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string {
+	return p.config.AuthCodeURL(state, opts...)
+}
+
+// Exchange implements Provider.
+func (p *oidcProvider) Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	token, err := p.config.Exchange(ctx, code, opts...)
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+	return token, nil
+}
+
+// UserInfo implements Provider.
+func (p *oidcProvider) UserInfo(ctx context.Context, token *oauth2.Token, nonce string) (UserInfo, error) {
+	raw, ok := token.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return UserInfo{}, pkgerrors.WithStack(fmt.Errorf("%w: missing id_token", ErrInvalidIDToken))
+	}
+
+	claims, err := p.verifier.verify(ctx, raw, nonce)
+	if err != nil {
+		return UserInfo{}, err
+	}
+
+	info := UserInfo{
+		ID:            claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Image:         claims.Picture,
+	}
+
+	// Some providers only return the email from the userinfo endpoint
+	if info.Email == "" && p.userInfoURL != "" {
+		var extra oidcUserInfo
+		if err := getJSON(ctx, p.config.Client(ctx, token), p.userInfoURL, &extra); err != nil {
+			return UserInfo{}, err
+		}
+		if extra.Sub != info.ID {
+			return UserInfo{}, pkgerrors.WithStack(fmt.Errorf("%w: userinfo subject mismatch", ErrUserInfoFailed))
+		}
+		info.Email, info.EmailVerified = extra.Email, bool(extra.EmailVerified)
+		if info.Name == "" {
+			info.Name = extra.Name
+		}
+		if info.Image == "" {
+			info.Image = extra.Picture
+		}
+	}
+
+	info.EmailVerified = info.EmailVerified && p.trustEmailClaim
+	return info, nil
+}
+
+// getJSON performs an authenticated GET request and decodes the JSON response into result
+func getJSON(ctx context.Context, client *http.Client, url string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return pkgerrors.WithStack(fmt.Errorf("%w: %v", ErrUserInfoFailed, err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return pkgerrors.WithStack(fmt.Errorf("%w: %s returned status %d", ErrUserInfoFailed, url, resp.StatusCode))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return pkgerrors.WithStack(fmt.Errorf("%w: %v", ErrUserInfoFailed, err))
+	}
+
+	return nil
+}
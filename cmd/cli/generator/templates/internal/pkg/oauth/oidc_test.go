@@ -0,0 +1,152 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCProvider_UserInfo(t *testing.T) {
+	type args struct {
+		claims   jwt.MapClaims
+		userInfo string
+		nonce    string
+		expRs    UserInfo
+		expErr   error
+	}
+	tcs := map[string]args{
+		"success": {
+			nonce: "the-nonce",
+			expRs: UserInfo{ID: "abc", Email: "test1@example.com", EmailVerified: true, Name: "Test User"},
+		},
+		"email_verified_as_string": {
+			claims: jwt.MapClaims{"email_verified": "true"},
+			nonce:  "the-nonce",
+			expRs:  UserInfo{ID: "abc", Email: "test1@example.com", EmailVerified: true, Name: "Test User"},
+		},
+		"email_from_userinfo": {
+			claims:   jwt.MapClaims{"email": nil, "email_verified": nil},
+			userInfo: `{"sub":"abc","email":"test2@example.com","email_verified":false,"picture":"https://example.com/a.png"}`,
+			nonce:    "the-nonce",
+			expRs:    UserInfo{ID: "abc", Email: "test2@example.com", Name: "Test User", Image: "https://example.com/a.png"},
+		},
+		"userinfo_subject_mismatch": {
+			claims:   jwt.MapClaims{"email": nil},
+			userInfo: `{"sub":"other","email":"test2@example.com"}`,
+			nonce:    "the-nonce",
+			expErr:   ErrUserInfoFailed,
+		},
+		"nonce_mismatch": {
+			nonce:  "other-nonce",
+			expErr: ErrInvalidIDToken,
+		},
+		"wrong_audience": {
+			claims: jwt.MapClaims{"aud": "other-client"},
+			nonce:  "the-nonce",
+			expErr: ErrInvalidIDToken,
+		},
+		"wrong_issuer": {
+			claims: jwt.MapClaims{"iss": "https://evil.example.com"},
+			nonce:  "the-nonce",
+			expErr: ErrInvalidIDToken,
+		},
+		"expired": {
+			claims: jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()},
+			nonce:  "the-nonce",
+			expErr: ErrInvalidIDToken,
+		},
+		"missing_expiry": {
+			claims: jwt.MapClaims{"exp": nil},
+			nonce:  "the-nonce",
+			expErr: ErrInvalidIDToken,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			ctx := context.Background()
+			iss := newTestIssuer(t)
+			iss.idToken = iss.sign(tc.claims)
+			iss.userInfo = tc.userInfo
+
+			p, err := NewOIDCProvider(ctx, iss.URL, Config{ClientID: "client"})
+			require.NoError(t, err)
+
+			token, err := p.Exchange(ctx, "the-code")
+			require.NoError(t, err)
+
+			rs, err := p.UserInfo(ctx, token, tc.nonce)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expRs, rs)
+		})
+	}
+}
+
+func TestOIDCProvider_UserInfo_MissingIDToken(t *testing.T) {
+	ctx := context.Background()
+	iss := newTestIssuer(t)
+
+	p, err := NewOIDCProvider(ctx, iss.URL, Config{ClientID: "client"})
+	require.NoError(t, err)
+
+	token, err := p.Exchange(ctx, "the-code")
+	require.NoError(t, err)
+
+	_, err = p.UserInfo(ctx, token, "the-nonce")
+	require.ErrorIs(t, err, ErrInvalidIDToken)
+}
+
+func TestNewOIDCProvider(t *testing.T) {
+	iss := newTestIssuer(t)
+
+	p, err := NewOIDCProvider(context.Background(), iss.URL+"/", Config{ClientID: "client", RedirectURL: "http://localhost/callback"})
+	require.NoError(t, err)
+	require.Equal(t, model.ProviderOIDC, p.Name())
+
+	authURL, err := url.Parse(p.AuthCodeURL("state"))
+	require.NoError(t, err)
+	require.Equal(t, "/authorize", authURL.Path)
+	require.Equal(t, "state", authURL.Query().Get("state"))
+	require.Equal(t, "openid email profile", authURL.Query().Get("scope"))
+}
+
+func TestNewOIDCProvider_DiscoveryFailed(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := NewOIDCProvider(context.Background(), srv.URL, Config{ClientID: "client"})
+	require.Error(t, err)
+}
+
+func TestNewOIDCProvider_IssuerMismatch(t *testing.T) {
+	iss := newTestIssuer(t)
+	proxy := httptest.NewServer(iss.Config.Handler)
+	defer proxy.Close()
+
+	// The discovery document served by proxy advertises iss.URL as issuer
+	_, err := NewOIDCProvider(context.Background(), proxy.URL, Config{ClientID: "client"})
+	require.Error(t, err)
+}
+
+func TestRegistry_Get(t *testing.T) {
+	r := NewRegistry(NewGoogleProvider(Config{ClientID: "g"}), NewGitHubProvider(Config{ClientID: "gh"}))
+
+	p, err := r.Get(model.ProviderGitHub)
+	require.NoError(t, err)
+	require.Equal(t, model.ProviderGitHub, p.Name())
+	require.Equal(t, []model.Provider{model.ProviderGitHub, model.ProviderGoogle}, r.Names())
+
+	_, err = r.Get(model.ProviderMicrosoft)
+	require.ErrorIs(t, err, ErrProviderNotFound)
+}
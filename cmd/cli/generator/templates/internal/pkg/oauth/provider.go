@@ -0,0 +1,55 @@
+package oauth
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"golang.org/x/oauth2"
+)
+
+// UserInfo is the identity of a user as reported by a provider, normalized across providers
+type UserInfo struct {
+	ID            string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Image         string
+}
+
+// Provider is an OAuth 2.0 / OpenID Connect identity provider
+type Provider interface {
+	// Name returns the provider name stored on linked accounts
+	Name() model.Provider
+
+	// AuthCodeURL returns the URL of the provider's consent page
+	AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string
+
+	// Exchange converts an authorization code into a token
+	Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error)
+
+	// UserInfo returns the identity of the user the token was issued for. OpenID Connect providers
+	// read it from the verified ID token, which must carry the nonce sent in the authorization request.
+	UserInfo(ctx context.Context, token *oauth2.Token, nonce string) (UserInfo, error)
+}
+
+// Config holds the client settings registered with a provider
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+func (c Config) oauth2Config(endpoint oauth2.Endpoint, defaultScopes []string) *oauth2.Config {
+	scopes := c.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+	return &oauth2.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		RedirectURL:  c.RedirectURL,
+		Scopes:       scopes,
+		Endpoint:     endpoint,
+	}
+}
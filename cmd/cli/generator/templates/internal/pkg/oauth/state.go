@@ -0,0 +1,149 @@
+package oauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	pkgerrors "github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+const (
+	stateCookieName = "oauth_state"
+	stateCookiePath = "/api/v1/auth"
+	defaultStateTTL = 10 * time.Minute
+)
+
+// AuthRequest holds the per-login secrets that must survive the round trip to the provider
+type AuthRequest struct {
+	Provider     model.Provider `json:"p"`
+	State        string         `json:"s"`
+	CodeVerifier string         `json:"v"`
+	Nonce        string         `json:"n"`
+	ExpiresAt    int64          `json:"e"`
+	// LinkUserID and LinkSessionID are set when a signed in user links the provider to their account
+	LinkUserID    int64  `json:"u,omitempty"`
+	LinkSessionID string `json:"sid,omitempty"`
+}
+
+// StateCookie binds an AuthRequest to the browser with a short-lived HMAC-signed cookie
+type StateCookie struct {
+	secret []byte
+	secure bool
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewStateCookie returns a StateCookie signing with secret. secure sets the cookie Secure flag.
+func NewStateCookie(secret []byte, secure bool) *StateCookie {
+	return &StateCookie{
+		secret: secret,
+		secure: secure,
+		ttl:    defaultStateTTL,
+		now:    time.Now,
+	}
+}
+
+// NewStateCookieFromConfig returns a StateCookie signing with OAUTH_STATE_SECRET, falling back
+// to JWT_SECRET. The cookie is marked Secure when APP_BASE_URL is served over https.
+func NewStateCookieFromConfig() (*StateCookie, error) {
+	cfg := config.GetConfig()
+	secret := cfg.GetString("OAUTH_STATE_SECRET")
+	if secret == "" {
+		secret = cfg.GetString("JWT_SECRET")
+	}
+	if secret == "" {
+		return nil, pkgerrors.New("OAUTH_STATE_SECRET or JWT_SECRET is required")
+	}
+
+	return NewStateCookie([]byte(secret), strings.HasPrefix(cfg.GetString("APP_BASE_URL"), "https://")), nil
+}
+
+// Issue generates a fresh state, PKCE verifier and nonce for req and stores them in the cookie
+func (s *StateCookie) Issue(w http.ResponseWriter, req AuthRequest) (AuthRequest, error) {
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return AuthRequest{}, err
+	}
+
+	nonce, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return AuthRequest{}, err
+	}
+
+	req.State = state
+	req.CodeVerifier = oauth2.GenerateVerifier()
+	req.Nonce = nonce
+	req.ExpiresAt = s.now().Add(s.ttl).Unix()
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return AuthRequest{}, pkgerrors.WithStack(err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	http.SetCookie(w, s.cookie(encoded+"."+s.sign(encoded), int(s.ttl.Seconds())))
+
+	return req, nil
+}
+
+// Verify reads the cookie and checks it was issued for the provider and the state returned by it.
+// The cookie is cleared in any case so it cannot be replayed.
+func (s *StateCookie) Verify(w http.ResponseWriter, r *http.Request, provider model.Provider, state string) (AuthRequest, error) {
+	c, err := r.Cookie(stateCookieName)
+	if err != nil {
+		return AuthRequest{}, pkgerrors.WithStack(ErrInvalidState)
+	}
+	http.SetCookie(w, s.cookie("", -1))
+
+	encoded, sig, ok := strings.Cut(c.Value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.sign(encoded))) {
+		return AuthRequest{}, pkgerrors.WithStack(ErrInvalidState)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return AuthRequest{}, pkgerrors.WithStack(ErrInvalidState)
+	}
+
+	var req AuthRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return AuthRequest{}, pkgerrors.WithStack(ErrInvalidState)
+	}
+
+	if req.Provider != provider ||
+		s.now().Unix() > req.ExpiresAt ||
+		subtle.ConstantTimeCompare([]byte(req.State), []byte(state)) != 1 {
+		return AuthRequest{}, pkgerrors.WithStack(ErrInvalidState)
+	}
+
+	return req, nil
+}
+
+func (s *StateCookie) sign(value string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *StateCookie) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     stateCookieName,
+		Value:    value,
+		Path:     stateCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   s.secure,
+		// Lax so the cookie is sent on the top-level redirect back from the provider
+		SameSite: http.SameSiteLaxMode,
+	}
+}
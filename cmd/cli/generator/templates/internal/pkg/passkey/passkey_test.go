@@ -0,0 +1,134 @@
+package passkey
+
+import (
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/passkey/passkeytest"
+	"github.com/stretchr/testify/require"
+)
+
+const testOrigin = "https://app.example.com"
+
+func newTestRelyingParty(t *testing.T) *webauthn.WebAuthn {
+	t.Helper()
+	w, err := New(Config{RPID: "example.com", RPName: "Example", RPOrigins: []string{testOrigin}})
+	require.NoError(t, err)
+	return w
+}
+
+// register runs a registration ceremony and returns the passkey as it would be stored
+func register(t *testing.T, w *webauthn.WebAuthn, user User, authenticator *passkeytest.Authenticator) model.WebAuthnCredential {
+	t.Helper()
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	body, err := authenticator.Register(creation)
+	require.NoError(t, err)
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(body)
+	require.NoError(t, err)
+
+	credential, err := w.CreateCredential(user, *session, parsed)
+	require.NoError(t, err)
+	return FromCredential(*credential)
+}
+
+// login runs a discoverable login ceremony against the stored passkey
+func login(t *testing.T, w *webauthn.WebAuthn, user User, authenticator *passkeytest.Authenticator) (*webauthn.Credential, error) {
+	t.Helper()
+	assertion, session, err := w.BeginDiscoverableLogin()
+	require.NoError(t, err)
+
+	body, err := authenticator.Login(assertion)
+	require.NoError(t, err)
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(body)
+	require.NoError(t, err)
+
+	return w.ValidateDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, ok := UserIDFromHandle(userHandle)
+		require.True(t, ok)
+		require.Equal(t, user.User.ID, userID)
+		return user, nil
+	}, *session, parsed)
+}
+
+func TestCeremonies(t *testing.T) {
+	type args struct {
+		fixedCounter bool
+		synced       bool
+		useClone     bool // Log in from a copy of the authenticator that fell behind
+		expClone     bool
+		expErr       bool
+	}
+	tcs := map[string]args{
+		"success": {},
+		"success - synced passkey": {
+			synced: true,
+		},
+		"success - authenticator without counter": {
+			fixedCounter: true,
+		},
+		"cloned authenticator": {
+			useClone: true,
+			expClone: true,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			w := newTestRelyingParty(t)
+			authenticator := passkeytest.New(testOrigin)
+			authenticator.FixedCounter = tc.fixedCounter
+			authenticator.Synced = tc.synced
+
+			user := User{User: model.User{ID: 42, Email: "passkey@example.com"}}
+			stored := register(t, w, user, authenticator)
+			require.Equal(t, authenticator.CredentialID(), stored.CredentialID)
+			require.Equal(t, tc.synced, stored.BackupEligible)
+			require.Equal(t, []string{"internal", "hybrid"}, stored.Transports)
+			user.Credentials = []model.WebAuthnCredential{stored}
+
+			clone := authenticator.Clone()
+			for j := 0; j < 2; j++ {
+				credential, err := login(t, w, user, authenticator)
+				require.NoError(t, err)
+				require.False(t, credential.Authenticator.CloneWarning)
+				user.Credentials[0].SignCount = credential.Authenticator.SignCount
+			}
+
+			if tc.useClone {
+				credential, err := login(t, w, user, clone)
+				require.NoError(t, err)
+				require.Equal(t, tc.expClone, credential.Authenticator.CloneWarning)
+			}
+		})
+	}
+}
+
+func TestCeremonies_WrongOrigin(t *testing.T) {
+	w := newTestRelyingParty(t)
+	user := User{User: model.User{ID: 42, Email: "passkey@example.com"}}
+
+	creation, session, err := w.BeginRegistration(user)
+	require.NoError(t, err)
+
+	// A phishing site relaying the ceremony cannot make the browser report our origin
+	body, err := passkeytest.New("https://app.example.com.evil.test").Register(creation)
+	require.NoError(t, err)
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(body)
+	require.NoError(t, err)
+
+	_, err = w.CreateCredential(user, *session, parsed)
+	require.Error(t, err)
+}
+
+func TestUserHandle(t *testing.T) {
+	userID, ok := UserIDFromHandle(UserHandle(1001))
+	require.True(t, ok)
+	require.Equal(t, int64(1001), userID)
+
+	_, ok = UserIDFromHandle([]byte("not-a-handle"))
+	require.False(t, ok)
+}
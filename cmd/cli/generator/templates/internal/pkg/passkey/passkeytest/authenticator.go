@@ -0,0 +1,199 @@
+// Package passkeytest provides a software authenticator to run WebAuthn ceremonies in tests
+package passkeytest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+// Authenticator flags, see https://www.w3.org/TR/webauthn-3/#authdata-flags
+const (
+	flagUserPresent    = 0x01
+	flagUserVerified   = 0x04
+	flagBackupEligible = 0x08
+	flagBackupState    = 0x10
+	flagAttestedData   = 0x40
+)
+
+// Authenticator is a software passkey authenticator holding a single P-256 credential.
+// It produces the JSON a browser would send for navigator.credentials.create() and get().
+type Authenticator struct {
+	Origin       string
+	SignCount    uint32 // Incremented before each assertion unless FixedCounter is set
+	FixedCounter bool   // Behave like authenticators without a counter, which always report 0
+	Synced       bool   // Report the credential as backed up, like passkeys synced by a platform
+	NoUV         bool   // Only test user presence, like security keys without a PIN or biometrics
+
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+}
+
+// New returns an authenticator used from the given origin
+func New(origin string) *Authenticator {
+	return &Authenticator{Origin: origin}
+}
+
+// CredentialID returns the id of the credential once registered
+func (a *Authenticator) CredentialID() []byte {
+	return a.credentialID
+}
+
+// Clone returns an authenticator holding a copy of the same credential, as an attacker who
+// extracted the key would. Its counter evolves independently.
+func (a *Authenticator) Clone() *Authenticator {
+	clone := *a
+	return &clone
+}
+
+// Register creates a credential for the creation options and returns the attestation response
+func (a *Authenticator) Register(creation *protocol.CredentialCreation) ([]byte, error) {
+	opts := creation.Response
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	credentialID := make([]byte, 16)
+	if _, err := rand.Read(credentialID); err != nil {
+		return nil, err
+	}
+
+	userHandle, err := userID(opts.User.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  1, // P-256
+		XCoord: key.PublicKey.X.FillBytes(make([]byte, 32)),
+		YCoord: key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	authData := a.authData(opts.RelyingParty.ID, flagAttestedData, 0)
+	authData = append(authData, make([]byte, 16)...) // AAGUID, zero for no attestation
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(credentialID)))
+	authData = append(authData, credentialID...)
+	authData = append(authData, publicKey...)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataJSON, err := a.clientData("webauthn.create", opts.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	a.key, a.credentialID, a.userHandle = key, credentialID, userHandle
+	return json.Marshal(map[string]any{
+		"id":    b64(credentialID),
+		"rawId": b64(credentialID),
+		"type":  "public-key",
+		"response": map[string]any{
+			"clientDataJSON":    b64(clientDataJSON),
+			"attestationObject": b64(attestationObject),
+			"transports":        []string{"internal", "hybrid"},
+		},
+		"clientExtensionResults": map[string]any{},
+	})
+}
+
+// Login signs the assertion options with the registered credential and returns the assertion response
+func (a *Authenticator) Login(assertion *protocol.CredentialAssertion) ([]byte, error) {
+	if a.key == nil {
+		return nil, fmt.Errorf("no credential registered")
+	}
+	opts := assertion.Response
+
+	if !a.FixedCounter {
+		a.SignCount++
+	}
+	authData := a.authData(opts.RelyingPartyID, 0, a.SignCount)
+
+	clientDataJSON, err := a.clientData("webauthn.get", opts.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(authData, clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(map[string]any{
+		"id":    b64(a.credentialID),
+		"rawId": b64(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]any{
+			"clientDataJSON":    b64(clientDataJSON),
+			"authenticatorData": b64(authData),
+			"signature":         b64(signature),
+			"userHandle":        b64(a.userHandle),
+		},
+		"clientExtensionResults": map[string]any{},
+	})
+}
+
+func (a *Authenticator) authData(rpID string, flags byte, signCount uint32) []byte {
+	flags |= flagUserPresent
+	if !a.NoUV {
+		flags |= flagUserVerified
+	}
+	if a.Synced {
+		flags |= flagBackupEligible | flagBackupState
+	}
+
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	authData := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(authData, signCount)
+}
+
+func (a *Authenticator) clientData(ceremony string, challenge protocol.URLEncodedBase64) ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"type":        ceremony,
+		"challenge":   challenge.String(),
+		"origin":      a.Origin,
+		"crossOrigin": false,
+	})
+}
+
+func userID(id any) ([]byte, error) {
+	switch v := id.(type) {
+	case protocol.URLEncodedBase64:
+		return v, nil
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("unexpected user id type %T", id)
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,97 @@
+package passkey
+
+import (
+	"encoding/binary"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/namf2001/go-backend-template/internal/model"
+)
+
+// User adapts a user and their passkeys to webauthn.User
+type User struct {
+	User        model.User
+	Credentials []model.WebAuthnCredential
+}
+
+// WebAuthnID implements webauthn.User. The user handle is the user ID, it carries no personal data.
+func (u User) WebAuthnID() []byte {
+	return UserHandle(u.User.ID)
+}
+
+// WebAuthnName implements webauthn.User.
+func (u User) WebAuthnName() string {
+	return u.User.Email
+}
+
+// WebAuthnDisplayName implements webauthn.User.
+func (u User) WebAuthnDisplayName() string {
+	if u.User.Name != "" {
+		return u.User.Name
+	}
+	return u.User.Email
+}
+
+// WebAuthnCredentials implements webauthn.User.
+func (u User) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.Credentials))
+	for _, c := range u.Credentials {
+		credentials = append(credentials, ToCredential(c))
+	}
+	return credentials
+}
+
+// UserHandle returns the user handle of a user
+func UserHandle(userID int64) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(userID))
+}
+
+// UserIDFromHandle returns the user ID a user handle was built from
+func UserIDFromHandle(handle []byte) (int64, bool) {
+	if len(handle) != 8 {
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint64(handle)), true
+}
+
+// ToCredential converts a stored passkey to a webauthn.Credential
+func ToCredential(c model.WebAuthnCredential) webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, 0, len(c.Transports))
+	for _, t := range c.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(t))
+	}
+
+	return webauthn.Credential{
+		ID:              c.CredentialID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: c.BackupEligible,
+			BackupState:    c.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    c.AAGUID,
+			SignCount: c.SignCount,
+		},
+	}
+}
+
+// FromCredential converts a webauthn.Credential to a passkey to store
+func FromCredential(c webauthn.Credential) model.WebAuthnCredential {
+	transports := make([]string, 0, len(c.Transport))
+	for _, t := range c.Transport {
+		transports = append(transports, string(t))
+	}
+
+	return model.WebAuthnCredential{
+		CredentialID:    c.ID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		AAGUID:          c.Authenticator.AAGUID,
+		SignCount:       c.Authenticator.SignCount,
+		Transports:      transports,
+		BackupEligible:  c.Flags.BackupEligible,
+		BackupState:     c.Flags.BackupState,
+	}
+}
@@ -0,0 +1,121 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+// Defaults of Argon2id, the second recommended option of RFC 9106 section 4
+const (
+	defaultArgon2Memory      = 64 * 1024
+	defaultArgon2Iterations  = 3
+	defaultArgon2Parallelism = 4
+	argon2SaltLength         = 16
+	argon2KeyLength          = 32
+)
+
+var argon2Encoding = base64.RawStdEncoding
+
+// Argon2id hashes passwords with Argon2id, into the PHC string format
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+type Argon2id struct {
+	Memory      uint32 // KiB, defaults to 64 MiB
+	Iterations  uint32 // Defaults to 3
+	Parallelism uint8  // Defaults to 4
+}
+
+// argon2Params are the parameters of a hash
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+func (a Argon2id) params() argon2Params {
+	p := argon2Params{memory: a.Memory, iterations: a.Iterations, parallelism: a.Parallelism}
+	if p.memory == 0 {
+		p.memory = defaultArgon2Memory
+	}
+	if p.iterations == 0 {
+		p.iterations = defaultArgon2Iterations
+	}
+	if p.parallelism == 0 {
+		p.parallelism = defaultArgon2Parallelism
+	}
+	return p
+}
+
+// Hash implements Hasher.
+func (a Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", pkgerrors.WithStack(err)
+	}
+
+	p := a.params()
+	key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.iterations, p.parallelism,
+		argon2Encoding.EncodeToString(salt), argon2Encoding.EncodeToString(key)), nil
+}
+
+// Verify implements Hasher. The parameters are read from the hash.
+func (a Argon2id) Verify(hash, password string) error {
+	p, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return pkgerrors.WithStack(ErrMismatch)
+	}
+	return nil
+}
+
+// NeedsRehash implements Hasher.
+func (a Argon2id) NeedsRehash(hash string) bool {
+	p, _, key, err := decodeArgon2id(hash)
+	return err != nil || p != a.params() || len(key) != argon2KeyLength
+}
+
+func (Argon2id) identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// decodeArgon2id parses a hash returned by Argon2id.Hash
+func decodeArgon2id(hash string) (argon2Params, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return argon2Params{}, nil, nil, pkgerrors.WithStack(ErrUnsupportedHash)
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Params{}, nil, nil, pkgerrors.WithStack(ErrUnsupportedHash)
+	}
+
+	var p argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil ||
+		p.memory == 0 || p.iterations == 0 || p.parallelism == 0 {
+		return argon2Params{}, nil, nil, pkgerrors.WithStack(ErrUnsupportedHash)
+	}
+
+	salt, err := argon2Encoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Params{}, nil, nil, pkgerrors.WithStack(ErrUnsupportedHash)
+	}
+	key, err := argon2Encoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return argon2Params{}, nil, nil, pkgerrors.WithStack(ErrUnsupportedHash)
+	}
+
+	return p, salt, key, nil
+}
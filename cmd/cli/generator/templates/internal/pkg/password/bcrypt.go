@@ -0,0 +1,61 @@
+package password
+
+import (
+	"errors"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultBcryptCost = 10
+	// bcryptMaxBytes is the longest password bcrypt hashes
+	bcryptMaxBytes = 72
+)
+
+// Bcrypt hashes passwords with bcrypt, into the $2a$<cost>$... format
+type Bcrypt struct {
+	Cost int // Defaults to 10
+}
+
+func (b Bcrypt) cost() int {
+	if b.Cost == 0 {
+		return defaultBcryptCost
+	}
+	return b.Cost
+}
+
+// Hash implements Hasher.
+func (b Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost())
+	if err != nil {
+		return "", pkgerrors.WithStack(err)
+	}
+	return string(hash), nil
+}
+
+// Verify implements Hasher. The cost is read from the hash.
+func (b Bcrypt) Verify(hash, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return pkgerrors.WithStack(ErrMismatch)
+	}
+	if err != nil {
+		return pkgerrors.WithStack(ErrUnsupportedHash)
+	}
+	return nil
+}
+
+// NeedsRehash implements Hasher.
+func (b Bcrypt) NeedsRehash(hash string) bool {
+	if !b.identifies(hash) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != b.cost()
+}
+
+func (Bcrypt) identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
@@ -0,0 +1,195 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/namf2001/go-backend-template/config"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Rules of the password policy, reported in Violation.Rule
+const (
+	RuleMinLength    = "min_length"
+	RuleMaxLength    = "max_length"
+	RuleUppercase    = "uppercase"
+	RuleLowercase    = "lowercase"
+	RuleDigit        = "digit"
+	RuleSymbol       = "symbol"
+	RulePersonalInfo = "personal_info"
+	RuleBreached     = "breached"
+)
+
+const (
+	defaultMinLength = 8
+	defaultMaxLength = 128
+	// minPersonalInfoLength is the shortest part of an email or name looked for in passwords,
+	// shorter ones such as initials would reject too many passwords
+	minPersonalInfoLength = 3
+)
+
+// Policy is the set of rules new passwords must follow
+type Policy struct {
+	MinLength            int // In characters, defaults to 8
+	MaxLength            int // In characters, defaults to 128
+	MaxBytes             int // In bytes once UTF-8 encoded, not checked when 0. Bcrypt refuses passwords over 72 bytes.
+	RequireUppercase     bool
+	RequireLowercase     bool
+	RequireDigit         bool
+	RequireSymbol        bool
+	DisallowPersonalInfo bool            // Rejects passwords containing the user's email or name
+	Breached             BreachedChecker // Rejects known breached passwords, not checked when nil
+}
+
+// Violation is a rule of the policy a password breaks
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// PolicyError lists every rule of the policy a password breaks
+type PolicyError struct {
+	Violations []Violation
+}
+
+// Error satisfies the error interface
+func (e *PolicyError) Error() string {
+	rules := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		rules = append(rules, v.Rule)
+	}
+	return "password breaks the policy: " + strings.Join(rules, ", ")
+}
+
+// NewPolicyFromConfig returns the policy of:
+//   - PASSWORD_MIN_LENGTH and PASSWORD_MAX_LENGTH, 8 and 128 by default, and 72 bytes when hashing with bcrypt
+//   - PASSWORD_REQUIRE_UPPERCASE, PASSWORD_REQUIRE_LOWERCASE, PASSWORD_REQUIRE_DIGIT and PASSWORD_REQUIRE_SYMBOL
+//   - PASSWORD_DISALLOW_PERSONAL_INFO, true by default
+//   - PASSWORD_BREACHED_DIR: directory of breached password hash ranges, see NewBreachedRanges. Unset to skip the check.
+//   - PASSWORD_BREACHED_MIN_COUNT: how many times a password must have been seen in breaches to be rejected, 1 by default
+func NewPolicyFromConfig() (Policy, error) {
+	cfg := config.GetConfig()
+	cfg.SetDefault("PASSWORD_DISALLOW_PERSONAL_INFO", true)
+
+	policy := Policy{
+		MinLength:            cfg.GetInt("PASSWORD_MIN_LENGTH"),
+		MaxLength:            cfg.GetInt("PASSWORD_MAX_LENGTH"),
+		RequireUppercase:     cfg.GetBool("PASSWORD_REQUIRE_UPPERCASE"),
+		RequireLowercase:     cfg.GetBool("PASSWORD_REQUIRE_LOWERCASE"),
+		RequireDigit:         cfg.GetBool("PASSWORD_REQUIRE_DIGIT"),
+		RequireSymbol:        cfg.GetBool("PASSWORD_REQUIRE_SYMBOL"),
+		DisallowPersonalInfo: cfg.GetBool("PASSWORD_DISALLOW_PERSONAL_INFO"),
+	}
+	if strings.EqualFold(cfg.GetString("PASSWORD_HASH_ALGORITHM"), AlgorithmBcrypt) {
+		policy.MaxBytes = bcryptMaxBytes
+	}
+
+	if dir := cfg.GetString("PASSWORD_BREACHED_DIR"); dir != "" {
+		breached, err := NewBreachedRanges(dir, cfg.GetInt("PASSWORD_BREACHED_MIN_COUNT"))
+		if err != nil {
+			return Policy{}, err
+		}
+		policy.Breached = breached
+	}
+
+	return policy, nil
+}
+
+func (p Policy) minLength() int {
+	if p.MinLength == 0 {
+		return defaultMinLength
+	}
+	return p.MinLength
+}
+
+func (p Policy) maxLength() int {
+	if p.MaxLength == 0 {
+		return defaultMaxLength
+	}
+	return p.MaxLength
+}
+
+// Check returns a *PolicyError with every rule the password breaks. personalInfo is the email, name
+// and other details of the user the password must not contain.
+func (p Policy) Check(password string, personalInfo ...string) error {
+	var violations []Violation
+
+	length := utf8.RuneCountInString(password)
+	if length < p.minLength() {
+		violations = append(violations, Violation{Rule: RuleMinLength, Message: fmt.Sprintf("Must be at least %d characters long", p.minLength())})
+	}
+	if length > p.maxLength() {
+		violations = append(violations, Violation{Rule: RuleMaxLength, Message: fmt.Sprintf("Must be at most %d characters long", p.maxLength())})
+	} else if p.MaxBytes > 0 && len(password) > p.MaxBytes {
+		violations = append(violations, Violation{Rule: RuleMaxLength, Message: fmt.Sprintf("Must be at most %d bytes long", p.MaxBytes)})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUppercase && !hasUpper {
+		violations = append(violations, Violation{Rule: RuleUppercase, Message: "Must contain an uppercase letter"})
+	}
+	if p.RequireLowercase && !hasLower {
+		violations = append(violations, Violation{Rule: RuleLowercase, Message: "Must contain a lowercase letter"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, Violation{Rule: RuleDigit, Message: "Must contain a digit"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, Violation{Rule: RuleSymbol, Message: "Must contain a symbol"})
+	}
+
+	if p.DisallowPersonalInfo && containsPersonalInfo(password, personalInfo) {
+		violations = append(violations, Violation{Rule: RulePersonalInfo, Message: "Must not contain your email or name"})
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.IsBreached(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			violations = append(violations, Violation{Rule: RuleBreached, Message: "Appeared in a data breach, choose another password"})
+		}
+	}
+
+	if len(violations) > 0 {
+		return pkgerrors.WithStack(&PolicyError{Violations: violations})
+	}
+	return nil
+}
+
+// containsPersonalInfo reports whether the password contains, ignoring case, one of the details or one of
+// their words: the local part of an email and each of its dot or dash separated parts, each word of a name
+func containsPersonalInfo(password string, personalInfo []string) bool {
+	password = strings.ToLower(password)
+	for _, info := range personalInfo {
+		info = strings.ToLower(info)
+		if local, _, ok := strings.Cut(info, "@"); ok {
+			info = local
+		}
+
+		parts := strings.FieldsFunc(info, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, part := range append(parts, info) {
+			if utf8.RuneCountInString(part) >= minPersonalInfoLength && strings.Contains(password, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBreachedRanges writes the range of "password" (seen 9545824 times) and of "correct horse battery staple" (seen once)
+func newTestBreachedRanges(t *testing.T, minCount int) *BreachedRanges {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "5BAA6"), []byte(
+		"1E0E7E3B5A4E3E3B9A1DB8C3C0E3C8C4F0B:3\r\n"+
+			"1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ABF7A.txt"), []byte(
+		"AD6438836DBE526AA231ABDE2D0EEF74D42:1\n"), 0o600))
+
+	b, err := NewBreachedRanges(dir, minCount)
+	require.NoError(t, err)
+	return b
+}
+
+func TestPolicy_Check(t *testing.T) {
+	type args struct {
+		policy       Policy
+		password     string
+		personalInfo []string
+		expRules     []string
+	}
+	tcs := map[string]args{
+		"success - defaults": {
+			password: "long enough",
+		},
+		"success - every class": {
+			policy:   Policy{RequireUppercase: true, RequireLowercase: true, RequireDigit: true, RequireSymbol: true},
+			password: "Tr0ub4dor&3",
+		},
+		"success - short name parts are ignored": {
+			policy:       Policy{DisallowPersonalInfo: true},
+			password:     "jo-and-li-forever",
+			personalInfo: []string{"jo@example.com", "Jo Li"},
+		},
+		"success - seen fewer times than the minimum": {
+			policy:   Policy{Breached: newTestBreachedRanges(t, 2)},
+			password: "correct horse battery staple",
+		},
+		"success - not in the breached ranges": {
+			policy:   Policy{Breached: newTestBreachedRanges(t, 1)},
+			password: "an unlisted passphrase",
+		},
+		"err - too short": {
+			password: "short",
+			expRules: []string{RuleMinLength},
+		},
+		"err - too long": {
+			policy:   Policy{MinLength: 4, MaxLength: 10},
+			password: "more than ten characters",
+			expRules: []string{RuleMaxLength},
+		},
+		"err - too many bytes": {
+			policy:   Policy{MaxBytes: 8},
+			password: "ñandú-ñandú",
+			expRules: []string{RuleMaxLength},
+		},
+		"success - length counts characters, not bytes": {
+			policy:   Policy{MinLength: 5},
+			password: "ñandú",
+		},
+		"err - missing classes": {
+			policy:   Policy{RequireUppercase: true, RequireLowercase: true, RequireDigit: true, RequireSymbol: true},
+			password: "alllowercase",
+			expRules: []string{RuleUppercase, RuleDigit, RuleSymbol},
+		},
+		"err - contains email": {
+			policy:       Policy{DisallowPersonalInfo: true},
+			password:     "Test1Example!",
+			personalInfo: []string{"test1@example.com", "Test User"},
+			expRules:     []string{RulePersonalInfo},
+		},
+		"err - contains a word of the name": {
+			policy:       Policy{DisallowPersonalInfo: true},
+			password:     "i-am-nguyen-2024",
+			personalInfo: []string{"someone@example.com", "Van Nguyen"},
+			expRules:     []string{RulePersonalInfo},
+		},
+		"err - breached": {
+			policy:   Policy{Breached: newTestBreachedRanges(t, 1)},
+			password: "password",
+			expRules: []string{RuleBreached},
+		},
+		"err - breached once": {
+			policy:   Policy{Breached: newTestBreachedRanges(t, 1)},
+			password: "correct horse battery staple",
+			expRules: []string{RuleBreached},
+		},
+		"err - every violation is reported": {
+			policy:       Policy{RequireDigit: true, DisallowPersonalInfo: true, Breached: newTestBreachedRanges(t, 1)},
+			password:     "password",
+			personalInfo: []string{"password@example.com"},
+			expRules:     []string{RuleDigit, RulePersonalInfo, RuleBreached},
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			err := tc.policy.Check(tc.password, tc.personalInfo...)
+			if len(tc.expRules) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var policyErr *PolicyError
+			require.ErrorAs(t, err, &policyErr)
+			rules := make([]string, 0, len(policyErr.Violations))
+			for _, v := range policyErr.Violations {
+				require.NotEmpty(t, v.Message)
+				rules = append(rules, v.Rule)
+			}
+			require.Equal(t, tc.expRules, rules)
+		})
+	}
+}
+
+func TestNewBreachedRanges_NotADirectory(t *testing.T) {
+	_, err := NewBreachedRanges(filepath.Join(t.TempDir(), "missing"), 1)
+	require.Error(t, err)
+}
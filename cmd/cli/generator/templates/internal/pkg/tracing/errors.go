@@ -0,0 +1,6 @@
+package tracing
+
+import "errors"
+
+// ErrInvalidConfig means the tracing configuration is invalid
+var ErrInvalidConfig = errors.New("invalid tracing config")
@@ -0,0 +1,120 @@
+// Package tracing sets up OpenTelemetry tracing and records spans with the application's tracer
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/namf2001/go-backend-template/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters selected by OTEL_TRACES_EXPORTER
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+)
+
+const (
+	instrumentationName = "github.com/namf2001/go-backend-template"
+	defaultServiceName  = "go-backend-template"
+)
+
+// Init installs the global tracer provider and the W3C trace context propagator. Spans are exported with
+// OTEL_TRACES_EXPORTER (otlp, stdout or none), the OTLP exporter reads the standard OTEL_EXPORTER_OTLP_* variables.
+// OTEL_TRACES_SAMPLE_RATIO samples a share of the traces not started by a caller.
+// The returned func flushes the pending spans and must be called on shutdown.
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	cfg := config.GetConfig()
+
+	name := cfg.GetString("OTEL_TRACES_EXPORTER")
+	if name == "" {
+		name = ExporterNone
+	}
+	exporter, err := NewExporter(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := cfg.GetString("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	ratio := 1.0
+	if cfg.IsSet("OTEL_TRACES_SAMPLE_RATIO") {
+		ratio = cfg.GetFloat64("OTEL_TRACES_SAMPLE_RATIO")
+	}
+	if ratio < 0 || ratio > 1 {
+		return nil, fmt.Errorf("%w: OTEL_TRACES_SAMPLE_RATIO must be between 0 and 1", ErrInvalidConfig)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator())
+
+	return provider.Shutdown, nil
+}
+
+// NewExporter returns the span exporter of the name
+func NewExporter(ctx context.Context, name string) (sdktrace.SpanExporter, error) {
+	switch name {
+	case ExporterOTLP:
+		return otlptracehttp.New(ctx)
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterNone:
+		return tracetest.NewNoopExporter(), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown exporter %q", ErrInvalidConfig, name)
+	}
+}
+
+// NewInMemory installs a global tracer provider recording every span in memory, for asserting spans in tests.
+// The returned func reinstates the previous provider and propagator.
+func NewInMemory() (*tracetest.InMemoryExporter, func()) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator())
+
+	return exporter, func() {
+		_ = provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}
+}
+
+// Start starts a span with the application's tracer
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End records the error, if any, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
@@ -3,6 +3,7 @@ package accounts
 import (
 	"context"
 
+	"github.com/namf2001/go-backend-template/internal/model"
 	pkgerrors "github.com/pkg/errors"
 )
 
@@ -20,3 +21,27 @@ func (i impl) Delete(ctx context.Context, provider, providerAccountID string) er
 
 	return nil
 }
+
+// DeleteByUserID implements Repository.
+func (i impl) DeleteByUserID(ctx context.Context, userID int64, provider model.Provider) error {
+	query := `
+		DELETE FROM accounts
+		WHERE "userId" = $1 AND provider = $2
+	`
+
+	result, err := i.db.ExecContext(ctx, query, userID, provider)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+
+	return nil
+}
@@ -19,6 +19,9 @@ type Repository interface {
 
 	// Delete deletes an account by provider and provider account id
 	Delete(ctx context.Context, provider, providerAccountID string) error
+
+	// DeleteByUserID deletes the account a user linked with the provider
+	DeleteByUserID(ctx context.Context, userID int64, provider model.Provider) error
 }
 
 type impl struct {
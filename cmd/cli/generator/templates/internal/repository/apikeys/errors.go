@@ -0,0 +1,7 @@
+package apikeys
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("api key not found")
+)
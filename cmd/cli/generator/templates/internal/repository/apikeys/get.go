@@ -0,0 +1,80 @@
+package apikeys
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const columns = `id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scan(row scanner) (model.APIKey, error) {
+	var (
+		key    model.APIKey
+		scopes string
+	)
+	if err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	); err != nil {
+		return model.APIKey{}, err
+	}
+
+	if scopes != "" {
+		key.Scopes = strings.Fields(scopes)
+	}
+	return key, nil
+}
+
+// GetByHash implements Repository.
+func (i impl) GetByHash(ctx context.Context, keyHash string) (model.APIKey, error) {
+	query := `SELECT ` + columns + ` FROM api_keys WHERE key_hash = $1`
+
+	key, err := scan(i.db.QueryRowContext(ctx, query, keyHash))
+	if err == sql.ErrNoRows {
+		return model.APIKey{}, pkgerrors.WithStack(ErrNotFound)
+	}
+
+	if err != nil {
+		return model.APIKey{}, pkgerrors.WithStack(err)
+	}
+
+	return key, nil
+}
+
+// ListByUserID implements Repository.
+func (i impl) ListByUserID(ctx context.Context, userID int64) ([]model.APIKey, error) {
+	query := `SELECT ` + columns + ` FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL ORDER BY created_at, id`
+
+	rows, err := i.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+	defer rows.Close()
+
+	var keys []model.APIKey
+	for rows.Next() {
+		key, err := scan(rows)
+		if err != nil {
+			return nil, pkgerrors.WithStack(err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, pkgerrors.WithStack(rows.Err())
+}
@@ -0,0 +1,36 @@
+package apikeys
+
+import (
+	"context"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+)
+
+type Repository interface {
+	// Create stores a new API key
+	Create(ctx context.Context, key model.APIKey) (model.APIKey, error)
+
+	// GetByHash retrieves an API key by the hash of its secret, revoked and expired keys included
+	GetByHash(ctx context.Context, keyHash string) (model.APIKey, error)
+
+	// ListByUserID lists the API keys of a user that are not revoked
+	ListByUserID(ctx context.Context, userID int64) ([]model.APIKey, error)
+
+	// Revoke revokes an API key of a user
+	Revoke(ctx context.Context, userID, id int64, revokedAt time.Time) error
+
+	// UpdateLastUsed records when an API key authenticated a request
+	UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error
+}
+
+type impl struct {
+	db pg.ContextExecutor
+}
+
+func New(db pg.ContextExecutor) Repository {
+	return impl{
+		db: db,
+	}
+}
@@ -0,0 +1,69 @@
+package apikeys
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevoke(t *testing.T) {
+	type args struct {
+		givenUserID int64
+		givenID     int64
+		expErr      error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenUserID: 8001,
+			givenID:     8101,
+		},
+		"err - already revoked": {
+			givenUserID: 8001,
+			givenID:     8102,
+			expErr:      ErrNotFound,
+		},
+		"err - key of another user": {
+			givenUserID: 8001,
+			givenID:     8103,
+			expErr:      ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/api_keys.sql")
+				repo := New(tx)
+				err := repo.Revoke(context.Background(), tc.givenUserID, tc.givenID, time.Now())
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+
+					keys, err := repo.ListByUserID(context.Background(), tc.givenUserID)
+					require.NoError(t, err)
+					require.Empty(t, keys)
+				}
+			})
+		})
+	}
+}
+
+func TestUpdateLastUsed(t *testing.T) {
+	testdb.WithTx(t, func(tx pg.ContextExecutor) {
+		testdb.LoadTestSQLFile(t, tx, "testdata/api_keys.sql")
+		repo := New(tx)
+
+		require.NoError(t, repo.UpdateLastUsed(context.Background(), 8101, time.Now()))
+
+		got, err := repo.GetByHash(context.Background(), "hash-8101")
+		require.NoError(t, err)
+		require.NotNil(t, got.LastUsedAt)
+	})
+}
@@ -0,0 +1,249 @@
+package pg
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	"github.com/namf2001/go-backend-template/internal/pkg/tracing"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type fakeExecutor struct {
+	ContextExecutor
+	err error
+}
+
+func (f fakeExecutor) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return driver.RowsAffected(2), nil
+}
+
+func (f fakeExecutor) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, f.err
+}
+
+func TestInstrumentedExecutor(t *testing.T) {
+	type args struct {
+		query       string
+		givenErr    error
+		isQuery     bool // QueryContext instead of ExecContext
+		expName     string
+		expCode     codes.Code
+		expAffected int64
+		hasAffected bool
+	}
+	tcs := map[string]args{
+		"success - exec": {
+			query:       "UPDATE users SET name = $1 WHERE id = $2",
+			expName:     "UPDATE",
+			hasAffected: true,
+			expAffected: 2,
+		},
+		"success - query": {
+			query:   "\n\t\tSELECT id FROM users",
+			isQuery: true,
+			expName: "SELECT",
+		},
+		"success - lowercase cte": {
+			query:   "(with recent as (SELECT 1) SELECT * FROM recent)",
+			isQuery: true,
+			expName: "WITH",
+		},
+		"err - exec": {
+			query:    "DELETE FROM users WHERE id = $1",
+			givenErr: errors.New("connection refused"),
+			expName:  "DELETE",
+			expCode:  codes.Error,
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			spans, restore := tracing.NewInMemory()
+			t.Cleanup(restore)
+			exec := instrumentedExecutor{next: fakeExecutor{err: tc.givenErr}}
+			// The test runs in this package, so the method is the first caller outside it
+			errCounter := metrics.DBQueryErrors.WithLabelValues("testing.tRunner", tc.expName)
+			errsBefore := testutil.ToFloat64(errCounter)
+
+			// When
+			var err error
+			if tc.isQuery {
+				_, err = exec.QueryContext(context.Background(), tc.query)
+			} else {
+				_, err = exec.ExecContext(context.Background(), tc.query)
+			}
+
+			// Then
+			require.Equal(t, tc.givenErr, err)
+			ended := spans.GetSpans()
+			require.Len(t, ended, 1)
+			require.Equal(t, tc.expName, ended[0].Name)
+			require.Equal(t, tc.expCode, ended[0].Status.Code)
+
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, kv := range ended[0].Attributes {
+				attrs[kv.Key] = kv.Value
+			}
+			require.Equal(t, "postgresql", attrs["db.system.name"].AsString())
+			require.Equal(t, tc.expName, attrs["db.operation.name"].AsString())
+			require.Equal(t, tc.query, attrs["db.query.text"].AsString())
+			affected, ok := attrs[attrRowsAffected]
+			require.Equal(t, tc.hasAffected, ok)
+			require.Equal(t, tc.expAffected, affected.AsInt64())
+			require.Equal(t, "testing.tRunner", attrs["code.function.name"].AsString())
+
+			expErrs := errsBefore
+			if tc.givenErr != nil {
+				expErrs++
+			}
+			require.Equal(t, expErrs, testutil.ToFloat64(errCounter))
+		})
+	}
+}
+
+func TestInstrumentedExecutor_SlowQuery(t *testing.T) {
+	type args struct {
+		slowQuery time.Duration
+		expLogged bool
+	}
+	tcs := map[string]args{
+		"success - logged":   {slowQuery: time.Nanosecond, expLogged: true},
+		"success - fast":     {slowQuery: time.Hour},
+		"success - disabled": {},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			var buf bytes.Buffer
+			l, err := logger.New(&buf, logger.FormatJSON, zap.NewAtomicLevelAt(zapcore.DebugLevel))
+			require.NoError(t, err)
+			previous := logger.L()
+			logger.SetDefault(l)
+			t.Cleanup(func() { logger.SetDefault(previous) })
+			exec := instrumentedExecutor{next: fakeExecutor{}, slowQuery: tc.slowQuery}
+
+			// When
+			_, err = exec.ExecContext(context.Background(), "UPDATE users SET name = $1", "secret")
+
+			// Then
+			require.NoError(t, err)
+			if !tc.expLogged {
+				require.Empty(t, buf.String())
+				return
+			}
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			require.Equal(t, "slow query", entry["message"])
+			require.Equal(t, "UPDATE", entry["operation"])
+			require.Equal(t, argsFingerprint([]interface{}{"secret"}), entry["args_fingerprint"])
+			require.NotContains(t, buf.String(), "secret\"")
+		})
+	}
+}
+
+func TestMethodName(t *testing.T) {
+	type args struct {
+		function string
+		expName  string
+	}
+	tcs := map[string]args{
+		"success - pointer receiver": {
+			function: "github.com/namf2001/go-backend-template/internal/repository/users.(*impl).GetByEmail",
+			expName:  "users.GetByEmail",
+		},
+		"success - closure": {
+			function: "github.com/namf2001/go-backend-template/internal/repository/users.(*impl).List.func1.2",
+			expName:  "users.List",
+		},
+		"success - value receiver": {
+			function: "github.com/namf2001/go-backend-template/internal/repository/sessions.impl.Create",
+			expName:  "sessions.Create",
+		},
+		"success - function": {
+			function: "testing.tRunner",
+			expName:  "testing.tRunner",
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+
+			// When
+			name := methodName(tc.function)
+
+			// Then
+			require.Equal(t, tc.expName, name)
+		})
+	}
+}
+
+func TestArgsFingerprint(t *testing.T) {
+	id := int64(1001)
+	type args struct {
+		a, b     []interface{}
+		expEqual bool
+	}
+	tcs := map[string]args{
+		"success - equal values": {
+			a:        []interface{}{"a@b.c", int64(1001)},
+			b:        []interface{}{"a@b.c", int64(1001)},
+			expEqual: true,
+		},
+		"success - pointer": {
+			a:        []interface{}{&id},
+			b:        []interface{}{int64(1001)},
+			expEqual: true,
+		},
+		"success - different values": {
+			a: []interface{}{"a@b.c", int64(1001)},
+			b: []interface{}{"a@b.c", int64(1002)},
+		},
+		"success - different types": {
+			a: []interface{}{"1001"},
+			b: []interface{}{int64(1001)},
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+
+			// When
+			a, b := argsFingerprint(tc.a), argsFingerprint(tc.b)
+
+			// Then
+			require.Len(t, a, 16)
+			require.Equal(t, tc.expEqual, a == b)
+		})
+	}
+}
+
+func TestArgsFingerprint_Keyed(t *testing.T) {
+	// Given
+	args := []interface{}{"a@b.c"}
+	fingerprint := argsFingerprint(args)
+	previous := fingerprintKey
+	fingerprintKey = []byte("another process")
+	t.Cleanup(func() { fingerprintKey = previous })
+
+	// When
+	other := argsFingerprint(args)
+
+	// Then
+	require.NotEqual(t, fingerprint, other)
+}
@@ -25,5 +25,6 @@ type BeginnerExecutor interface {
 	ContextExecutor
 
 	PingContext(ctx context.Context) error
+	Stats() sql.DBStats
 	Close() error
 }
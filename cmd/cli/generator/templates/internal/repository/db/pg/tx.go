@@ -7,7 +7,19 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	"github.com/namf2001/go-backend-template/internal/pkg/tracing"
 	pkgerrors "github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Transaction span attributes
+const (
+	attrTxAttempts  = attribute.Key("db.transaction.begin_attempts")
+	attrTxCommitted = attribute.Key("db.transaction.committed")
 )
 
 // Tx starts a transaction with default backoff policy (3 retries, 1 minute max)
@@ -19,11 +31,15 @@ func Tx(ctx context.Context, dbconn BeginnerExecutor, callback func(ContextExecu
 // It handles begin, commit, and rollback automatically.
 // If callback returns an error, the transaction is rolled back.
 // If callback succeeds, the transaction is committed.
-func TxWithBackOff(ctx context.Context, b backoff.BackOff, dbconn BeginnerExecutor, callback func(ContextExecutor) error) error {
+// The transaction is recorded as a span, parent of the spans of its queries.
+func TxWithBackOff(ctx context.Context, b backoff.BackOff, dbconn BeginnerExecutor, callback func(ContextExecutor) error) (err error) {
 	if b == nil {
 		b = &backoff.StopBackOff{}
 	}
 
+	ctx, span := tracing.Start(ctx, "TRANSACTION", trace.WithAttributes(semconv.DBSystemNamePostgreSQL))
+	defer func() { tracing.End(span, err) }()
+
 	tx, err := beginTx(ctx, dbconn, b)
 	if err != nil {
 		return err
@@ -31,6 +47,7 @@ func TxWithBackOff(ctx context.Context, b backoff.BackOff, dbconn BeginnerExecut
 
 	var committed bool
 	defer func() {
+		span.SetAttributes(attrTxCommitted.Bool(committed))
 		if committed {
 			return
 		}
@@ -38,8 +55,12 @@ func TxWithBackOff(ctx context.Context, b backoff.BackOff, dbconn BeginnerExecut
 		_ = tx.Rollback()
 	}()
 
-	// Execute the callback within the transaction
-	if err = callback(tx); err != nil {
+	// Execute the callback within the transaction, instrumented like the queries of the pool
+	var exec ContextExecutor = tx
+	if db, ok := dbconn.(instrumentedDB); ok {
+		exec = db.instrumentTx(tx, span)
+	}
+	if err = callback(exec); err != nil {
 		return err
 	}
 
@@ -58,12 +79,16 @@ func beginTx(ctx context.Context, dbconn BeginnerExecutor, b backoff.BackOff) (*
 		tryCount++
 		var err error
 
-		logger.INFO.Printf("DB: BeginTx Attempt: %d", tryCount)
+		logger.Debug(ctx, "DB BeginTx", zap.Int("attempt", tryCount))
 		tx, err = dbconn.BeginTx(ctx, nil)
 
 		return pkgerrors.WithStack(err)
 	}, backoff.WithContext(b, ctx)); err != nil {
+		metrics.DBTxBeginRetries.Add(float64(tryCount - 1))
+		metrics.DBTxBeginFailures.Inc()
 		return nil, err
 	}
+	metrics.DBTxBeginRetries.Add(float64(tryCount - 1))
+	trace.SpanFromContext(ctx).SetAttributes(attrTxAttempts.Int(tryCount))
 	return tx, nil
 }
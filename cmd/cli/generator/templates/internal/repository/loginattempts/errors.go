@@ -0,0 +1,7 @@
+package loginattempts
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("login attempt not found")
+)
@@ -0,0 +1,36 @@
+package loginattempts
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Get implements Repository.
+func (i impl) Get(ctx context.Context, key string) (model.LoginAttempt, error) {
+	query := `
+		SELECT key, failures, last_failure_at, locked_until
+		FROM login_attempts
+		WHERE key = $1
+	`
+
+	var attempt model.LoginAttempt
+	err := i.db.QueryRowContext(ctx, query, key).Scan(
+		&attempt.Key,
+		&attempt.Failures,
+		&attempt.LastFailureAt,
+		&attempt.LockedUntil,
+	)
+
+	if err == sql.ErrNoRows {
+		return model.LoginAttempt{}, pkgerrors.WithStack(ErrNotFound)
+	}
+
+	if err != nil {
+		return model.LoginAttempt{}, pkgerrors.WithStack(err)
+	}
+
+	return attempt, nil
+}
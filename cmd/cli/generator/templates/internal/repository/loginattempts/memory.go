@@ -0,0 +1,74 @@
+package loginattempts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// memory is an in-process Repository for tests and single instance deployments
+type memory struct {
+	mu       sync.Mutex
+	attempts map[string]model.LoginAttempt
+}
+
+// NewMemory returns a Repository that keeps attempts in memory
+func NewMemory() Repository {
+	return &memory{attempts: map[string]model.LoginAttempt{}}
+}
+
+// Get implements Repository.
+func (m *memory) Get(_ context.Context, key string) (model.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attempt, ok := m.attempts[key]
+	if !ok {
+		return model.LoginAttempt{}, pkgerrors.WithStack(ErrNotFound)
+	}
+	return attempt, nil
+}
+
+// RecordFailure implements Repository.
+func (m *memory) RecordFailure(_ context.Context, key string, now, windowStart time.Time) (model.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attempt, ok := m.attempts[key]
+	if !ok || attempt.LastFailureAt.Before(windowStart) {
+		attempt.Key = key
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = now
+
+	m.attempts[key] = attempt
+	return attempt, nil
+}
+
+// Lock implements Repository.
+func (m *memory) Lock(_ context.Context, key string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attempt, ok := m.attempts[key]
+	if !ok {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+	attempt.LockedUntil = &until
+
+	m.attempts[key] = attempt
+	return nil
+}
+
+// Reset implements Repository.
+func (m *memory) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.attempts, key)
+	return nil
+}
@@ -0,0 +1,33 @@
+package loginattempts
+
+import (
+	"context"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+)
+
+type Repository interface {
+	// Get retrieves the failed attempts of a key
+	Get(ctx context.Context, key string) (model.LoginAttempt, error)
+
+	// RecordFailure counts a failed attempt at now. Failures older than windowStart are forgotten first.
+	RecordFailure(ctx context.Context, key string, now, windowStart time.Time) (model.LoginAttempt, error)
+
+	// Lock locks a key out until the given time
+	Lock(ctx context.Context, key string, until time.Time) error
+
+	// Reset forgets the failed attempts of a key
+	Reset(ctx context.Context, key string) error
+}
+
+type impl struct {
+	db pg.ContextExecutor
+}
+
+func New(db pg.ContextExecutor) Repository {
+	return impl{
+		db: db,
+	}
+}
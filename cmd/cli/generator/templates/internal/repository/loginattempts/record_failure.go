@@ -0,0 +1,35 @@
+package loginattempts
+
+import (
+	"context"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// RecordFailure implements Repository.
+func (i impl) RecordFailure(ctx context.Context, key string, now, windowStart time.Time) (model.LoginAttempt, error) {
+	// A single upsert so concurrent failures are all counted
+	query := `
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = $2
+		RETURNING key, failures, last_failure_at, locked_until
+	`
+
+	var attempt model.LoginAttempt
+	err := i.db.QueryRowContext(ctx, query, key, now, windowStart).Scan(
+		&attempt.Key,
+		&attempt.Failures,
+		&attempt.LastFailureAt,
+		&attempt.LockedUntil,
+	)
+	if err != nil {
+		return model.LoginAttempt{}, pkgerrors.WithStack(err)
+	}
+
+	return attempt, nil
+}
@@ -0,0 +1,8 @@
+package mfa
+
+import "errors"
+
+var (
+	ErrNotFound        = errors.New("mfa not found")
+	ErrStepAlreadyUsed = errors.New("mfa code already used")
+)
@@ -0,0 +1,37 @@
+package mfa
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Get implements Repository.
+func (i impl) Get(ctx context.Context, userID int64) (model.MFA, error) {
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+
+	var mfa model.MFA
+	err := i.db.QueryRowContext(ctx, query, userID).Scan(
+		&mfa.UserID,
+		&mfa.Secret,
+		&mfa.EnabledAt,
+		&mfa.LastUsedStep,
+		&mfa.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return model.MFA{}, pkgerrors.WithStack(ErrNotFound)
+	}
+
+	if err != nil {
+		return model.MFA{}, pkgerrors.WithStack(err)
+	}
+
+	return mfa, nil
+}
@@ -0,0 +1,51 @@
+package mfa
+
+import (
+	"context"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// ReplaceRecoveryCodes implements Repository.
+func (i impl) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	if _, err := i.db.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	query := `
+		INSERT INTO mfa_recovery_codes (user_id, code_hash)
+		VALUES ($1, $2)
+	`
+	for _, hash := range codeHashes {
+		if _, err := i.db.ExecContext(ctx, query, userID, hash); err != nil {
+			return pkgerrors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+// UseRecoveryCode implements Repository.
+func (i impl) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := i.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+
+	return nil
+}
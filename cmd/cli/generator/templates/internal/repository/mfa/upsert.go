@@ -0,0 +1,102 @@
+package mfa
+
+import (
+	"context"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Upsert implements Repository.
+func (i impl) Upsert(ctx context.Context, mfa model.MFA) error {
+	query := `
+		INSERT INTO user_mfa (user_id, secret, enabled_at, last_used_step, created_at)
+		VALUES ($1, $2, NULL, 0, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			enabled_at = NULL,
+			last_used_step = 0,
+			created_at = EXCLUDED.created_at
+	`
+
+	if _, err := i.db.ExecContext(ctx, query, mfa.UserID, mfa.Secret); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
+
+// Enable implements Repository.
+func (i impl) Enable(ctx context.Context, userID int64, enabledAt time.Time) error {
+	query := `
+		UPDATE user_mfa
+		SET enabled_at = $2
+		WHERE user_id = $1
+	`
+
+	result, err := i.db.ExecContext(ctx, query, userID, enabledAt)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+
+	return nil
+}
+
+// UseStep implements Repository.
+func (i impl) UseStep(ctx context.Context, userID int64, step int64) error {
+	// Compare and set so two concurrent requests cannot both use the same code
+	query := `
+		UPDATE user_mfa
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2
+	`
+
+	result, err := i.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrStepAlreadyUsed)
+	}
+
+	return nil
+}
+
+// Delete implements Repository.
+func (i impl) Delete(ctx context.Context, userID int64) error {
+	if _, err := i.db.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	result, err := i.db.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+
+	return nil
+}
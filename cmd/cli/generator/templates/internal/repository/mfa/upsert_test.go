@@ -0,0 +1,79 @@
+package mfa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsert(t *testing.T) {
+	type args struct {
+		givenUserID int64
+	}
+
+	tcs := map[string]args{
+		"new enrollment": {
+			givenUserID: 6002,
+		},
+		"re-enrollment resets the existing factor": {
+			givenUserID: 6001,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/mfa.sql")
+				repo := New(tx)
+				err := repo.Upsert(context.Background(), model.MFA{UserID: tc.givenUserID, Secret: "new-secret"})
+				require.NoError(t, err)
+
+				mfa, err := repo.Get(context.Background(), tc.givenUserID)
+				require.NoError(t, err)
+				require.Equal(t, "new-secret", mfa.Secret)
+				require.False(t, mfa.IsEnabled())
+				require.Zero(t, mfa.LastUsedStep)
+			})
+		})
+	}
+}
+
+func TestUseStep(t *testing.T) {
+	type args struct {
+		givenStep int64
+		expErr    error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenStep: 101,
+		},
+		"err - step already used": {
+			givenStep: 100,
+			expErr:    ErrStepAlreadyUsed,
+		},
+		"err - older step": {
+			givenStep: 99,
+			expErr:    ErrStepAlreadyUsed,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/mfa.sql")
+				err := New(tx).UseStep(context.Background(), 6001, tc.givenStep)
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+				}
+			})
+		})
+	}
+}
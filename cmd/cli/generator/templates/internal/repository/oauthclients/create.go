@@ -0,0 +1,34 @@
+package oauthclients
+
+import (
+	"context"
+	"strings"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Create implements Repository.
+func (i impl) Create(ctx context.Context, client model.OAuthClient) (model.OAuthClient, error) {
+	query := `
+		INSERT INTO oauth_clients (client_id, secret_hash, name, redirect_uris, grant_types, scopes, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING id, created_at
+	`
+
+	err := i.db.QueryRowContext(ctx, query,
+		client.ClientID,
+		client.SecretHash,
+		client.Name,
+		strings.Join(client.RedirectURIs, " "),
+		strings.Join(client.GrantTypes, " "),
+		strings.Join(client.Scopes, " "),
+		client.CreatedBy,
+	).Scan(&client.ID, &client.CreatedAt)
+
+	if err != nil {
+		return model.OAuthClient{}, pkgerrors.WithStack(err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,26 @@
+package oauthclients
+
+import (
+	"context"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Delete implements Repository.
+func (i impl) Delete(ctx context.Context, id int64) error {
+	result, err := i.db.ExecContext(ctx, `DELETE FROM oauth_clients WHERE id = $1`, id)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+
+	return nil
+}
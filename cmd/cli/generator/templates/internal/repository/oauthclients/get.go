@@ -0,0 +1,95 @@
+package oauthclients
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const columns = `id, client_id, secret_hash, name, redirect_uris, grant_types, scopes, created_by, created_at`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scan(row scanner) (model.OAuthClient, error) {
+	var (
+		client                           model.OAuthClient
+		redirectURIs, grantTypes, scopes string
+	)
+	if err := row.Scan(
+		&client.ID,
+		&client.ClientID,
+		&client.SecretHash,
+		&client.Name,
+		&redirectURIs,
+		&grantTypes,
+		&scopes,
+		&client.CreatedBy,
+		&client.CreatedAt,
+	); err != nil {
+		return model.OAuthClient{}, err
+	}
+
+	client.RedirectURIs = strings.Fields(redirectURIs)
+	client.GrantTypes = strings.Fields(grantTypes)
+	client.Scopes = strings.Fields(scopes)
+	return client, nil
+}
+
+// GetByID implements Repository.
+func (i impl) GetByID(ctx context.Context, id int64) (model.OAuthClient, error) {
+	query := `SELECT ` + columns + ` FROM oauth_clients WHERE id = $1`
+
+	client, err := scan(i.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return model.OAuthClient{}, pkgerrors.WithStack(ErrNotFound)
+	}
+
+	if err != nil {
+		return model.OAuthClient{}, pkgerrors.WithStack(err)
+	}
+
+	return client, nil
+}
+
+// GetByClientID implements Repository.
+func (i impl) GetByClientID(ctx context.Context, clientID string) (model.OAuthClient, error) {
+	query := `SELECT ` + columns + ` FROM oauth_clients WHERE client_id = $1`
+
+	client, err := scan(i.db.QueryRowContext(ctx, query, clientID))
+	if err == sql.ErrNoRows {
+		return model.OAuthClient{}, pkgerrors.WithStack(ErrNotFound)
+	}
+
+	if err != nil {
+		return model.OAuthClient{}, pkgerrors.WithStack(err)
+	}
+
+	return client, nil
+}
+
+// List implements Repository.
+func (i impl) List(ctx context.Context) ([]model.OAuthClient, error) {
+	query := `SELECT ` + columns + ` FROM oauth_clients ORDER BY created_at, id`
+
+	rows, err := i.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+	defer rows.Close()
+
+	var clients []model.OAuthClient
+	for rows.Next() {
+		client, err := scan(rows)
+		if err != nil {
+			return nil, pkgerrors.WithStack(err)
+		}
+		clients = append(clients, client)
+	}
+
+	return clients, pkgerrors.WithStack(rows.Err())
+}
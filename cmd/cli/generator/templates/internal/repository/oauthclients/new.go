@@ -0,0 +1,35 @@
+package oauthclients
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+)
+
+type Repository interface {
+	// Create registers a new OAuth client
+	Create(ctx context.Context, client model.OAuthClient) (model.OAuthClient, error)
+
+	// GetByID retrieves an OAuth client by id
+	GetByID(ctx context.Context, id int64) (model.OAuthClient, error)
+
+	// GetByClientID retrieves an OAuth client by its public client_id
+	GetByClientID(ctx context.Context, clientID string) (model.OAuthClient, error)
+
+	// List lists every registered OAuth client
+	List(ctx context.Context) ([]model.OAuthClient, error)
+
+	// Delete deletes an OAuth client with its authorization codes
+	Delete(ctx context.Context, id int64) error
+}
+
+type impl struct {
+	db pg.ContextExecutor
+}
+
+func New(db pg.ContextExecutor) Repository {
+	return impl{
+		db: db,
+	}
+}
@@ -0,0 +1,7 @@
+package oauthcodes
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("oauth authorization code not found")
+)
@@ -0,0 +1,88 @@
+package oauthcodes
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Create implements Repository.
+func (i impl) Create(ctx context.Context, code model.OAuthAuthorizationCode) error {
+	// Used codes are kept to detect replays, drop the ones expired long ago
+	if _, err := i.db.ExecContext(ctx, `DELETE FROM oauth_authorization_codes WHERE expires_at < NOW() - INTERVAL '1 day'`); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	query := `
+		INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+	`
+
+	if _, err := i.db.ExecContext(ctx, query,
+		code.CodeHash,
+		code.ClientID,
+		code.UserID,
+		code.RedirectURI,
+		strings.Join(code.Scopes, " "),
+		code.CodeChallenge,
+		code.ExpiresAt,
+	); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
+
+// Redeem implements Repository.
+func (i impl) Redeem(ctx context.Context, codeHash, familyID string) (model.OAuthAuthorizationCode, error) {
+	// The row is locked so concurrent redemptions of a code see each other
+	query := `
+		WITH previous AS (
+			SELECT code_hash, used_at, family_id FROM oauth_authorization_codes
+			WHERE code_hash = $1
+			FOR UPDATE
+		)
+		UPDATE oauth_authorization_codes c
+		SET used_at = COALESCE(previous.used_at, NOW()),
+			family_id = CASE WHEN previous.used_at IS NULL THEN $2 ELSE previous.family_id END
+		FROM previous
+		WHERE c.code_hash = previous.code_hash
+		RETURNING c.code_hash, c.client_id, c.user_id, c.redirect_uri, c.scopes, c.code_challenge, c.expires_at, c.created_at,
+			c.family_id, previous.used_at
+	`
+
+	var (
+		code   model.OAuthAuthorizationCode
+		scopes string
+		usedAt sql.NullTime
+	)
+	err := i.db.QueryRowContext(ctx, query, codeHash, familyID).Scan(
+		&code.CodeHash,
+		&code.ClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&scopes,
+		&code.CodeChallenge,
+		&code.ExpiresAt,
+		&code.CreatedAt,
+		&code.FamilyID,
+		&usedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return model.OAuthAuthorizationCode{}, pkgerrors.WithStack(ErrNotFound)
+	}
+
+	if err != nil {
+		return model.OAuthAuthorizationCode{}, pkgerrors.WithStack(err)
+	}
+
+	code.Scopes = strings.Fields(scopes)
+	if usedAt.Valid {
+		code.UsedAt = &usedAt.Time
+	}
+	return code, nil
+}
@@ -0,0 +1,78 @@
+package oauthcodes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate(t *testing.T) {
+	testdb.WithTx(t, func(tx pg.ContextExecutor) {
+		testdb.LoadTestSQLFile(t, tx, "testdata/oauth_codes.sql")
+		repo := New(tx)
+
+		err := repo.Create(context.Background(), model.OAuthAuthorizationCode{
+			CodeHash:      "hash-code-2",
+			ClientID:      "web-app",
+			UserID:        9201,
+			RedirectURI:   "https://app.example.com/callback",
+			Scopes:        []string{"users:read"},
+			CodeChallenge: "challenge",
+			ExpiresAt:     time.Now().Add(time.Minute),
+		})
+		require.NoError(t, err)
+
+		got, err := repo.Redeem(context.Background(), "hash-code-2", "family-1")
+		require.NoError(t, err)
+		require.Equal(t, int64(9201), got.UserID)
+		require.Equal(t, []string{"users:read"}, got.Scopes)
+	})
+}
+
+func TestRedeem(t *testing.T) {
+	type args struct {
+		givenHash string
+		expErr    error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenHash: "hash-code-1",
+		},
+		"err - not found": {
+			givenHash: "hash-unknown",
+			expErr:    ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/oauth_codes.sql")
+				repo := New(tx)
+				got, err := repo.Redeem(context.Background(), tc.givenHash, "family-1")
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+					return
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, "web-app", got.ClientID)
+				require.Equal(t, "family-1", got.FamilyID)
+				require.Nil(t, got.UsedAt)
+
+				// A replay returns the family of the first redemption
+				got, err = repo.Redeem(context.Background(), tc.givenHash, "family-2")
+				require.NoError(t, err)
+				require.Equal(t, "family-1", got.FamilyID)
+				require.NotNil(t, got.UsedAt)
+			})
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package ratelimits
+
+import "errors"
+
+var (
+	ErrInvalidRateLimit = errors.New("rate limit needs a positive limit and period")
+)
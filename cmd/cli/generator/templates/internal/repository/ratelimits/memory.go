@@ -0,0 +1,63 @@
+package ratelimits
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// pruneEvery is how many takes the stores wait between two sweeps of full buckets. Instances share the
+// Postgres store, it sweeps on a random sample of takes instead of counting them.
+const pruneEvery = 1000
+
+// memory is an in-process Repository for tests and single instance deployments
+type memory struct {
+	mu    sync.Mutex
+	tats  map[string]time.Time
+	takes int
+}
+
+// NewMemory returns a Repository that keeps buckets in memory
+func NewMemory() Repository {
+	return &memory{tats: map[string]time.Time{}}
+}
+
+// Take implements Repository.
+func (m *memory) Take(_ context.Context, key string, now time.Time, limit model.RateLimit) (model.RateLimitResult, error) {
+	if limit.Limit <= 0 || limit.Period <= 0 {
+		return model.RateLimitResult{}, pkgerrors.WithStack(ErrInvalidRateLimit)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.takes++
+	if m.takes%pruneEvery == 0 {
+		m.prune(now)
+	}
+
+	tat, ok := m.tats[key]
+	if !ok || tat.Before(now) {
+		tat = now
+	}
+
+	next := tat.Add(limit.Interval())
+	if next.Sub(now) > limit.Period {
+		return model.NewRateLimitResult(limit, tat, now, false), nil
+	}
+
+	m.tats[key] = next
+	return model.NewRateLimitResult(limit, next, now, true), nil
+}
+
+// prune forgets full buckets, they are the same as no bucket
+func (m *memory) prune(now time.Time) {
+	for key, tat := range m.tats {
+		if tat.Before(now) {
+			delete(m.tats, key)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package ratelimits
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTake(t *testing.T) {
+	testdb.WithTx(t, func(tx pg.ContextExecutor) {
+		testdb.LoadTestSQLFile(t, tx, "testdata/rate_limits.sql")
+
+		testTake(t, New(tx))
+	})
+}
+
+func TestPrune(t *testing.T) {
+	testdb.WithTx(t, func(tx pg.ContextExecutor) {
+		testdb.LoadTestSQLFile(t, tx, "testdata/rate_limits.sql")
+		repo := New(tx).(impl)
+		now := time.Now().Truncate(time.Second)
+		_, err := repo.Take(context.Background(), "login:ip:192.0.2.1", now, model.RateLimit{Limit: 3, Period: time.Minute})
+		require.NoError(t, err)
+
+		err = repo.prune(context.Background(), now)
+		require.NoError(t, err)
+
+		// The old bucket of testdata/rate_limits.sql is full and deleted, the new one is kept
+		var keys []string
+		rows, err := tx.QueryContext(context.Background(), `SELECT key FROM rate_limits`)
+		require.NoError(t, err)
+		defer rows.Close()
+		for rows.Next() {
+			var key string
+			require.NoError(t, rows.Scan(&key))
+			keys = append(keys, key)
+		}
+		require.NoError(t, rows.Err())
+		require.Equal(t, []string{"login:ip:192.0.2.1"}, keys)
+	})
+}
+
+// testTake runs the same scenario against every store, holding the buckets of testdata/rate_limits.sql
+func testTake(t *testing.T, repo Repository) {
+	ctx := context.Background()
+	limit := model.RateLimit{Limit: 3, Period: time.Minute}
+	now := time.Now().Truncate(time.Second)
+
+	// A full bucket allows a burst of Limit requests
+	for remaining := 2; remaining >= 0; remaining-- {
+		result, err := repo.Take(ctx, "login:ip:192.0.2.1", now, limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, remaining, result.Remaining)
+		require.Equal(t, 3, result.Limit)
+		require.Zero(t, result.RetryAfter)
+	}
+
+	result, err := repo.Take(ctx, "login:ip:192.0.2.1", now, limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Zero(t, result.Remaining)
+	require.Equal(t, time.Minute, result.ResetAfter)
+	require.Equal(t, 20*time.Second, result.RetryAfter)
+
+	// Other keys have their own bucket, an old bucket is full
+	result, err = repo.Take(ctx, "login:ip:192.0.2.2", now, limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	result, err = repo.Take(ctx, "api:user:1001", now, limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 2, result.Remaining)
+
+	// A token is back every Period/Limit
+	result, err = repo.Take(ctx, "login:ip:192.0.2.1", now.Add(20*time.Second), limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Zero(t, result.Remaining)
+
+	_, err = repo.Take(ctx, "login:ip:192.0.2.1", now, model.RateLimit{})
+	require.ErrorIs(t, err, ErrInvalidRateLimit)
+}
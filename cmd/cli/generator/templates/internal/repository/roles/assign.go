@@ -0,0 +1,57 @@
+package roles
+
+import (
+	"context"
+	"database/sql"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// AssignToUser implements Repository.
+func (i impl) AssignToUser(ctx context.Context, userID int64, roleName string) error {
+	var roleID int64
+	err := i.db.QueryRowContext(ctx, `SELECT id FROM roles WHERE name = $1`, roleName).Scan(&roleID)
+	if err == sql.ErrNoRows {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	query := `
+		INSERT INTO user_roles (user_id, role_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	if _, err := i.db.ExecContext(ctx, query, userID, roleID); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
+
+// RemoveFromUser implements Repository.
+func (i impl) RemoveFromUser(ctx context.Context, userID int64, roleName string) error {
+	query := `
+		DELETE FROM user_roles ur
+		USING roles r
+		WHERE ur.role_id = r.id AND ur.user_id = $1 AND r.name = $2
+	`
+
+	result, err := i.db.ExecContext(ctx, query, userID, roleName)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package roles
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("role not found")
+)
@@ -0,0 +1,57 @@
+package sessions
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// ListActiveByUserID implements Repository.
+func (i impl) ListActiveByUserID(ctx context.Context, userID int64) ([]model.Session, error) {
+	query := `
+		SELECT ` + columns + `
+		FROM sessions
+		WHERE "userId" = $1 AND "revokedAt" IS NULL AND expires > NOW()
+		ORDER BY created_at DESC
+	`
+
+	rows, err := i.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+	defer rows.Close()
+
+	var sessions []model.Session
+	for rows.Next() {
+		session, err := scan(rows)
+		if err != nil {
+			return nil, pkgerrors.WithStack(err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+
+	return sessions, nil
+}
+
+// IsFamilyActive implements Repository.
+func (i impl) IsFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM sessions
+			WHERE "familyId" = $1 AND "revokedAt" IS NULL AND expires > NOW()
+		)
+	`
+
+	var active bool
+	if err := i.db.QueryRowContext(ctx, query, familyID).Scan(&active); err != nil {
+		return false, pkgerrors.WithStack(err)
+	}
+
+	return active, nil
+}
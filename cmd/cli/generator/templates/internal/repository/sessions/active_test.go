@@ -0,0 +1,53 @@
+package sessions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListActiveByUserID(t *testing.T) {
+	testdb.WithTx(t, func(tx pg.ContextExecutor) {
+		testdb.LoadTestSQLFile(t, tx, "testdata/sessions.sql")
+		repo := New(tx)
+		sessions, err := repo.ListActiveByUserID(context.Background(), 2001)
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		for _, s := range sessions {
+			require.Nil(t, s.RevokedAt)
+		}
+	})
+}
+
+func TestIsFamilyActive(t *testing.T) {
+	type args struct {
+		givenFamilyID string
+		expActive     bool
+	}
+
+	tcs := map[string]args{
+		"active": {
+			givenFamilyID: "family-1",
+			expActive:     true,
+		},
+		"unknown family": {
+			givenFamilyID: "family-unknown",
+			expActive:     false,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/sessions.sql")
+				repo := New(tx)
+				active, err := repo.IsFamilyActive(context.Background(), tc.givenFamilyID)
+				require.NoError(t, err)
+				require.Equal(t, tc.expActive, active)
+			})
+		})
+	}
+}
@@ -2,6 +2,7 @@ package sessions
 
 import (
 	"context"
+	"strings"
 
 	"github.com/namf2001/go-backend-template/internal/model"
 	pkgerrors "github.com/pkg/errors"
@@ -10,22 +11,23 @@ import (
 // Create implements Repository.
 func (i impl) Create(ctx context.Context, session model.Session) (model.Session, error) {
 	query := `
-		INSERT INTO sessions ("userId", expires, "sessionToken")
-		VALUES ($1, $2, $3)
-		RETURNING id, "userId", expires, "sessionToken"
+		INSERT INTO sessions ("userId", expires, "sessionToken", "familyId", "clientId", scopes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
 	`
 
-	var created model.Session
-	err := i.db.QueryRowContext(ctx, query, session.UserID, session.Expires, session.SessionToken).Scan(
-		&created.ID,
-		&created.UserID,
-		&created.Expires,
-		&created.SessionToken,
-	)
+	err := i.db.QueryRowContext(ctx, query,
+		session.UserID,
+		session.Expires,
+		session.SessionToken,
+		session.FamilyID,
+		session.ClientID,
+		strings.Join(session.Scopes, " "),
+	).Scan(&session.ID, &session.CreatedAt)
 
 	if err != nil {
 		return model.Session{}, pkgerrors.WithStack(err)
 	}
 
-	return created, nil
+	return session, nil
 }
@@ -3,26 +3,46 @@ package sessions
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"github.com/namf2001/go-backend-template/internal/model"
 	pkgerrors "github.com/pkg/errors"
 )
 
-// GetByToken implements Repository.
-func (i impl) GetByToken(ctx context.Context, token string) (model.Session, error) {
-	query := `
-		SELECT id, "userId", expires, "sessionToken"
-		FROM sessions
-		WHERE "sessionToken" = $1
-	`
-
-	var session model.Session
-	err := i.db.QueryRowContext(ctx, query, token).Scan(
+const columns = `id, "userId", expires, "sessionToken", "familyId", "clientId", scopes, "revokedAt", created_at`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scan(row scanner) (model.Session, error) {
+	var (
+		session model.Session
+		scopes  string
+	)
+	if err := row.Scan(
 		&session.ID,
 		&session.UserID,
 		&session.Expires,
 		&session.SessionToken,
-	)
+		&session.FamilyID,
+		&session.ClientID,
+		&scopes,
+		&session.RevokedAt,
+		&session.CreatedAt,
+	); err != nil {
+		return model.Session{}, err
+	}
+
+	session.Scopes = strings.Fields(scopes)
+	return session, nil
+}
+
+// GetByToken implements Repository.
+func (i impl) GetByToken(ctx context.Context, token string) (model.Session, error) {
+	query := `SELECT ` + columns + ` FROM sessions WHERE "sessionToken" = $1`
+
+	session, err := scan(i.db.QueryRowContext(ctx, query, token))
 
 	if err == sql.ErrNoRows {
 		return model.Session{}, pkgerrors.WithStack(ErrNotFound)
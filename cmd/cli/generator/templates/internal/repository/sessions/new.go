@@ -16,6 +16,27 @@ type Repository interface {
 
 	// Delete deletes a session by session token
 	Delete(ctx context.Context, token string) error
+
+	// Revoke marks an active session as revoked, returning ErrNotFound if no active session matches
+	Revoke(ctx context.Context, token string) error
+
+	// RevokeFamily revokes every active session in a token family
+	RevokeFamily(ctx context.Context, familyID string) error
+
+	// RevokeByUserID revokes every active session of a user
+	RevokeByUserID(ctx context.Context, userID int64) error
+
+	// RevokeByUserIDExcept revokes every active session of a user except the given token family
+	RevokeByUserIDExcept(ctx context.Context, userID int64, familyID string) error
+
+	// RevokeByClientID revokes every active session granted to an OAuth client
+	RevokeByClientID(ctx context.Context, clientID string) error
+
+	// ListActiveByUserID retrieves the sessions of a user that are neither revoked nor expired
+	ListActiveByUserID(ctx context.Context, userID int64) ([]model.Session, error)
+
+	// IsFamilyActive reports whether a token family still has an active session
+	IsFamilyActive(ctx context.Context, familyID string) (bool, error)
 }
 
 type impl struct {
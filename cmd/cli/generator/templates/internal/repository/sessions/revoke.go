@@ -0,0 +1,96 @@
+package sessions
+
+import (
+	"context"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Revoke implements Repository.
+func (i impl) Revoke(ctx context.Context, token string) error {
+	query := `
+		UPDATE sessions
+		SET "revokedAt" = NOW()
+		WHERE "sessionToken" = $1 AND "revokedAt" IS NULL
+	`
+
+	result, err := i.db.ExecContext(ctx, query, token)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+
+	return nil
+}
+
+// RevokeFamily implements Repository.
+func (i impl) RevokeFamily(ctx context.Context, familyID string) error {
+	query := `
+		UPDATE sessions
+		SET "revokedAt" = NOW()
+		WHERE "familyId" = $1 AND "revokedAt" IS NULL
+	`
+
+	_, err := i.db.ExecContext(ctx, query, familyID)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
+
+// RevokeByUserID implements Repository.
+func (i impl) RevokeByUserID(ctx context.Context, userID int64) error {
+	query := `
+		UPDATE sessions
+		SET "revokedAt" = NOW()
+		WHERE "userId" = $1 AND "revokedAt" IS NULL
+	`
+
+	_, err := i.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
+
+// RevokeByUserIDExcept implements Repository.
+func (i impl) RevokeByUserIDExcept(ctx context.Context, userID int64, familyID string) error {
+	query := `
+		UPDATE sessions
+		SET "revokedAt" = NOW()
+		WHERE "userId" = $1 AND "familyId" <> $2 AND "revokedAt" IS NULL
+	`
+
+	_, err := i.db.ExecContext(ctx, query, userID, familyID)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
+
+// RevokeByClientID implements Repository.
+func (i impl) RevokeByClientID(ctx context.Context, clientID string) error {
+	query := `
+		UPDATE sessions
+		SET "revokedAt" = NOW()
+		WHERE "clientId" = $1 AND "revokedAt" IS NULL
+	`
+
+	_, err := i.db.ExecContext(ctx, query, clientID)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package verificationtokens
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsume(t *testing.T) {
+	type args struct {
+		givenIdentifier string
+		givenToken      string
+		expErr          error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenIdentifier: "verify-email:test1@example.com",
+			givenToken:      "hashed-token-1",
+		},
+		"err - identifier mismatch": {
+			givenIdentifier: "verify-email:test2@example.com",
+			givenToken:      "hashed-token-1",
+			expErr:          ErrNotFound,
+		},
+		"err - token not found": {
+			givenIdentifier: "verify-email:test1@example.com",
+			givenToken:      "unknown-token",
+			expErr:          ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/verification_tokens.sql")
+				repo := New(tx)
+				consumed, err := repo.Consume(context.Background(), tc.givenIdentifier, tc.givenToken)
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+					require.Equal(t, tc.givenToken, consumed.Token)
+
+					// A consumed token cannot be used again
+					_, err = repo.Consume(context.Background(), tc.givenIdentifier, tc.givenToken)
+					require.ErrorIs(t, err, ErrNotFound)
+				}
+			})
+		})
+	}
+}
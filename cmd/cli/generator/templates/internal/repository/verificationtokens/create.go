@@ -0,0 +1,30 @@
+package verificationtokens
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Create implements Repository.
+func (i impl) Create(ctx context.Context, token model.VerificationToken) (model.VerificationToken, error) {
+	query := `
+		INSERT INTO verification_token (identifier, expires, token)
+		VALUES ($1, $2, $3)
+		RETURNING identifier, expires, token
+	`
+
+	var created model.VerificationToken
+	err := i.db.QueryRowContext(ctx, query, token.Identifier, token.Expires, token.Token).Scan(
+		&created.Identifier,
+		&created.Expires,
+		&created.Token,
+	)
+
+	if err != nil {
+		return model.VerificationToken{}, pkgerrors.WithStack(err)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,22 @@
+package verificationtokens
+
+import (
+	"context"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// DeleteByIdentifier implements Repository.
+func (i impl) DeleteByIdentifier(ctx context.Context, identifier string) error {
+	query := `
+		DELETE FROM verification_token
+		WHERE identifier = $1
+	`
+
+	_, err := i.db.ExecContext(ctx, query, identifier)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
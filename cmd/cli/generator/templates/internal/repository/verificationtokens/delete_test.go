@@ -0,0 +1,25 @@
+package verificationtokens
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteByIdentifier(t *testing.T) {
+	testdb.WithTx(t, func(tx pg.ContextExecutor) {
+		testdb.LoadTestSQLFile(t, tx, "testdata/verification_tokens.sql")
+		repo := New(tx)
+		require.NoError(t, repo.DeleteByIdentifier(context.Background(), "verify-email:test1@example.com"))
+
+		_, err := repo.Consume(context.Background(), "verify-email:test1@example.com", "hashed-token-2")
+		require.ErrorIs(t, err, ErrNotFound)
+
+		// Tokens of other identifiers are untouched
+		_, err = repo.Consume(context.Background(), "verify-email:test2@example.com", "hashed-token-3")
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,7 @@
+package verificationtokens
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("verification token not found")
+)
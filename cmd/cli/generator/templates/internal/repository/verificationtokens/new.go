@@ -0,0 +1,29 @@
+package verificationtokens
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+)
+
+type Repository interface {
+	// Create creates a new verification token
+	Create(ctx context.Context, token model.VerificationToken) (model.VerificationToken, error)
+
+	// Consume deletes a verification token and returns it, so it can only be used once
+	Consume(ctx context.Context, identifier, token string) (model.VerificationToken, error)
+
+	// DeleteByIdentifier deletes every verification token issued for an identifier
+	DeleteByIdentifier(ctx context.Context, identifier string) error
+}
+
+type impl struct {
+	db pg.ContextExecutor
+}
+
+func New(db pg.ContextExecutor) Repository {
+	return impl{
+		db: db,
+	}
+}
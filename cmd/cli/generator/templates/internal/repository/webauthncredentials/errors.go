@@ -0,0 +1,9 @@
+package webauthncredentials
+
+import "errors"
+
+var (
+	ErrNotFound              = errors.New("webauthn credential not found")
+	ErrAlreadyRegistered     = errors.New("webauthn credential already registered")
+	ErrSignCountNotIncreased = errors.New("webauthn sign count did not increase")
+)
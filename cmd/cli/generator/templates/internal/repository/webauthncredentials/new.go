@@ -0,0 +1,37 @@
+package webauthncredentials
+
+import (
+	"context"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+)
+
+type Repository interface {
+	// Create stores a new passkey
+	Create(ctx context.Context, credential model.WebAuthnCredential) (model.WebAuthnCredential, error)
+
+	// GetByCredentialID retrieves a passkey by the id the authenticator gave it
+	GetByCredentialID(ctx context.Context, credentialID []byte) (model.WebAuthnCredential, error)
+
+	// ListByUserID lists the passkeys of a user
+	ListByUserID(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error)
+
+	// UpdateAfterLogin stores the sign counter and backup state reported by a login. It returns
+	// ErrSignCountNotIncreased if a concurrent login already stored a higher counter.
+	UpdateAfterLogin(ctx context.Context, credential model.WebAuthnCredential, usedAt time.Time) error
+
+	// Delete deletes a passkey of a user
+	Delete(ctx context.Context, userID, id int64) error
+}
+
+type impl struct {
+	db pg.ContextExecutor
+}
+
+func New(db pg.ContextExecutor) Repository {
+	return impl{
+		db: db,
+	}
+}
@@ -0,0 +1,54 @@
+package webauthncredentials
+
+import (
+	"context"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// UpdateAfterLogin implements Repository.
+func (i impl) UpdateAfterLogin(ctx context.Context, credential model.WebAuthnCredential, usedAt time.Time) error {
+	// Authenticators that do not implement a counter always report 0, any other value must increase
+	query := `
+		UPDATE webauthn_credentials
+		SET sign_count = $2, backup_state = $3, last_used_at = $4
+		WHERE id = $1 AND (sign_count < $2 OR (sign_count = 0 AND $2 = 0))
+	`
+
+	result, err := i.db.ExecContext(ctx, query, credential.ID, int64(credential.SignCount), credential.BackupState, usedAt)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrSignCountNotIncreased)
+	}
+
+	return nil
+}
+
+// Delete implements Repository.
+func (i impl) Delete(ctx context.Context, userID, id int64) error {
+	result, err := i.db.ExecContext(ctx, `DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+
+	return nil
+}
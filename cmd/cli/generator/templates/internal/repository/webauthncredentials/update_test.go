@@ -0,0 +1,111 @@
+package webauthncredentials
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateAfterLogin(t *testing.T) {
+	type args struct {
+		givenID        int64
+		givenSignCount uint32
+		expErr         error
+	}
+
+	tcs := map[string]args{
+		"success - counter increased": {
+			givenID:        7201,
+			givenSignCount: 11,
+		},
+		"success - authenticator without counter": {
+			givenID:        7202,
+			givenSignCount: 0,
+		},
+		"err - counter did not increase": {
+			givenID:        7201,
+			givenSignCount: 10,
+			expErr:         ErrSignCountNotIncreased,
+		},
+		"err - counter went back to zero": {
+			givenID:        7201,
+			givenSignCount: 0,
+			expErr:         ErrSignCountNotIncreased,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/webauthn_credentials.sql")
+				repo := New(tx)
+				err := repo.UpdateAfterLogin(context.Background(), model.WebAuthnCredential{
+					ID:          tc.givenID,
+					SignCount:   tc.givenSignCount,
+					BackupState: true,
+				}, time.Now())
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+
+					credentials, err := repo.ListByUserID(context.Background(), 7001)
+					require.NoError(t, err)
+					for _, c := range credentials {
+						if c.ID == tc.givenID {
+							require.Equal(t, tc.givenSignCount, c.SignCount)
+							require.True(t, c.BackupState)
+							require.NotNil(t, c.LastUsedAt)
+						}
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	type args struct {
+		givenUserID int64
+		givenID     int64
+		expErr      error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenUserID: 7001,
+			givenID:     7201,
+		},
+		"err - credential of another user": {
+			givenUserID: 7001,
+			givenID:     7203,
+			expErr:      ErrNotFound,
+		},
+		"err - credential not found": {
+			givenUserID: 7001,
+			givenID:     9999,
+			expErr:      ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/webauthn_credentials.sql")
+				err := New(tx).Delete(context.Background(), tc.givenUserID, tc.givenID)
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_sessions_user_id;
+DROP INDEX IF EXISTS idx_sessions_family_id;
+DROP INDEX IF EXISTS idx_sessions_session_token;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS created_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS "revokedAt";
+ALTER TABLE sessions DROP COLUMN IF EXISTS "familyId";
@@ -0,0 +1,16 @@
+-- Turn sessions into refresh token storage.
+-- Each row holds the SHA-256 hash of one refresh token. Rotating a token revokes
+-- its row and inserts a new one in the same family, so a revoked token that is
+-- presented again can be detected and the whole family revoked.
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS "familyId" VARCHAR(255);
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS "revokedAt" TIMESTAMPTZ;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- Existing sessions each become their own family
+UPDATE sessions SET "familyId" = "sessionToken" WHERE "familyId" IS NULL;
+ALTER TABLE sessions ALTER COLUMN "familyId" SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_session_token ON sessions("sessionToken");
+CREATE INDEX IF NOT EXISTS idx_sessions_family_id ON sessions("familyId");
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions("userId");
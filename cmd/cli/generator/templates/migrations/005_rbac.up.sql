@@ -0,0 +1,58 @@
+-- Role-based access control.
+-- Roles group permissions, users are granted roles. Roles and permissions are carried
+-- in access tokens, so changes take effect when the user's access token is refreshed.
+-- Grant the first admin manually:
+--   INSERT INTO user_roles (user_id, role_id) SELECT <user id>, id FROM roles WHERE name = 'admin';
+
+CREATE TABLE IF NOT EXISTS roles (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(100) UNIQUE NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+  id BIGSERIAL PRIMARY KEY,
+  name VARCHAR(100) UNIQUE NOT NULL,
+  description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+  role_id BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+  permission_id BIGINT NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+  PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role_id BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles(role_id);
+
+-- Seed default roles and permissions
+INSERT INTO roles (name, description) VALUES
+  ('admin', 'Full access to every resource'),
+  ('user', 'Regular user, can only manage their own account')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+  ('users:read', 'Read any user'),
+  ('users:create', 'Create users'),
+  ('users:update', 'Update any user'),
+  ('users:delete', 'Delete any user'),
+  ('roles:manage', 'Grant and revoke roles')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- Every existing user gets the default role
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u CROSS JOIN roles r
+WHERE r.name = 'user'
+ON CONFLICT DO NOTHING;
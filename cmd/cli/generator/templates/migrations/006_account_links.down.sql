@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_accounts_user_provider;
+DROP INDEX IF EXISTS idx_accounts_provider_account;
@@ -0,0 +1,18 @@
+-- TOTP two-factor authentication. The secret is encrypted with ENCRYPTION_KEY, enabled_at is NULL
+-- until the user confirms enrollment with a first code, last_used_step prevents replaying a code.
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled_at TIMESTAMPTZ,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Single use recovery codes, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    UNIQUE (user_id, code_hash)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS webauthn_credentials;
+DELETE FROM accounts WHERE provider = 'webauthn';
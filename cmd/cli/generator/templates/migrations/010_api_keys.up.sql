@@ -0,0 +1,26 @@
+-- Personal API keys. Only the SHA-256 hash of a key is stored, the prefix identifies it in listings.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(32) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
+
+-- Self-service permission of every user, so API keys can be limited to reading the profile.
+-- Access tokens issued before carry it once refreshed.
+INSERT INTO permissions (name, description) VALUES
+  ('profile:read', 'Read the own profile, linked accounts and API keys')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name IN ('admin', 'user') AND p.name = 'profile:read'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,9 @@
+DELETE FROM permissions WHERE name = 'oauth_clients:manage';
+
+-- Refresh tokens of OAuth clients must not become first-party sessions
+DELETE FROM sessions WHERE "clientId" <> '';
+ALTER TABLE sessions DROP COLUMN IF EXISTS scopes;
+ALTER TABLE sessions DROP COLUMN IF EXISTS "clientId";
+
+DROP TABLE IF EXISTS oauth_authorization_codes;
+DROP TABLE IF EXISTS oauth_clients;
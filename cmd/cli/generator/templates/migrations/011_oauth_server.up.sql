@@ -0,0 +1,41 @@
+-- OAuth2 authorization server for first-party downstream apps.
+-- Only the SHA-256 hash of client secrets and authorization codes is stored.
+-- Refresh tokens issued to clients are sessions tagged with the client and the granted scopes.
+
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id BIGSERIAL PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL UNIQUE,
+    secret_hash VARCHAR(64) NOT NULL DEFAULT '', -- Empty for public clients, which must use PKCE
+    name VARCHAR(255) NOT NULL,
+    redirect_uris TEXT NOT NULL DEFAULT '', -- Space separated
+    grant_types TEXT NOT NULL DEFAULT '', -- Space separated
+    scopes TEXT NOT NULL DEFAULT '', -- Space separated
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+    code_hash VARCHAR(64) PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scopes TEXT NOT NULL DEFAULT '',
+    code_challenge VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- Used codes are kept so a replay revokes the token family issued for them
+    used_at TIMESTAMPTZ,
+    family_id VARCHAR(64) NOT NULL DEFAULT ''
+);
+
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS "clientId" VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS scopes TEXT NOT NULL DEFAULT '';
+
+INSERT INTO permissions (name, description) VALUES
+  ('oauth_clients:manage', 'Register and delete OAuth clients')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin' AND p.name = 'oauth_clients:manage'
+ON CONFLICT DO NOTHING;
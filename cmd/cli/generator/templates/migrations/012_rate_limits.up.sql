@@ -0,0 +1,9 @@
+-- Token buckets of the rate limiter, keyed by policy and client ("login:ip:<address>", "api:user:<id>").
+-- tat is the theoretical arrival time of GCRA: when the bucket is full again. Rows past it can be deleted.
+CREATE TABLE IF NOT EXISTS rate_limits (
+    key VARCHAR(320) PRIMARY KEY,
+    tat TIMESTAMPTZ NOT NULL,
+    allowed BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_tat ON rate_limits(tat);
@@ -3,24 +3,45 @@ package main
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	_ "github.com/namf2001/go-backend-template/docs/swagger"
+	authcontroller "github.com/namf2001/go-backend-template/internal/controller/auth"
 	appMiddleware "github.com/namf2001/go-backend-template/internal/handler/middleware"
 	authhandler "github.com/namf2001/go-backend-template/internal/handler/rest/v1/auth"
+	authserverhandler "github.com/namf2001/go-backend-template/internal/handler/rest/v1/authserver"
 	usershandler "github.com/namf2001/go-backend-template/internal/handler/rest/v1/users"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	"github.com/namf2001/go-backend-template/internal/repository/ratelimits"
+	"github.com/spf13/viper"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
+// Rate limit policies, see loadRateLimitPolicies
+const (
+	rateLimitLogin    = "login"    // Credential checks, per IP
+	rateLimitRegister = "register" // Account creation, per IP
+	rateLimitAuth     = "auth"     // Other public auth and OAuth endpoints, per IP
+	rateLimitAPI      = "api"      // Authenticated requests, per API key or user
+)
+
 // router defines the routes & handlers of the app
 type router struct {
-	ctx          context.Context
-	usersHandler *usershandler.Handler
-	authHandler  *authhandler.Handler
+	ctx               context.Context
+	authCtrl          authcontroller.Controller
+	usersHandler      *usershandler.Handler
+	authHandler       *authhandler.Handler
+	authServerHandler *authserverhandler.Handler
+	rateLimits        ratelimits.Repository
+	rateLimitPolicies map[string]appMiddleware.RateLimitPolicy
+	trustedProxies    appMiddleware.TrustedProxies // Proxies whose X-Forwarded-For tells the client IP
+	serveMetrics      bool                         // False when metrics are served on their own admin port
 }
 
 // handler returns the handler for use by the server
@@ -29,17 +50,20 @@ func (rtr router) handler() http.Handler {
 
 	// Middleware
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(appMiddleware.RealIP(rtr.trustedProxies))
+	r.Use(appMiddleware.Tracing)
+	r.Use(appMiddleware.Metrics)
+	r.Use(appMiddleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
 
 	// CORS
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", appMiddleware.APIKeyHeader},
+		ExposedHeaders: []string{"Link", appMiddleware.HeaderRateLimitLimit, appMiddleware.HeaderRateLimitRemaining,
+			appMiddleware.HeaderRateLimitReset, appMiddleware.HeaderRateLimitPolicy, appMiddleware.HeaderRetryAfter},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -61,7 +85,11 @@ func (rtr router) public(r chi.Router) {
 		_, _ = w.Write([]byte("OK"))
 	})
 
-	r.Handle("/metrics", promhttp.Handler())
+	if rtr.serveMetrics {
+		r.Handle("/metrics", metrics.Handler())
+	}
+
+	r.Get("/.well-known/jwks.json", rtr.authHandler.JWKS())
 
 	// Swagger UI
 	r.Get("/swagger/*", httpSwagger.Handler(
@@ -72,21 +100,147 @@ func (rtr router) public(r chi.Router) {
 func (rtr router) apiV1(r chi.Router) {
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Route("/auth", func(r chi.Router) {
-			r.Post("/login", rtr.authHandler.Login())
-			r.Post("/register", rtr.authHandler.Register())
-			r.Get("/google/login", rtr.authHandler.GoogleLogin())
-			r.Get("/google/callback", rtr.authHandler.GoogleCallback())
+			r.Group(func(r chi.Router) {
+				r.Use(rtr.rateLimit(rateLimitLogin))
+				r.Post("/login", rtr.authHandler.Login())
+				r.Get("/magic-link/callback", rtr.authHandler.MagicLinkCallback())
+				r.Post("/mfa/verify", rtr.authHandler.VerifyMFA())
+				r.Post("/webauthn/login/finish", rtr.authHandler.FinishPasskeyLogin())
+			})
+			r.With(rtr.rateLimit(rateLimitRegister)).Post("/register", rtr.authHandler.Register())
+			r.Group(func(r chi.Router) {
+				r.Use(rtr.rateLimit(rateLimitAuth))
+				r.Post("/refresh", rtr.authHandler.Refresh())
+				r.Get("/{provider}/login", rtr.authHandler.OAuthLogin())
+				r.Get("/{provider}/callback", rtr.authHandler.OAuthCallback())
+				r.Get("/verify-email/confirm", rtr.authHandler.ConfirmEmail())
+				r.Post("/password/forgot", rtr.authHandler.ForgotPassword())
+				r.Post("/password/reset", rtr.authHandler.ResetPassword())
+				r.Post("/magic-link", rtr.authHandler.RequestMagicLink())
+				r.Post("/webauthn/login/begin", rtr.authHandler.BeginPasskeyLogin())
+			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(appMiddleware.RequireAuth(rtr.authCtrl))
+				r.Use(rtr.rateLimit(rateLimitAPI))
+				r.Use(appMiddleware.RequireSession)
+				r.Post("/logout", rtr.authHandler.Logout())
+				r.Post("/logout-all", rtr.authHandler.LogoutAll())
+				r.Get("/sessions", rtr.authHandler.ListSessions())
+				r.Delete("/sessions/{id}", rtr.authHandler.RevokeSession())
+				r.Post("/verify-email/request", rtr.authHandler.RequestEmailVerification())
+				r.Post("/mfa/enroll", rtr.authHandler.EnrollMFA())
+				r.Post("/mfa/enroll/confirm", rtr.authHandler.ConfirmMFA())
+				r.Post("/mfa/disable", rtr.authHandler.DisableMFA())
+				r.Post("/webauthn/register/begin", rtr.authHandler.BeginPasskeyRegistration())
+				r.Post("/webauthn/register/finish", rtr.authHandler.FinishPasskeyRegistration())
+				r.Get("/webauthn/credentials", rtr.authHandler.ListPasskeys())
+				r.Delete("/webauthn/credentials/{id}", rtr.authHandler.DeletePasskey())
+			})
+		})
+
+		r.Route("/oauth", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(rtr.rateLimit(rateLimitAuth))
+				r.Get("/authorize", rtr.authServerHandler.Authorize())
+				r.Post("/token", rtr.authServerHandler.Token())
+				r.Post("/introspect", rtr.authServerHandler.Introspect())
+				r.Post("/revoke", rtr.authServerHandler.Revoke())
+			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(appMiddleware.RequireAuth(rtr.authCtrl))
+				r.Use(rtr.rateLimit(rateLimitAPI))
+				r.Use(appMiddleware.RequireSession)
+				r.Post("/authorize", rtr.authServerHandler.Consent())
+
+				r.Route("/clients", func(r chi.Router) {
+					r.Use(appMiddleware.RequirePermission(model.PermissionOAuthClientsManage))
+					r.Get("/", rtr.authServerHandler.ListClients())
+					r.Post("/", rtr.authServerHandler.CreateClient())
+					r.Delete("/{id}", rtr.authServerHandler.DeleteClient())
+				})
+			})
 		})
 
 		r.Group(func(r chi.Router) {
-			r.Use(appMiddleware.RequireAuth)
+			r.Use(appMiddleware.RequireAuth(rtr.authCtrl))
+			r.Use(rtr.rateLimit(rateLimitAPI))
+			r.Route("/me", func(r chi.Router) {
+				r.Group(func(r chi.Router) {
+					r.Use(appMiddleware.RequirePermission(model.PermissionProfileRead))
+					r.Get("/", rtr.usersHandler.Me())
+					r.Get("/accounts", rtr.usersHandler.ListAccounts())
+					r.Get("/api-keys", rtr.authHandler.ListAPIKeys())
+				})
+
+				// Credentials and the profile are only managed by the user, never by a machine holding one of their
+				// API keys or a client they consented to
+				r.Group(func(r chi.Router) {
+					r.Use(appMiddleware.RequireSession)
+					r.Patch("/", rtr.usersHandler.UpdateMe())
+					r.Post("/password", rtr.usersHandler.ChangePassword())
+					r.Post("/accounts/{provider}/link", rtr.authHandler.LinkAccount())
+					r.Delete("/accounts/{provider}", rtr.usersHandler.UnlinkAccount())
+					r.Post("/api-keys", rtr.authHandler.CreateAPIKey())
+					r.Delete("/api-keys/{id}", rtr.authHandler.RevokeAPIKey())
+				})
+			})
 			r.Route("/users", func(r chi.Router) {
-				r.Post("/", rtr.usersHandler.CreateUser())
-				r.Get("/", rtr.usersHandler.ListUsers())
-				r.Get("/{id}", rtr.usersHandler.GetUser())
-				r.Put("/{id}", rtr.usersHandler.UpdateUser())
-				r.Delete("/{id}", rtr.usersHandler.DeleteUser())
+				r.With(appMiddleware.RequirePermission(model.PermissionUsersCreate)).Post("/", rtr.usersHandler.CreateUser())
+				r.With(appMiddleware.RequirePermission(model.PermissionUsersRead)).Get("/", rtr.usersHandler.ListUsers())
+				r.With(appMiddleware.RequireOwnerOrPermission("id", model.PermissionUsersRead)).Get("/{id}", rtr.usersHandler.GetUser())
+				r.With(appMiddleware.RequireOwnerOrPermission("id", model.PermissionUsersUpdate)).Put("/{id}", rtr.usersHandler.UpdateUser())
+				r.With(appMiddleware.RequirePermission(model.PermissionUsersDelete)).Delete("/{id}", rtr.usersHandler.DeleteUser())
+
+				r.Route("/{id}/roles", func(r chi.Router) {
+					r.Use(appMiddleware.RequirePermission(model.PermissionRolesManage))
+					r.Get("/", rtr.usersHandler.ListRoles())
+					r.Post("/", rtr.usersHandler.AssignRole())
+					r.Delete("/{role}", rtr.usersHandler.RemoveRole())
+				})
+			})
+
+			// GET returns the log level, PUT {"level": "debug"} changes it until the next restart
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(appMiddleware.RequireRole(model.RoleAdmin))
+				r.Use(appMiddleware.RequireSession)
+				r.Handle("/log-level", logger.Level())
 			})
 		})
 	})
 }
+
+// rateLimit returns the middleware of a rate limit policy
+func (rtr router) rateLimit(name string) func(http.Handler) http.Handler {
+	return appMiddleware.RateLimit(rtr.rateLimits, rtr.rateLimitPolicies[name])
+}
+
+// loadRateLimitPolicies reads the policies from RATE_LIMIT_LOGIN, RATE_LIMIT_REGISTER, RATE_LIMIT_AUTH
+// and RATE_LIMIT_API, each written <limit>/<period>
+func loadRateLimitPolicies(cfg *viper.Viper) (map[string]appMiddleware.RateLimitPolicy, error) {
+	defaults := []struct {
+		name  string
+		limit string
+		key   appMiddleware.KeyFunc
+	}{
+		{name: rateLimitLogin, limit: "10/1m", key: appMiddleware.KeyByIP},
+		{name: rateLimitRegister, limit: "5/1h", key: appMiddleware.KeyByIP},
+		{name: rateLimitAuth, limit: "30/1m", key: appMiddleware.KeyByIP},
+		{name: rateLimitAPI, limit: "600/1m", key: appMiddleware.KeyByAPIKey},
+	}
+
+	policies := make(map[string]appMiddleware.RateLimitPolicy, len(defaults))
+	for _, d := range defaults {
+		setting := cfg.GetString("RATE_LIMIT_" + strings.ToUpper(d.name))
+		if setting == "" {
+			setting = d.limit
+		}
+		limit, err := appMiddleware.ParseRateLimit(setting)
+		if err != nil {
+			return nil, err
+		}
+		policies[d.name] = appMiddleware.RateLimitPolicy{Name: d.name, Limit: limit, Key: d.key}
+	}
+	return policies, nil
+}
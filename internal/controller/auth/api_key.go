@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	"github.com/namf2001/go-backend-template/internal/repository/apikeys"
+	pkgerrors "github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// apiKeyPrefix starts every API key so leaked keys are easy to recognize, e.g. by secret scanners
+	apiKeyPrefix = "gbt_"
+	// apiKeyDisplayLength is how much of the key is stored in clear to tell keys apart
+	apiKeyDisplayLength = len(apiKeyPrefix) + 8
+	// apiKeyLastUsedInterval limits the writes made to record when a key was last used
+	apiKeyLastUsedInterval = time.Minute
+)
+
+// CreateAPIKeyInput holds the settings of a new API key
+type CreateAPIKeyInput struct {
+	UserID    int64
+	Name      string
+	Scopes    []string // Permissions of the user the key is limited to, all of them when empty
+	ExpiresAt *time.Time
+}
+
+// NewAPIKey is a freshly created API key. Key is only available at creation, only its hash is stored.
+type NewAPIKey struct {
+	model.APIKey
+	Key string
+}
+
+// APIKeyPrincipal is who a request authenticated with an API key acts as
+type APIKeyPrincipal struct {
+	UserID      int64
+	APIKeyID    int64
+	Roles       []string
+	Permissions []string
+}
+
+// CreateAPIKey creates an API key for the user. Scopes must be permissions the user has.
+func (i impl) CreateAPIKey(ctx context.Context, input CreateAPIKeyInput) (NewAPIKey, error) {
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return NewAPIKey{}, pkgerrors.WithStack(ErrInvalidAPIKeyExpiry)
+	}
+
+	if len(input.Scopes) > 0 {
+		permissions, err := i.repo.Role().ListPermissionsByUserID(ctx, input.UserID)
+		if err != nil {
+			return NewAPIKey{}, err
+		}
+		for _, scope := range input.Scopes {
+			if !slices.Contains(permissions, scope) {
+				return NewAPIKey{}, pkgerrors.Wrap(ErrInvalidAPIKeyScope, scope)
+			}
+		}
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return NewAPIKey{}, err
+	}
+	key := apiKeyPrefix + secret
+
+	created, err := i.repo.APIKey().Create(ctx, model.APIKey{
+		UserID:    input.UserID,
+		Name:      input.Name,
+		Prefix:    key[:apiKeyDisplayLength],
+		KeyHash:   utils.HashToken(key),
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(input.Scopes))),
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		return NewAPIKey{}, err
+	}
+
+	logSecurityEvent(ctx, "api_key_created", []string{"user:" + strconv.FormatInt(input.UserID, 10)}, zap.Int64("api_key_id", created.ID))
+	return NewAPIKey{APIKey: created, Key: key}, nil
+}
+
+// ListAPIKeys lists the API keys of a user that are not revoked
+func (i impl) ListAPIKeys(ctx context.Context, userID int64) ([]model.APIKey, error) {
+	return i.repo.APIKey().ListByUserID(ctx, userID)
+}
+
+// RevokeAPIKey revokes an API key of a user, requests using it are rejected right away
+func (i impl) RevokeAPIKey(ctx context.Context, userID, id int64) error {
+	if err := i.repo.APIKey().Revoke(ctx, userID, id, time.Now()); err != nil {
+		if errors.Is(err, apikeys.ErrNotFound) {
+			return pkgerrors.WithStack(ErrAPIKeyNotFound)
+		}
+		return err
+	}
+
+	logSecurityEvent(ctx, "api_key_revoked", []string{"user:" + strconv.FormatInt(userID, 10)}, zap.Int64("api_key_id", id))
+	return nil
+}
+
+// AuthenticateAPIKey resolves the user and permissions of an API key. Roles and permissions are read
+// on every request so changes to the user's roles apply to their keys immediately.
+func (i impl) AuthenticateAPIKey(ctx context.Context, key string) (APIKeyPrincipal, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return APIKeyPrincipal{}, pkgerrors.WithStack(ErrInvalidAPIKey)
+	}
+
+	apiKey, err := i.repo.APIKey().GetByHash(ctx, utils.HashToken(key))
+	if err != nil {
+		if errors.Is(err, apikeys.ErrNotFound) {
+			return APIKeyPrincipal{}, pkgerrors.WithStack(ErrInvalidAPIKey)
+		}
+		return APIKeyPrincipal{}, err
+	}
+
+	now := time.Now()
+	if !apiKey.IsActive(now) {
+		return APIKeyPrincipal{}, pkgerrors.WithStack(ErrInvalidAPIKey)
+	}
+
+	permissions, err := i.repo.Role().ListPermissionsByUserID(ctx, apiKey.UserID)
+	if err != nil {
+		return APIKeyPrincipal{}, err
+	}
+
+	// A scoped key gets no roles, RequireRole would otherwise let it past its scopes
+	var roleNames []string
+	if len(apiKey.Scopes) == 0 {
+		roles, err := i.repo.Role().ListByUserID(ctx, apiKey.UserID)
+		if err != nil {
+			return APIKeyPrincipal{}, err
+		}
+		for _, r := range roles {
+			roleNames = append(roleNames, r.Name)
+		}
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyLastUsedInterval {
+		if err := i.repo.APIKey().UpdateLastUsed(ctx, apiKey.ID, now); err != nil {
+			return APIKeyPrincipal{}, err
+		}
+	}
+
+	return APIKeyPrincipal{
+		UserID:      apiKey.UserID,
+		APIKeyID:    apiKey.ID,
+		Roles:       roleNames,
+		Permissions: scopePermissions(permissions, apiKey.Scopes),
+	}, nil
+}
+
+// scopePermissions returns the permissions of the user the scopes allow, all of them when there are no scopes.
+// Permissions the user lost since the key was created are not granted back by its scopes.
+func scopePermissions(permissions, scopes []string) []string {
+	if len(scopes) == 0 {
+		return permissions
+	}
+
+	scoped := make([]string, 0, len(scopes))
+	for _, p := range permissions {
+		if slices.Contains(scopes, p) {
+			scoped = append(scoped, p)
+		}
+	}
+	return scoped
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopePermissions(t *testing.T) {
+	type args struct {
+		givenPermissions []string
+		givenScopes      []string
+		exp              []string
+	}
+
+	tcs := map[string]args{
+		"unscoped key gets every permission": {
+			givenPermissions: []string{"users:read", "users:update"},
+			exp:              []string{"users:read", "users:update"},
+		},
+		"scoped key": {
+			givenPermissions: []string{"users:read", "users:update", "roles:manage"},
+			givenScopes:      []string{"users:read"},
+			exp:              []string{"users:read"},
+		},
+		"scope the user lost is not granted": {
+			givenPermissions: []string{"users:read"},
+			givenScopes:      []string{"users:read", "roles:manage"},
+			exp:              []string{"users:read"},
+		},
+		"scoped key of a user without permissions": {
+			givenScopes: []string{"users:read"},
+			exp:         []string{},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.exp, scopePermissions(tc.givenPermissions, tc.givenScopes))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/mailer"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const (
+	purposeVerifyEmail           = "verify-email"
+	defaultEmailVerificationTTL  = 24 * time.Hour
+	emailVerificationConfirmPath = "/api/v1/auth/verify-email/confirm"
+)
+
+// RequestEmailVerification sends a new verification link to the user's email
+func (i impl) RequestEmailVerification(ctx context.Context, userID int64) error {
+	user, err := i.repo.User().GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified != nil {
+		return pkgerrors.WithStack(ErrEmailAlreadyVerified)
+	}
+
+	return i.sendEmailVerification(ctx, user)
+}
+
+// ConfirmEmail consumes a verification token and marks the user's email as verified
+func (i impl) ConfirmEmail(ctx context.Context, email, token string) error {
+	if err := i.consumeVerificationToken(ctx, purposeVerifyEmail, email, token); err != nil {
+		return err
+	}
+
+	user, err := i.repo.User().GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerified = &now
+	return i.repo.User().Update(ctx, user)
+}
+
+func (i impl) sendEmailVerification(ctx context.Context, user model.User) error {
+	ttl := durationFromConfig("EMAIL_VERIFICATION_TTL", defaultEmailVerificationTTL)
+	token, err := i.issueVerificationToken(ctx, purposeVerifyEmail, user.Email, ttl)
+	if err != nil {
+		return err
+	}
+
+	link := buildLink(emailVerificationConfirmPath, url.Values{"email": {user.Email}, "token": {token}})
+	return i.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Name, link, ttl),
+	})
+}
@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/mailer"
+	"github.com/namf2001/go-backend-template/internal/repository"
+	"github.com/namf2001/go-backend-template/internal/repository/accounts"
+	"github.com/namf2001/go-backend-template/internal/repository/mfa"
+	"github.com/namf2001/go-backend-template/internal/repository/roles"
+	"github.com/namf2001/go-backend-template/internal/repository/sessions"
+	"github.com/namf2001/go-backend-template/internal/repository/users"
+	"github.com/namf2001/go-backend-template/internal/repository/verificationtokens"
+	"github.com/namf2001/go-backend-template/internal/repository/webauthncredentials"
+)
+
+// fakeRegistry keeps the tables the login flows use in memory
+type fakeRegistry struct {
+	repository.Registry
+	users       *fakeUsers
+	accounts    *fakeAccounts
+	credentials *fakeCredentials
+	mfa         fakeMFA
+	tokens      fakeVerificationTokens
+	sessions    *fakeSessions
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{
+		users:       &fakeUsers{users: map[int64]model.User{}},
+		accounts:    &fakeAccounts{},
+		credentials: &fakeCredentials{},
+		mfa:         fakeMFA{mfa: map[int64]model.MFA{}},
+		tokens:      fakeVerificationTokens{tokens: map[string]model.VerificationToken{}},
+		sessions:    &fakeSessions{},
+	}
+}
+
+func (f *fakeRegistry) User() users.Repository                             { return f.users }
+func (f *fakeRegistry) Account() accounts.Repository                       { return f.accounts }
+func (f *fakeRegistry) WebAuthnCredential() webauthncredentials.Repository { return f.credentials }
+func (f *fakeRegistry) MFA() mfa.Repository                                { return f.mfa }
+func (f *fakeRegistry) VerificationToken() verificationtokens.Repository   { return f.tokens }
+func (f *fakeRegistry) Session() sessions.Repository                       { return f.sessions }
+func (f *fakeRegistry) Role() roles.Repository                             { return fakeRoles{} }
+
+// DoInTx rolls back the users and accounts written by a failed transaction
+func (f *fakeRegistry) DoInTx(ctx context.Context, txFunc func(ctx context.Context, txRepo repository.Registry) error, _ backoff.BackOff) error {
+	savedUsers := maps.Clone(f.users.users)
+	savedAccounts := slices.Clone(f.accounts.accounts)
+	if err := txFunc(ctx, f); err != nil {
+		f.users.users, f.accounts.accounts = savedUsers, savedAccounts
+		return err
+	}
+	return nil
+}
+
+type fakeUsers struct {
+	users.Repository
+	users map[int64]model.User
+}
+
+func (f *fakeUsers) GetByID(_ context.Context, id int64) (model.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return model.User{}, users.ErrNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUsers) GetByEmail(_ context.Context, email string) (model.User, error) {
+	for _, user := range f.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return model.User{}, model.ErrUserNotFound
+}
+
+func (f *fakeUsers) Create(_ context.Context, user model.User) (model.User, error) {
+	user.ID = int64(len(f.users) + 1)
+	f.users[user.ID] = user
+	return user, nil
+}
+
+type fakeAccounts struct {
+	accounts.Repository
+	accounts []model.Account
+	err      error // Returned by Create
+}
+
+// Create fails like the unique indexes on (provider, "providerAccountId") and ("userId", provider)
+func (f *fakeAccounts) Create(_ context.Context, account model.Account) (model.Account, error) {
+	if f.err != nil {
+		return model.Account{}, f.err
+	}
+	for _, a := range f.accounts {
+		if a.Provider == account.Provider && (a.ProviderAccountID == account.ProviderAccountID || a.UserID == account.UserID) {
+			return model.Account{}, errors.New("duplicate key value violates unique constraint")
+		}
+	}
+	f.accounts = append(f.accounts, account)
+	return account, nil
+}
+
+func (f *fakeAccounts) GetByUserID(_ context.Context, userID int64) ([]model.Account, error) {
+	var out []model.Account
+	for _, a := range f.accounts {
+		if a.UserID == userID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeAccounts) DeleteByUserID(_ context.Context, userID int64, provider model.Provider) error {
+	f.accounts = slices.DeleteFunc(f.accounts, func(a model.Account) bool {
+		return a.UserID == userID && a.Provider == provider
+	})
+	return nil
+}
+
+func (f *fakeAccounts) GetByProvider(_ context.Context, provider model.Provider, providerAccountID string) (model.Account, error) {
+	for _, a := range f.accounts {
+		if a.Provider == provider && a.ProviderAccountID == providerAccountID {
+			return a, nil
+		}
+	}
+	return model.Account{}, accounts.ErrNotFound
+}
+
+type fakeCredentials struct {
+	webauthncredentials.Repository
+	credentials []model.WebAuthnCredential
+}
+
+func (f *fakeCredentials) GetByCredentialID(_ context.Context, credentialID []byte) (model.WebAuthnCredential, error) {
+	for _, c := range f.credentials {
+		if bytes.Equal(c.CredentialID, credentialID) {
+			return c, nil
+		}
+	}
+	return model.WebAuthnCredential{}, webauthncredentials.ErrNotFound
+}
+
+func (f *fakeCredentials) ListByUserID(_ context.Context, userID int64) ([]model.WebAuthnCredential, error) {
+	var out []model.WebAuthnCredential
+	for _, c := range f.credentials {
+		if c.UserID == userID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeCredentials) Delete(_ context.Context, userID, id int64) error {
+	f.credentials = slices.DeleteFunc(f.credentials, func(c model.WebAuthnCredential) bool {
+		return c.UserID == userID && c.ID == id
+	})
+	return nil
+}
+
+func (f *fakeCredentials) UpdateAfterLogin(_ context.Context, credential model.WebAuthnCredential, _ time.Time) error {
+	for i, c := range f.credentials {
+		if c.ID == credential.ID {
+			f.credentials[i] = credential
+		}
+	}
+	return nil
+}
+
+type fakeMFA struct {
+	mfa.Repository
+	mfa map[int64]model.MFA
+}
+
+func (f fakeMFA) Get(_ context.Context, userID int64) (model.MFA, error) {
+	m, ok := f.mfa[userID]
+	if !ok {
+		return model.MFA{}, mfa.ErrNotFound
+	}
+	return m, nil
+}
+
+type fakeVerificationTokens struct {
+	verificationtokens.Repository
+	tokens map[string]model.VerificationToken
+}
+
+func (f fakeVerificationTokens) Create(_ context.Context, token model.VerificationToken) (model.VerificationToken, error) {
+	f.tokens[token.Identifier+":"+token.Token] = token
+	return token, nil
+}
+
+func (f fakeVerificationTokens) DeleteByIdentifier(_ context.Context, identifier string) error {
+	for key, token := range f.tokens {
+		if token.Identifier == identifier {
+			delete(f.tokens, key)
+		}
+	}
+	return nil
+}
+
+func (f fakeVerificationTokens) Consume(_ context.Context, identifier, token string) (model.VerificationToken, error) {
+	stored, ok := f.tokens[identifier+":"+token]
+	if !ok {
+		return model.VerificationToken{}, verificationtokens.ErrNotFound
+	}
+	delete(f.tokens, identifier+":"+token)
+	return stored, nil
+}
+
+type fakeSessions struct {
+	sessions.Repository
+	sessions []model.Session
+}
+
+func (f *fakeSessions) Create(_ context.Context, session model.Session) (model.Session, error) {
+	session.ID = int64(len(f.sessions) + 1)
+	f.sessions = append(f.sessions, session)
+	return session, nil
+}
+
+type fakeRoles struct {
+	roles.Repository
+}
+
+func (fakeRoles) ListByUserID(context.Context, int64) ([]model.Role, error) {
+	return []model.Role{{Name: model.RoleUser}}, nil
+}
+
+func (fakeRoles) AssignToUser(context.Context, int64, string) error {
+	return nil
+}
+
+func (fakeRoles) ListPermissionsByUserID(context.Context, int64) ([]string, error) {
+	return []string{model.PermissionProfileRead}, nil
+}
+
+// fakeMailer records the messages sent
+type fakeMailer struct {
+	sent *[]mailer.Message
+}
+
+func (f fakeMailer) Send(_ context.Context, msg mailer.Message) error {
+	*f.sent = append(*f.sent, msg)
+	return nil
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	repoLoginAttempts "github.com/namf2001/go-backend-template/internal/repository/loginattempts"
+	pkgerrors "github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// LockedError is returned when logins are refused for a while after too many failed attempts
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%v, retry after %s", ErrAccountLocked, e.RetryAfter)
+}
+
+// Unwrap lets errors.Is match ErrAccountLocked
+func (e *LockedError) Unwrap() error {
+	return ErrAccountLocked
+}
+
+// lockoutPolicy decides how failed logins are throttled. Once an account or an IP has delayAfter
+// recent failures, each further attempt must wait a delay doubling from baseDelay up to maxDelay.
+// At maxAttempts failures it is locked out for lockoutDuration.
+type lockoutPolicy struct {
+	window          time.Duration // Failures older than this are forgotten
+	delayAfter      int
+	baseDelay       time.Duration
+	maxDelay        time.Duration
+	maxAttempts     int // Per account
+	ipMaxAttempts   int // Per IP, higher since many users can share one
+	lockoutDuration time.Duration
+}
+
+func newLockoutPolicy() lockoutPolicy {
+	return lockoutPolicy{
+		window:          durationFromConfig("LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
+		delayAfter:      intFromConfig("LOGIN_DELAY_AFTER", 3),
+		baseDelay:       durationFromConfig("LOGIN_DELAY_BASE", time.Second),
+		maxDelay:        durationFromConfig("LOGIN_DELAY_MAX", 30*time.Second),
+		maxAttempts:     intFromConfig("LOGIN_MAX_ATTEMPTS", 10),
+		ipMaxAttempts:   intFromConfig("LOGIN_IP_MAX_ATTEMPTS", 50),
+		lockoutDuration: durationFromConfig("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+	}
+}
+
+func intFromConfig(key string, def int) int {
+	if v := config.GetConfig().GetInt(key); v > 0 {
+		return v
+	}
+	return def
+}
+
+// delay returns how long to wait after the last failure before the next attempt
+func (p lockoutPolicy) delay(failures int) time.Duration {
+	if failures < p.delayAfter {
+		return 0
+	}
+	d := time.Duration(float64(p.baseDelay) * math.Pow(2, float64(failures-p.delayAfter)))
+	if d > p.maxDelay || d <= 0 {
+		return p.maxDelay
+	}
+	return d
+}
+
+// loginKeys returns the keys failed logins are counted under
+func loginKeys(email, ip string) (accountKey, ipKey string) {
+	accountKey = "account:" + strings.ToLower(strings.TrimSpace(email))
+	if ip != "" {
+		ipKey = "ip:" + ip
+	}
+	return accountKey, ipKey
+}
+
+// checkLockout returns a LockedError if the account or the IP must not attempt to log in yet
+func (i impl) checkLockout(ctx context.Context, now time.Time, keys ...string) error {
+	var retryAfter time.Duration
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+
+		attempt, err := i.attempts.Get(ctx, key)
+		if errors.Is(err, repoLoginAttempts.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		var wait time.Duration
+		switch {
+		case attempt.IsLocked(now):
+			wait = attempt.LockedUntil.Sub(now)
+		case attempt.LastFailureAt.After(now.Add(-i.lockout.window)):
+			wait = attempt.LastFailureAt.Add(i.lockout.delay(attempt.Failures)).Sub(now)
+		}
+		if wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		logSecurityEvent(ctx, "login_throttled", keys, zap.Duration("retry_after", retryAfter.Round(time.Second)))
+		return pkgerrors.WithStack(&LockedError{RetryAfter: retryAfter})
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login and locks out keys reaching their limit
+func (i impl) recordLoginFailure(ctx context.Context, now time.Time, accountKey, ipKey string) error {
+	limits := map[string]int{accountKey: i.lockout.maxAttempts}
+	if ipKey != "" {
+		limits[ipKey] = i.lockout.ipMaxAttempts
+	}
+
+	for key, limit := range limits {
+		attempt, err := i.attempts.RecordFailure(ctx, key, now, now.Add(-i.lockout.window))
+		if err != nil {
+			return err
+		}
+
+		logSecurityEvent(ctx, "login_failed", []string{key}, zap.Int("failures", attempt.Failures))
+		if attempt.Failures < limit {
+			continue
+		}
+
+		until := now.Add(i.lockout.lockoutDuration)
+		if err := i.attempts.Lock(ctx, key, until); err != nil {
+			return err
+		}
+		logSecurityEvent(ctx, "login_locked", []string{key}, zap.Int("failures", attempt.Failures), zap.Time("locked_until", until))
+	}
+
+	return nil
+}
+
+// logSecurityEvent logs a security relevant event
+func logSecurityEvent(ctx context.Context, event string, keys []string, fields ...zap.Field) {
+	fields = append([]zap.Field{zap.String("security_event", event), zap.Strings("keys", nonEmpty(keys))}, fields...)
+	logger.Info(ctx, "security event", fields...)
+}
+
+func nonEmpty(items []string) []string {
+	var out []string
+	for _, item := range items {
+		if item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
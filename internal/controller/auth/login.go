@@ -2,34 +2,142 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/namf2001/go-backend-template/internal/model"
 	"github.com/namf2001/go-backend-template/internal/pkg/jwt"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	"github.com/namf2001/go-backend-template/internal/pkg/password"
 	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	repoMFA "github.com/namf2001/go-backend-template/internal/repository/mfa"
+	"github.com/namf2001/go-backend-template/internal/repository/users"
+	pkgerrors "github.com/pkg/errors"
+	"go.uber.org/zap"
 )
 
 type ValidationInput struct {
 	Email    string
 	Password string
+	IP       string // Client IP, failed attempts are also counted per IP
 }
 
 // Login performs manual login
-func (i impl) Login(ctx context.Context, input ValidationInput) (string, error) {
-	// 1. Get user by email
+func (i impl) Login(ctx context.Context, input ValidationInput) (tokens Tokens, err error) {
+	defer func() { recordLogin(metrics.MethodPassword, tokens, err) }()
+
+	now := time.Now()
+	accountKey, ipKey := loginKeys(input.Email, input.IP)
+
+	// 1. Refuse early while the account or the IP is throttled
+	if err := i.checkLockout(ctx, now, accountKey, ipKey); err != nil {
+		return Tokens{}, err
+	}
+
+	// 2. Get user by email
 	user, err := i.repo.User().GetByEmail(ctx, input.Email)
 	if err != nil {
-		return "", err
+		// Unknown emails count and take as long as wrong passwords, so they cannot be told apart
+		if errors.Is(err, model.ErrUserNotFound) {
+			_ = i.passwords.Verify(i.dummyHash(), input.Password)
+			if recordErr := i.recordLoginFailure(ctx, now, accountKey, ipKey); recordErr != nil {
+				return Tokens{}, recordErr
+			}
+		}
+		return Tokens{}, err
+	}
+
+	// 3. Validate password. Accounts without one, e.g. OAuth only, are checked against the dummy hash to take as long.
+	hash := user.Password
+	if hash == "" {
+		hash = i.dummyHash()
+	}
+	if err := i.passwords.Verify(hash, input.Password); err != nil {
+		if recordErr := i.recordLoginFailure(ctx, now, accountKey, ipKey); recordErr != nil {
+			return Tokens{}, recordErr
+		}
+		return Tokens{}, err
+	}
+
+	// The IP is not reset, one valid account must not unlock guessing on others
+	if err := i.attempts.Reset(ctx, accountKey); err != nil {
+		return Tokens{}, pkgerrors.WithStack(err)
+	}
+
+	// 4. Upgrade the hash while the password is at hand
+	if i.passwords.NeedsRehash(user.Password) {
+		i.rehashPassword(ctx, user, input.Password)
 	}
 
-	// 2. Validate password
-	if err := utils.VerifyPassword(user.Password, input.Password); err != nil {
-		return "", err
+	// 5. Issue tokens
+	return i.completeLogin(ctx, user)
+}
+
+// newDummyHash returns the hash of a random password, computed on first use with the current algorithm and
+// parameters. Logins verify against it when there is no hash to check, so they take as long as with one.
+func newDummyHash(passwords password.Hasher) func() string {
+	return sync.OnceValue(func() string {
+		secret, err := utils.GenerateRandomToken(16)
+		if err == nil {
+			var hash string
+			if hash, err = passwords.Hash(secret); err == nil {
+				return hash
+			}
+		}
+		logger.Error(context.Background(), "dummy password hash failed", zap.Error(err))
+		return ""
+	})
+}
+
+// recordLogin counts a login by method and result
+func recordLogin(method string, tokens Tokens, err error) {
+	result := metrics.ResultSuccess
+	switch {
+	case errors.Is(err, ErrAccountLocked):
+		result = metrics.ResultLocked
+	case err != nil:
+		result = metrics.ResultFailure
+	case tokens.MFAToken != "":
+		result = metrics.ResultMFARequired
 	}
+	metrics.AuthLogins.WithLabelValues(method, result).Inc()
+}
 
-	// 3. Generate Token
-	token, err := jwt.GenerateToken(user.ID, user.Email)
+// rehashPassword replaces an outdated password hash with one of the current algorithm and parameters.
+// Failing to do so does not fail the login, it is tried again on the next one.
+func (i impl) rehashPassword(ctx context.Context, user model.User, plain string) {
+	hash, err := i.passwords.Hash(plain)
+	if err == nil {
+		err = i.repo.User().UpdatePassword(ctx, user.ID, user.Password, hash)
+	}
 	if err != nil {
-		return "", err
+		// ErrNotFound: the password was changed since it was read, the new hash is already current
+		if !errors.Is(err, users.ErrNotFound) {
+			logger.Error(ctx, "rehash password failed", zap.Int64(logger.FieldUserID, user.ID), zap.Error(err))
+		}
+		return
+	}
+
+	logSecurityEvent(ctx, "password_rehashed", []string{"user:" + strconv.FormatInt(user.ID, 10)})
+}
+
+// completeLogin issues tokens once the first factor of a user was checked, or an mfa_pending
+// token if the user enabled a second factor
+func (i impl) completeLogin(ctx context.Context, user model.User) (Tokens, error) {
+	mfa, err := i.repo.MFA().Get(ctx, user.ID)
+	if err != nil && !errors.Is(err, repoMFA.ErrNotFound) {
+		return Tokens{}, err
+	}
+	if err == nil && mfa.IsEnabled() {
+		mfaToken, err := jwt.GenerateMFAToken(user.ID)
+		if err != nil {
+			return Tokens{}, err
+		}
+		return Tokens{MFAToken: mfaToken, ExpiresIn: jwt.MFADuration()}, nil
 	}
 
-	return token, nil
+	return issueTokens(ctx, i.repo, user, "")
 }
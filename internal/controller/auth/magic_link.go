@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/namf2001/go-backend-template/internal/pkg/mailer"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	repoLoginAttempts "github.com/namf2001/go-backend-template/internal/repository/loginattempts"
+	pkgerrors "github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	purposeMagicLink         = "magic-link"
+	defaultMagicLinkTTL      = 15 * time.Minute
+	defaultMagicLinkPath     = "/api/v1/auth/magic-link/callback"
+	defaultMagicLinkWindow   = 15 * time.Minute
+	defaultMagicLinkRequests = 3
+)
+
+// magicLinkPolicy limits how many login links can be requested for an email within a window
+type magicLinkPolicy struct {
+	ttl         time.Duration // How long a link is valid
+	window      time.Duration
+	maxRequests int
+}
+
+func newMagicLinkPolicy() magicLinkPolicy {
+	return magicLinkPolicy{
+		ttl:         durationFromConfig("MAGIC_LINK_TTL", defaultMagicLinkTTL),
+		window:      durationFromConfig("MAGIC_LINK_WINDOW", defaultMagicLinkWindow),
+		maxRequests: intFromConfig("MAGIC_LINK_MAX_REQUESTS", defaultMagicLinkRequests),
+	}
+}
+
+// RequestMagicLink emails a one-time login link if the email belongs to a user. Requests are limited
+// per email whether or not it is registered, so neither the limit nor the response reveal accounts.
+func (i impl) RequestMagicLink(ctx context.Context, email string) error {
+	if err := i.throttleMagicLink(ctx, email, time.Now()); err != nil {
+		return err
+	}
+
+	// Do the work in the background so the response time does not depend on whether the user exists
+	go func(ctx context.Context) {
+		if err := i.sendMagicLink(ctx, email); err != nil {
+			logger.Error(ctx, "send magic link failed", zap.Error(err))
+		}
+	}(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+// MagicLinkLogin consumes a login link and logs its user in. Opening the link proves the user owns
+// the email, so it is marked as verified.
+func (i impl) MagicLinkLogin(ctx context.Context, email, token string) (tokens Tokens, err error) {
+	defer func() { recordLogin(metrics.MethodMagicLink, tokens, err) }()
+
+	if err := i.consumeVerificationToken(ctx, purposeMagicLink, email, token); err != nil {
+		return Tokens{}, err
+	}
+
+	user, err := i.repo.User().GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return Tokens{}, pkgerrors.WithStack(ErrInvalidVerificationToken)
+		}
+		return Tokens{}, err
+	}
+
+	if user.EmailVerified == nil {
+		now := time.Now()
+		user.EmailVerified = &now
+		if err := i.repo.User().Update(ctx, user); err != nil {
+			return Tokens{}, err
+		}
+	}
+
+	return i.completeLogin(ctx, user)
+}
+
+// throttleMagicLink counts a link request for the email and refuses it once the email reached
+// the maximum number of requests within the window
+func (i impl) throttleMagicLink(ctx context.Context, email string, now time.Time) error {
+	key := "magic-link:" + strings.ToLower(strings.TrimSpace(email))
+	window := i.magicLink.window
+
+	attempt, err := i.attempts.Get(ctx, key)
+	if err != nil && !errors.Is(err, repoLoginAttempts.ErrNotFound) {
+		return err
+	}
+	if err == nil && attempt.Failures >= i.magicLink.maxRequests && attempt.LastFailureAt.After(now.Add(-window)) {
+		logSecurityEvent(ctx, "magic_link_throttled", []string{key}, zap.Int("requests", attempt.Failures))
+		return pkgerrors.WithStack(&LockedError{RetryAfter: attempt.LastFailureAt.Add(window).Sub(now)})
+	}
+
+	_, err = i.attempts.RecordFailure(ctx, key, now, now.Add(-window))
+	return err
+}
+
+func (i impl) sendMagicLink(ctx context.Context, email string) error {
+	user, err := i.repo.User().GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	ttl := i.magicLink.ttl
+	token, err := i.issueVerificationToken(ctx, purposeMagicLink, user.Email, ttl)
+	if err != nil {
+		return err
+	}
+
+	linkURL := config.GetConfig().GetString("MAGIC_LINK_URL")
+	if linkURL == "" {
+		linkURL = defaultMagicLinkPath
+	}
+
+	link := buildLink(linkURL, url.Values{"email": {user.Email}, "token": {token}})
+	return i.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen the link below to sign in:\n\n%s\n\n"+
+			"The link expires in %s and can be used once. If you did not ask for this, you can ignore this email.\n", user.Name, link, ttl),
+	})
+}
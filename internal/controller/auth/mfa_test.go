@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRecoveryCode(t *testing.T) {
+	seen := map[string]bool{}
+	for j := 0; j < 20; j++ {
+		code, err := generateRecoveryCode()
+		require.NoError(t, err)
+		require.Regexp(t, regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`), code)
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	type args struct {
+		given string
+		exp   string
+	}
+	tcs := map[string]args{
+		"as displayed": {
+			given: "abcde-fghij",
+			exp:   "abcdefghij",
+		},
+		"upper case without dash": {
+			given: " ABCDEFGHIJ ",
+			exp:   "abcdefghij",
+		},
+		"spaces": {
+			given: "abcde fghij",
+			exp:   "abcdefghij",
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			require.Equal(t, tc.exp, normalizeRecoveryCode(tc.given))
+		})
+	}
+}
@@ -2,10 +2,14 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/namf2001/go-backend-template/internal/model"
-	"github.com/namf2001/go-backend-template/internal/pkg/jwt"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	"github.com/namf2001/go-backend-template/internal/repository"
+	repoAccounts "github.com/namf2001/go-backend-template/internal/repository/accounts"
+	pkgerrors "github.com/pkg/errors"
 )
 
 // OAuthInput is the input for OAuth login
@@ -28,46 +32,106 @@ type OAuthInput struct {
 }
 
 // OAuthLogin handles oauth login/registration
-func (i impl) OAuthLogin(ctx context.Context, input OAuthInput) (string, error) {
+func (i impl) OAuthLogin(ctx context.Context, input OAuthInput) (tokens Tokens, err error) {
+	defer func() { recordLogin(metrics.MethodOAuth, tokens, err) }()
+
 	// 1. Check if account already linked
 	account, err := i.repo.Account().GetByProvider(ctx, input.Provider, input.ProviderAccountID)
-	if err == nil {
-		// Account exists → get user and return token
+	switch {
+	case err == nil:
+		// Account exists → get user and log them in, the provider is their first factor
 		user, err := i.repo.User().GetByID(ctx, account.UserID)
 		if err != nil {
-			return "", err
+			return Tokens{}, err
 		}
 
-		return jwt.GenerateToken(user.ID, user.Email)
+		return i.completeLogin(ctx, user)
+	case !errors.Is(err, repoAccounts.ErrNotFound):
+		return Tokens{}, err
 	}
 
-	// 2. Account not linked yet → find or create user
-	user, err := i.repo.User().GetByEmail(ctx, input.Email)
-	switch {
-	case err == ErrUserNotFound:
-		// User doesn't exist → create new user
-		newUser := model.User{
-			Name:  input.Name,
-			Email: input.Email,
-			Image: input.Image,
-		}
-		if input.EmailVerified {
+	// 2. Account not linked yet → find or create user and link the account in a single transaction,
+	// so a failure cannot leave a user without its role or its account
+	var user model.User
+	created := false
+	err = i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		var txErr error
+		user, txErr = txRepo.User().GetByEmail(ctx, input.Email)
+		switch {
+		case errors.Is(txErr, model.ErrUserNotFound):
+			// User doesn't exist → create new user. Taking an unverified email would let anyone claim the address
+			// before its owner signs up, and keep signing in through the provider once the owner takes the user over.
+			if !input.EmailVerified {
+				return pkgerrors.WithStack(ErrOAuthEmailNotVerified)
+			}
 			now := time.Now()
-			newUser.EmailVerified = &now
+			newUser := model.User{
+				Name:          input.Name,
+				Email:         input.Email,
+				Image:         input.Image,
+				EmailVerified: &now,
+			}
+
+			if user, txErr = txRepo.User().Create(ctx, newUser); txErr != nil {
+				return txErr
+			}
+			if txErr = txRepo.Role().AssignToUser(ctx, user.ID, model.RoleUser); txErr != nil {
+				return txErr
+			}
+			created = true
+		case txErr != nil:
+			// Unexpected error
+			return txErr
+		case !input.EmailVerified:
+			// Linking on an unverified email would let anyone claiming the address take over the user,
+			// the user has to sign in and link the provider from their profile instead
+			return pkgerrors.WithStack(ErrOAuthEmailNotVerified)
 		}
 
-		user, err = i.repo.User().Create(ctx, newUser)
-		if err != nil {
-			return "", err
+		// 3. Link account to user
+		_, txErr = txRepo.Account().Create(ctx, newAccount(user.ID, input))
+		return txErr
+	}, nil)
+	if err != nil {
+		return Tokens{}, err
+	}
+	if created {
+		metrics.AuthRegistrations.WithLabelValues(metrics.MethodOAuth, metrics.ResultSuccess).Inc()
+	}
+
+	return i.completeLogin(ctx, user)
+}
+
+// LinkAccount links a provider account to a signed in user
+func (i impl) LinkAccount(ctx context.Context, userID int64, input OAuthInput) (model.Account, error) {
+	account, err := i.repo.Account().GetByProvider(ctx, input.Provider, input.ProviderAccountID)
+	switch {
+	case err == nil:
+		if account.UserID != userID {
+			return model.Account{}, pkgerrors.WithStack(ErrAccountAlreadyLinked)
+		}
+		// Already linked to this user
+		return account, nil
+	case !errors.Is(err, repoAccounts.ErrNotFound):
+		return model.Account{}, err
+	}
+
+	accounts, err := i.repo.Account().GetByUserID(ctx, userID)
+	if err != nil {
+		return model.Account{}, err
+	}
+	for _, a := range accounts {
+		if a.Provider == input.Provider {
+			return model.Account{}, pkgerrors.WithStack(ErrProviderAlreadyLinked)
 		}
-	case err != nil:
-		// Unexpected error
-		return "", err
 	}
 
-	// 3. Link account to user
-	newAccount := model.Account{
-		UserID:            user.ID,
+	return i.repo.Account().Create(ctx, newAccount(userID, input))
+}
+
+func newAccount(userID int64, input OAuthInput) model.Account {
+	return model.Account{
+		UserID:            userID,
 		Type:              input.Type,
 		Provider:          input.Provider,
 		ProviderAccountID: input.ProviderAccountID,
@@ -79,10 +143,4 @@ func (i impl) OAuthLogin(ctx context.Context, input OAuthInput) (string, error)
 		SessionState:      input.SessionState,
 		TokenType:         input.TokenType,
 	}
-
-	if _, err = i.repo.Account().Create(ctx, newAccount); err != nil {
-		return "", err
-	}
-
-	return jwt.GenerateToken(user.ID, user.Email)
 }
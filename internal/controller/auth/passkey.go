@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	"github.com/namf2001/go-backend-template/internal/pkg/passkey"
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	"github.com/namf2001/go-backend-template/internal/repository"
+	repoAccounts "github.com/namf2001/go-backend-template/internal/repository/accounts"
+	repoVerificationTokens "github.com/namf2001/go-backend-template/internal/repository/verificationtokens"
+	repoWebAuthn "github.com/namf2001/go-backend-template/internal/repository/webauthncredentials"
+	pkgerrors "github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	purposePasskeyRegister = "passkey-register"
+	purposePasskeyLogin    = "passkey-login"
+	defaultPasskeyName     = "Passkey"
+)
+
+// BeginPasskeyRegistration returns the options to create a passkey. The challenge is stored as a
+// single use verification token bound to the user, it is looked up again from the response.
+func (i impl) BeginPasskeyRegistration(ctx context.Context, userID int64) (*protocol.CredentialCreation, error) {
+	user, err := i.passkeyUser(ctx, i.repo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ask the authenticator not to create a second passkey for the same account
+	exclusions := webauthn.Credentials(user.WebAuthnCredentials()).CredentialDescriptors()
+	creation, session, err := i.webauthn.BeginRegistration(user, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+
+	if err := i.storeCeremony(ctx, purposePasskeyRegister, userID, session); err != nil {
+		return nil, err
+	}
+
+	return creation, nil
+}
+
+// FinishPasskeyRegistration verifies the attestation of a new passkey and stores it under the user's webauthn account
+func (i impl) FinishPasskeyRegistration(ctx context.Context, userID int64, name string, response *protocol.ParsedCredentialCreationData) (model.WebAuthnCredential, error) {
+	challenge := response.Response.CollectedClientData.Challenge
+	if err := i.consumeCeremony(ctx, purposePasskeyRegister, userID, challenge); err != nil {
+		return model.WebAuthnCredential{}, err
+	}
+
+	if name == "" {
+		name = defaultPasskeyName
+	}
+
+	var created model.WebAuthnCredential
+	err := i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		user, err := i.passkeyUser(ctx, txRepo, userID)
+		if err != nil {
+			return err
+		}
+
+		credential, err := i.webauthn.CreateCredential(user, i.ceremonySession(challenge, user.WebAuthnID(), true), response)
+		if err != nil {
+			return invalidPasskey(err)
+		}
+
+		account, err := webAuthnAccount(ctx, txRepo, userID)
+		if err != nil {
+			return err
+		}
+
+		stored := passkey.FromCredential(*credential)
+		stored.AccountID = account.ID
+		stored.UserID = userID
+		stored.Name = name
+		if created, err = txRepo.WebAuthnCredential().Create(ctx, stored); err != nil {
+			if errors.Is(err, repoWebAuthn.ErrAlreadyRegistered) {
+				return pkgerrors.WithStack(ErrPasskeyAlreadyRegistered)
+			}
+			return err
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return model.WebAuthnCredential{}, err
+	}
+
+	logSecurityEvent(ctx, "passkey_registered", []string{"user:" + strconv.FormatInt(userID, 10)}, zap.Int64("passkey_id", created.ID))
+	return created, nil
+}
+
+// BeginPasskeyLogin returns the options of a discoverable login, the user is only known from the response
+func (i impl) BeginPasskeyLogin(ctx context.Context) (*protocol.CredentialAssertion, error) {
+	assertion, session, err := i.webauthn.BeginDiscoverableLogin()
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+
+	if err := i.storeCeremony(ctx, purposePasskeyLogin, 0, session); err != nil {
+		return nil, err
+	}
+
+	return assertion, nil
+}
+
+// FinishPasskeyLogin verifies the assertion of a passkey and issues tokens for its user. A sign counter
+// that did not increase means two authenticators hold the same key, the login is refused.
+// A passkey verifying the user with a PIN or biometrics proves two factors, so no second factor is asked.
+// Without user verification it only proves possession, and users who enabled MFA are asked for their code.
+func (i impl) FinishPasskeyLogin(ctx context.Context, response *protocol.ParsedCredentialAssertionData) (tokens Tokens, err error) {
+	defer func() { recordLogin(metrics.MethodPasskey, tokens, err) }()
+
+	challenge := response.Response.CollectedClientData.Challenge
+	if err := i.consumeCeremony(ctx, purposePasskeyLogin, 0, challenge); err != nil {
+		return Tokens{}, err
+	}
+
+	var user passkey.User
+	findUser := func(rawID, userHandle []byte) (webauthn.User, error) {
+		userID, ok := passkey.UserIDFromHandle(userHandle)
+		if !ok {
+			return nil, pkgerrors.WithStack(ErrInvalidPasskey)
+		}
+
+		var err error
+		user, err = i.passkeyUser(ctx, i.repo, userID)
+		return user, err
+	}
+
+	credential, err := i.webauthn.ValidateDiscoverableLogin(findUser, i.ceremonySession(challenge, nil, false), response)
+	if err != nil {
+		return Tokens{}, invalidPasskey(err)
+	}
+
+	userKeys := []string{"user:" + strconv.FormatInt(user.User.ID, 10)}
+	if credential.Authenticator.CloneWarning {
+		logSecurityEvent(ctx, "passkey_clone_detected", userKeys, zap.Uint32("sign_count", credential.Authenticator.SignCount))
+		return Tokens{}, pkgerrors.WithStack(ErrPasskeyCloned)
+	}
+
+	stored, err := i.repo.WebAuthnCredential().GetByCredentialID(ctx, credential.ID)
+	if err != nil {
+		return Tokens{}, err
+	}
+	stored.SignCount = credential.Authenticator.SignCount
+	stored.BackupState = credential.Flags.BackupState
+
+	// The counter is compared again in the update so two concurrent logins cannot both pass
+	if err := i.repo.WebAuthnCredential().UpdateAfterLogin(ctx, stored, time.Now()); err != nil {
+		if errors.Is(err, repoWebAuthn.ErrSignCountNotIncreased) {
+			logSecurityEvent(ctx, "passkey_clone_detected", userKeys, zap.Uint32("sign_count", stored.SignCount))
+			return Tokens{}, pkgerrors.WithStack(ErrPasskeyCloned)
+		}
+		return Tokens{}, err
+	}
+
+	if !response.Response.AuthenticatorData.Flags.UserVerified() {
+		return i.completeLogin(ctx, user.User)
+	}
+	return issueTokens(ctx, i.repo, user.User, "")
+}
+
+// ListPasskeys lists the passkeys of a user
+func (i impl) ListPasskeys(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error) {
+	return i.repo.WebAuthnCredential().ListByUserID(ctx, userID)
+}
+
+// DeletePasskey deletes a passkey of a user. Deleting the last one unlinks the webauthn account,
+// which is refused if it is the user's last way to sign in.
+func (i impl) DeletePasskey(ctx context.Context, userID, id int64) error {
+	return i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		credentials, err := txRepo.WebAuthnCredential().ListByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, c := range credentials {
+			if c.ID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return pkgerrors.WithStack(ErrPasskeyNotFound)
+		}
+
+		if len(credentials) > 1 {
+			return txRepo.WebAuthnCredential().Delete(ctx, userID, id)
+		}
+
+		user, err := txRepo.User().GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		accounts, err := txRepo.Account().GetByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		// A password counts as a sign-in method, every linked provider as another one
+		signInMethods := 0
+		for _, a := range accounts {
+			if a.Provider != "" {
+				signInMethods++
+			}
+		}
+		if user.Password != "" {
+			signInMethods++
+		}
+		if signInMethods <= 1 {
+			return pkgerrors.WithStack(ErrLastSignInMethod)
+		}
+
+		// Deleting the account deletes its passkeys
+		return txRepo.Account().DeleteByUserID(ctx, userID, model.ProviderWebAuthn)
+	}, nil)
+}
+
+// passkeyUser loads a user with their passkeys
+func (i impl) passkeyUser(ctx context.Context, repo repository.Registry, userID int64) (passkey.User, error) {
+	user, err := repo.User().GetByID(ctx, userID)
+	if err != nil {
+		return passkey.User{}, err
+	}
+
+	credentials, err := repo.WebAuthnCredential().ListByUserID(ctx, userID)
+	if err != nil {
+		return passkey.User{}, err
+	}
+
+	return passkey.User{User: user, Credentials: credentials}, nil
+}
+
+// webAuthnAccount returns the webauthn account of the user, creating it with the first passkey
+func webAuthnAccount(ctx context.Context, repo repository.Registry, userID int64) (model.Account, error) {
+	providerAccountID := strconv.FormatInt(userID, 10)
+	account, err := repo.Account().GetByProvider(ctx, model.ProviderWebAuthn, providerAccountID)
+	if err == nil {
+		return account, nil
+	}
+	if !errors.Is(err, repoAccounts.ErrNotFound) {
+		return model.Account{}, err
+	}
+
+	return repo.Account().Create(ctx, model.Account{
+		UserID:            userID,
+		Type:              "webauthn",
+		Provider:          model.ProviderWebAuthn,
+		ProviderAccountID: providerAccountID,
+	})
+}
+
+// ceremonyIdentifier scopes a ceremony challenge to its purpose and, for registrations, to the user
+func ceremonyIdentifier(purpose string, userID int64, challenge string) string {
+	return verificationIdentifier(purpose, strconv.FormatInt(userID, 10)+":"+challenge)
+}
+
+// storeCeremony stores the challenge of a ceremony until it expires
+func (i impl) storeCeremony(ctx context.Context, purpose string, userID int64, session *webauthn.SessionData) error {
+	_, err := i.repo.VerificationToken().Create(ctx, model.VerificationToken{
+		Identifier: ceremonyIdentifier(purpose, userID, session.Challenge),
+		Expires:    session.Expires,
+		Token:      utils.HashToken(session.Challenge),
+	})
+	return err
+}
+
+// consumeCeremony uses up the challenge a response was signed for, so a response cannot be replayed
+func (i impl) consumeCeremony(ctx context.Context, purpose string, userID int64, challenge string) error {
+	consumed, err := i.repo.VerificationToken().Consume(ctx, ceremonyIdentifier(purpose, userID, challenge), utils.HashToken(challenge))
+	if err != nil {
+		if errors.Is(err, repoVerificationTokens.ErrNotFound) {
+			return pkgerrors.WithStack(ErrInvalidPasskey)
+		}
+		return err
+	}
+
+	if !consumed.Expires.After(time.Now()) {
+		return pkgerrors.WithStack(ErrInvalidPasskey)
+	}
+
+	return nil
+}
+
+// ceremonySession rebuilds the session data of a ceremony from its challenge, every other field
+// comes from the relying party configuration
+func (i impl) ceremonySession(challenge string, userHandle []byte, registration bool) webauthn.SessionData {
+	session := webauthn.SessionData{
+		Challenge:        challenge,
+		RelyingPartyID:   i.webauthn.Config.RPID,
+		UserID:           userHandle,
+		UserVerification: i.webauthn.Config.AuthenticatorSelection.UserVerification,
+	}
+	if registration {
+		session.CredParams = webauthn.CredentialParametersDefault()
+	}
+	return session
+}
+
+// invalidPasskey wraps a verification failure of the webauthn library
+func invalidPasskey(err error) error {
+	var protoErr *protocol.Error
+	if errors.As(err, &protoErr) && protoErr.DevInfo != "" {
+		err = fmt.Errorf("%v: %s", err, protoErr.DevInfo)
+	}
+	return pkgerrors.WithStack(fmt.Errorf("%w: %v", ErrInvalidPasskey, err))
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/namf2001/go-backend-template/internal/pkg/mailer"
+	"github.com/namf2001/go-backend-template/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	purposeResetPassword     = "reset-password"
+	defaultPasswordResetTTL  = time.Hour
+	defaultPasswordResetPath = "/reset-password"
+)
+
+// ResetPasswordInput is the input for resetting a password
+type ResetPasswordInput struct {
+	Email    string
+	Token    string
+	Password string
+}
+
+// ForgotPassword emails a password reset link if the email belongs to a user.
+// It behaves the same whether or not the email is registered, so it cannot be used to discover accounts.
+func (i impl) ForgotPassword(ctx context.Context, email string) error {
+	// Do the work in the background so the response time does not depend on whether the user exists
+	go func(ctx context.Context) {
+		if err := i.sendPasswordReset(ctx, email); err != nil {
+			logger.Error(ctx, "send password reset failed", zap.Error(err))
+		}
+	}(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+// ResetPassword consumes a password reset token, sets the new password and revokes every session of the user
+func (i impl) ResetPassword(ctx context.Context, input ResetPasswordInput) error {
+	return i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		txImpl := i
+		txImpl.repo = txRepo
+		if err := txImpl.consumeVerificationToken(ctx, purposeResetPassword, input.Email, input.Token); err != nil {
+			return err
+		}
+
+		user, err := txRepo.User().GetByEmail(ctx, input.Email)
+		if err != nil {
+			return err
+		}
+
+		// Checked once the token is, the link stays usable when the password is refused
+		if err := i.policy.Check(input.Password, user.Email, user.Name); err != nil {
+			return err
+		}
+
+		hashedPassword, err := i.passwords.Hash(input.Password)
+		if err != nil {
+			return err
+		}
+		user.Password = hashedPassword
+		if err := txRepo.User().Update(ctx, user); err != nil {
+			return err
+		}
+
+		// Drop any other pending reset links
+		if err := txRepo.VerificationToken().DeleteByIdentifier(ctx, verificationIdentifier(purposeResetPassword, user.Email)); err != nil {
+			return err
+		}
+
+		return txRepo.Session().RevokeByUserID(ctx, user.ID)
+	}, nil)
+}
+
+func (i impl) sendPasswordReset(ctx context.Context, email string) error {
+	user, err := i.repo.User().GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	ttl := durationFromConfig("PASSWORD_RESET_TTL", defaultPasswordResetTTL)
+	token, err := i.issueVerificationToken(ctx, purposeResetPassword, user.Email, ttl)
+	if err != nil {
+		return err
+	}
+
+	resetURL := config.GetConfig().GetString("PASSWORD_RESET_URL")
+	if resetURL == "" {
+		resetURL = defaultPasswordResetPath
+	}
+
+	link := buildLink(resetURL, url.Values{"email": {user.Email}, "token": {token}})
+	return i.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\n"+
+			"The link expires in %s. If you did not ask for this, you can ignore this email.\n", user.Name, link, ttl),
+	})
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	"github.com/namf2001/go-backend-template/internal/repository"
+	"github.com/namf2001/go-backend-template/internal/repository/sessions"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Refresh rotates a refresh token and issues a new token pair.
+// Presenting a refresh token that was already rotated revokes its whole family.
+func (i impl) Refresh(ctx context.Context, refreshToken string) (Tokens, error) {
+	hashedToken := utils.HashToken(refreshToken)
+
+	// 1. Look up the session
+	session, err := i.repo.Session().GetByToken(ctx, hashedToken)
+	if err != nil {
+		if errors.Is(err, sessions.ErrNotFound) {
+			return Tokens{}, pkgerrors.WithStack(ErrInvalidRefreshToken)
+		}
+		return Tokens{}, err
+	}
+
+	// 2. Detect reuse of a rotated token
+	if session.RevokedAt != nil {
+		return Tokens{}, i.revokeReusedFamily(ctx, session.FamilyID)
+	}
+
+	// Refresh tokens of OAuth clients are only rotated by the authorization server, within their scopes
+	if !session.IsActive(time.Now()) || session.ClientID != "" {
+		return Tokens{}, pkgerrors.WithStack(ErrInvalidRefreshToken)
+	}
+
+	user, err := i.repo.User().GetByID(ctx, session.UserID)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	// 3. Rotate: revoke the presented token and issue a new one in the same family
+	var tokens Tokens
+	err = i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		if txErr := txRepo.Session().Revoke(ctx, hashedToken); txErr != nil {
+			return txErr
+		}
+
+		var txErr error
+		tokens, txErr = issueTokens(ctx, txRepo, user, session.FamilyID)
+		return txErr
+	}, nil)
+	if err != nil {
+		if errors.Is(err, sessions.ErrNotFound) {
+			// Another request rotated the same token first
+			return Tokens{}, i.revokeReusedFamily(ctx, session.FamilyID)
+		}
+		return Tokens{}, err
+	}
+
+	return tokens, nil
+}
+
+// revokeReusedFamily revokes a token family after reuse was detected and returns ErrRefreshTokenReused
+func (i impl) revokeReusedFamily(ctx context.Context, familyID string) error {
+	if err := i.repo.Session().RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return pkgerrors.WithStack(ErrRefreshTokenReused)
+}
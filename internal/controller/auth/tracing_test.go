@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type stubController struct {
+	Controller
+	err error
+}
+
+func (s stubController) Login(ctx context.Context, input ValidationInput) (Tokens, error) {
+	return Tokens{AccessToken: "access"}, s.err
+}
+
+func TestTraced(t *testing.T) {
+	type args struct {
+		givenErr error
+		expCode  codes.Code
+	}
+	tcs := map[string]args{
+		"success":              {expCode: codes.Unset},
+		"err - user not found": {givenErr: ErrUserNotFound, expCode: codes.Error},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			spans, restore := tracing.NewInMemory()
+			t.Cleanup(restore)
+			ctrl := traced{next: stubController{err: tc.givenErr}}
+
+			// When
+			tokens, err := ctrl.Login(context.Background(), ValidationInput{})
+
+			// Then
+			require.Equal(t, tc.givenErr, err)
+			require.Equal(t, "access", tokens.AccessToken)
+			ended := spans.GetSpans()
+			require.Len(t, ended, 1)
+			require.Equal(t, "auth.Login", ended[0].Name)
+			require.Equal(t, tc.expCode, ended[0].Status.Code)
+		})
+	}
+}
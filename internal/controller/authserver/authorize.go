@@ -0,0 +1,145 @@
+package authserver
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	"github.com/namf2001/go-backend-template/internal/repository/oauthclients"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const (
+	responseTypeCode        = "code"
+	codeChallengeMethodS256 = "S256"
+)
+
+// AuthorizationRequest holds the parameters of an authorization request (RFC 6749 section 4.1.1, RFC 7636)
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string // Space separated, the client's scopes when empty
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// AuthorizeInput is the decision of a signed in user on an authorization request
+type AuthorizeInput struct {
+	UserID   int64
+	Request  AuthorizationRequest
+	Approved bool
+}
+
+// ValidateAuthorization implements Controller. It returns the request with its redirect URI and scope resolved.
+// ErrInvalidClient and ErrInvalidRedirectURI must be shown to the user, any other error can be sent to the
+// redirect URI of the returned request.
+func (i impl) ValidateAuthorization(ctx context.Context, req AuthorizationRequest) (AuthorizationRequest, model.OAuthClient, error) {
+	// The secret of confidential clients is only checked on the token endpoint
+	client, err := i.repo.OAuthClient().GetByClientID(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, oauthclients.ErrNotFound) {
+			return req, model.OAuthClient{}, pkgerrors.WithStack(ErrInvalidClient)
+		}
+		return req, model.OAuthClient{}, err
+	}
+
+	// Without a redirect URI known to belong to the client, errors cannot be redirected
+	if req.RedirectURI == "" && len(client.RedirectURIs) == 1 {
+		req.RedirectURI = client.RedirectURIs[0]
+	}
+	if !client.AllowsRedirectURI(req.RedirectURI) {
+		return req, client, pkgerrors.WithStack(ErrInvalidRedirectURI)
+	}
+
+	if req.ResponseType != responseTypeCode {
+		return req, client, pkgerrors.WithStack(ErrUnsupportedResponseType)
+	}
+	if !client.AllowsGrant(model.GrantTypeAuthorizationCode) {
+		return req, client, pkgerrors.WithStack(ErrUnauthorizedClient)
+	}
+
+	// PKCE is required from every client, plain challenges would leak the verifier
+	if req.CodeChallengeMethod != codeChallengeMethodS256 || req.CodeChallenge == "" {
+		return req, client, pkgerrors.WithStack(ErrPKCERequired)
+	}
+
+	scopes, err := grantScopes(client.Scopes, req.Scope)
+	if err != nil {
+		return req, client, err
+	}
+	req.Scope = strings.Join(scopes, " ")
+
+	return req, client, nil
+}
+
+// Authorize implements Controller. The returned URL is the client's redirect URI with either a code
+// or the error of the request.
+func (i impl) Authorize(ctx context.Context, input AuthorizeInput) (string, error) {
+	req, client, err := i.ValidateAuthorization(ctx, input.Request)
+	if err != nil {
+		return "", err
+	}
+
+	if !input.Approved {
+		return "", pkgerrors.WithStack(ErrAccessDenied)
+	}
+
+	code, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	if err := i.repo.OAuthCode().Create(ctx, model.OAuthAuthorizationCode{
+		CodeHash:      utils.HashToken(code),
+		ClientID:      client.ClientID,
+		UserID:        input.UserID,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        strings.Fields(req.Scope),
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     time.Now().Add(i.codeTTL),
+	}); err != nil {
+		return "", err
+	}
+
+	return RedirectURL(req.RedirectURI, url.Values{"code": {code}, "state": {req.State}}), nil
+}
+
+// RedirectURL adds the response parameters to the query of a redirect URI, keeping its own query
+func RedirectURL(redirectURI string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	q := u.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			q.Set(key, values[0])
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// grantScopes returns the requested scopes if the client may ask for all of them, every allowed scope
+// when none are requested
+func grantScopes(allowed []string, requested string) ([]string, error) {
+	scopes := strings.Fields(requested)
+	if len(scopes) == 0 {
+		return allowed, nil
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(allowed, scope) {
+			return nil, pkgerrors.WithStack(ErrInvalidScope)
+		}
+	}
+	return slices.Compact(slices.Sorted(slices.Values(scopes))), nil
+}
@@ -0,0 +1,159 @@
+package authserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	"github.com/namf2001/go-backend-template/internal/repository"
+	"github.com/namf2001/go-backend-template/internal/repository/oauthclients"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// CreateClientInput holds the metadata of a new OAuth client
+type CreateClientInput struct {
+	Name         string
+	RedirectURIs []string
+	GrantTypes   []string
+	Scopes       []string // Permissions the client may ask for
+	Confidential bool     // Confidential clients get a secret, public ones such as SPAs and mobile apps rely on PKCE
+	CreatedBy    int64
+}
+
+// NewClient is a freshly registered client. Secret is only available at creation, only its hash is stored.
+type NewClient struct {
+	model.OAuthClient
+	Secret string
+}
+
+// ClientCredentials are what a client authenticates with on the token, introspection and revocation endpoints
+type ClientCredentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// CreateClient implements Controller.
+func (i impl) CreateClient(ctx context.Context, input CreateClientInput) (NewClient, error) {
+	if err := validateClientMetadata(input); err != nil {
+		return NewClient{}, err
+	}
+
+	clientID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return NewClient{}, err
+	}
+
+	var secret, secretHash string
+	if input.Confidential {
+		if secret, err = utils.GenerateRandomToken(32); err != nil {
+			return NewClient{}, err
+		}
+		secretHash = utils.HashToken(secret)
+	}
+
+	client, err := i.repo.OAuthClient().Create(ctx, model.OAuthClient{
+		ClientID:     clientID,
+		SecretHash:   secretHash,
+		Name:         input.Name,
+		RedirectURIs: input.RedirectURIs,
+		GrantTypes:   input.GrantTypes,
+		Scopes:       input.Scopes,
+		CreatedBy:    &input.CreatedBy,
+	})
+	if err != nil {
+		return NewClient{}, err
+	}
+
+	return NewClient{OAuthClient: client, Secret: secret}, nil
+}
+
+// ListClients implements Controller.
+func (i impl) ListClients(ctx context.Context) ([]model.OAuthClient, error) {
+	return i.repo.OAuthClient().List(ctx)
+}
+
+// DeleteClient implements Controller.
+func (i impl) DeleteClient(ctx context.Context, id int64) error {
+	return i.repo.DoInTx(ctx, func(ctx context.Context, txRepo repository.Registry) error {
+		client, err := txRepo.OAuthClient().GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, oauthclients.ErrNotFound) {
+				return pkgerrors.WithStack(ErrClientNotFound)
+			}
+			return err
+		}
+
+		// Access tokens are bound to their session, revoking them stops the client right away
+		if err := txRepo.Session().RevokeByClientID(ctx, client.ClientID); err != nil {
+			return err
+		}
+
+		return txRepo.OAuthClient().Delete(ctx, id)
+	}, nil)
+}
+
+// authenticateClient checks the credentials of a client. Public clients have no secret to check.
+func (i impl) authenticateClient(ctx context.Context, credentials ClientCredentials) (model.OAuthClient, error) {
+	if credentials.ClientID == "" {
+		return model.OAuthClient{}, pkgerrors.WithStack(ErrInvalidClient)
+	}
+
+	client, err := i.repo.OAuthClient().GetByClientID(ctx, credentials.ClientID)
+	if err != nil {
+		if errors.Is(err, oauthclients.ErrNotFound) {
+			return model.OAuthClient{}, pkgerrors.WithStack(ErrInvalidClient)
+		}
+		return model.OAuthClient{}, err
+	}
+
+	if client.IsConfidential() {
+		hash := utils.HashToken(credentials.ClientSecret)
+		if credentials.ClientSecret == "" || subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash)) != 1 {
+			return model.OAuthClient{}, pkgerrors.WithStack(ErrInvalidClient)
+		}
+	}
+
+	return client, nil
+}
+
+// validateClientMetadata rejects clients that could not use their grants safely
+func validateClientMetadata(input CreateClientInput) error {
+	if input.Name == "" || len(input.GrantTypes) == 0 {
+		return pkgerrors.WithStack(ErrInvalidClientMetadata)
+	}
+
+	for _, grantType := range input.GrantTypes {
+		switch grantType {
+		case model.GrantTypeAuthorizationCode:
+			if len(input.RedirectURIs) == 0 {
+				return pkgerrors.Wrap(ErrInvalidClientMetadata, "the authorization code grant needs a redirect URI")
+			}
+		case model.GrantTypeRefreshToken:
+			if !slices.Contains(input.GrantTypes, model.GrantTypeAuthorizationCode) {
+				return pkgerrors.Wrap(ErrInvalidClientMetadata, "refresh tokens are only issued with the authorization code grant")
+			}
+		case model.GrantTypeClientCredentials:
+			// A public client cannot keep a secret, so it cannot prove it is itself
+			if !input.Confidential {
+				return pkgerrors.Wrap(ErrInvalidClientMetadata, "the client credentials grant needs a confidential client")
+			}
+		default:
+			return pkgerrors.Wrap(ErrInvalidClientMetadata, "unsupported grant type "+grantType)
+		}
+	}
+
+	for _, redirectURI := range input.RedirectURIs {
+		// Redirect URIs are stored space separated
+		u, err := url.Parse(redirectURI)
+		if err != nil || !u.IsAbs() || u.Fragment != "" || strings.ContainsAny(redirectURI, " \t\n") {
+			return pkgerrors.Wrap(ErrInvalidClientMetadata, "redirect URIs must be absolute and without fragment")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package authserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/jwt"
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
+	"github.com/namf2001/go-backend-template/internal/repository/sessions"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const tokenTypeBearer = "Bearer"
+
+// Introspection describes a token (RFC 7662 section 2.2). Only Active is set for inactive tokens.
+type Introspection struct {
+	Active    bool
+	Scopes    []string
+	ClientID  string
+	Username  string // Email of the user, empty for client tokens
+	TokenType string // Bearer for access tokens, empty for refresh tokens
+	Subject   string
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+	NotBefore time.Time
+	Issuer    string
+	Audience  []string
+	JTI       string
+}
+
+// Introspect implements Controller. Only confidential clients may introspect (RFC 7662 section 2.1), as anyone
+// can present the client_id of a public one. Only the tokens issued to the calling client are described,
+// those of other clients and first-party access tokens are reported inactive.
+func (i impl) Introspect(ctx context.Context, credentials ClientCredentials, token string) (Introspection, error) {
+	client, err := i.authenticateClient(ctx, credentials)
+	if err != nil {
+		return Introspection{}, err
+	}
+	if !client.IsConfidential() {
+		return Introspection{}, pkgerrors.WithStack(ErrInvalidClient)
+	}
+
+	if claims, err := jwt.ParseToken(token); err == nil {
+		if claims.ClientID != client.ClientID {
+			return Introspection{}, nil
+		}
+		active, err := i.repo.Session().IsFamilyActive(ctx, claims.SessionID)
+		if err != nil || !active {
+			return Introspection{}, err
+		}
+		return introspectClaims(claims), nil
+	}
+
+	if claims, err := jwt.ParseClientToken(token); err == nil {
+		if claims.ClientID != client.ClientID {
+			return Introspection{}, nil
+		}
+		return introspectClaims(claims), nil
+	}
+
+	session, err := i.repo.Session().GetByToken(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sessions.ErrNotFound) {
+			return Introspection{}, nil
+		}
+		return Introspection{}, err
+	}
+	if session.ClientID != client.ClientID || !session.IsActive(time.Now()) {
+		return Introspection{}, nil
+	}
+
+	return Introspection{
+		Active:    true,
+		Scopes:    session.Scopes,
+		ClientID:  session.ClientID,
+		ExpiresAt: session.Expires,
+		IssuedAt:  session.CreatedAt,
+	}, nil
+}
+
+// Revoke implements Controller. Revoking a refresh token or an access token revokes the whole grant.
+// Tokens of other clients and unknown tokens are ignored, as the client could not tell them apart.
+func (i impl) Revoke(ctx context.Context, credentials ClientCredentials, token string) error {
+	client, err := i.authenticateClient(ctx, credentials)
+	if err != nil {
+		return err
+	}
+
+	session, err := i.repo.Session().GetByToken(ctx, utils.HashToken(token))
+	if err != nil && !errors.Is(err, sessions.ErrNotFound) {
+		return err
+	}
+	if err == nil {
+		if session.ClientID != client.ClientID {
+			return nil
+		}
+		return i.repo.Session().RevokeFamily(ctx, session.FamilyID)
+	}
+
+	if claims, err := jwt.ParseToken(token); err == nil {
+		if claims.ClientID != client.ClientID {
+			return nil
+		}
+		return i.repo.Session().RevokeFamily(ctx, claims.SessionID)
+	}
+
+	// Client tokens are not stored, they can only expire
+	if claims, err := jwt.ParseClientToken(token); err == nil && claims.ClientID == client.ClientID {
+		return pkgerrors.WithStack(ErrUnsupportedTokenType)
+	}
+
+	return nil
+}
+
+func introspectClaims(claims *jwt.Claims) Introspection {
+	introspection := Introspection{
+		Active:    true,
+		Scopes:    claims.Scopes(),
+		ClientID:  claims.ClientID,
+		Username:  claims.Email,
+		TokenType: tokenTypeBearer,
+		Subject:   claims.Subject,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+		JTI:       claims.ID,
+	}
+	if claims.ExpiresAt != nil {
+		introspection.ExpiresAt = claims.ExpiresAt.Time
+	}
+	if claims.IssuedAt != nil {
+		introspection.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.NotBefore != nil {
+		introspection.NotBefore = claims.NotBefore.Time
+	}
+	return introspection
+}
@@ -0,0 +1,63 @@
+package authserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository"
+)
+
+const (
+	defaultCodeTTL         = time.Minute
+	defaultRefreshDuration = 30 * 24 * time.Hour
+)
+
+// Controller is the OAuth2 authorization server letting other apps get tokens for this service's users
+type Controller interface {
+	// CreateClient registers an OAuth client and returns its secret, shown only once
+	CreateClient(ctx context.Context, input CreateClientInput) (NewClient, error)
+
+	// ListClients lists every registered OAuth client
+	ListClients(ctx context.Context) ([]model.OAuthClient, error)
+
+	// DeleteClient deletes an OAuth client, its refresh tokens stop working
+	DeleteClient(ctx context.Context, id int64) error
+
+	// ValidateAuthorization checks an authorization request before the user is asked for consent
+	ValidateAuthorization(ctx context.Context, req AuthorizationRequest) (AuthorizationRequest, model.OAuthClient, error)
+
+	// Authorize records the user's decision on an authorization request and returns where to redirect them
+	Authorize(ctx context.Context, input AuthorizeInput) (string, error)
+
+	// Token issues tokens for the authorization_code, refresh_token and client_credentials grants
+	Token(ctx context.Context, req TokenRequest) (Tokens, error)
+
+	// Introspect describes a token to an authenticated client (RFC 7662)
+	Introspect(ctx context.Context, client ClientCredentials, token string) (Introspection, error)
+
+	// Revoke revokes a token issued to the authenticated client (RFC 7009)
+	Revoke(ctx context.Context, client ClientCredentials, token string) error
+}
+
+type impl struct {
+	repo       repository.Registry
+	codeTTL    time.Duration
+	refreshTTL time.Duration
+}
+
+func New(repo repository.Registry) Controller {
+	return traced{next: impl{
+		repo:       repo,
+		codeTTL:    durationFromConfig("OAUTH_CODE_TTL", defaultCodeTTL),
+		refreshTTL: durationFromConfig("JWT_REFRESH_DURATION", defaultRefreshDuration),
+	}}
+}
+
+func durationFromConfig(key string, def time.Duration) time.Duration {
+	if d := config.GetConfig().GetDuration(key); d > 0 {
+		return d
+	}
+	return def
+}
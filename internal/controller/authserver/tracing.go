@@ -0,0 +1,69 @@
+package authserver
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/tracing"
+)
+
+// traced records a span around each call of the Controller it wraps
+type traced struct {
+	next Controller
+}
+
+func (t traced) CreateClient(ctx context.Context, input CreateClientInput) (NewClient, error) {
+	ctx, span := tracing.Start(ctx, "authserver.CreateClient")
+	client, err := t.next.CreateClient(ctx, input)
+	tracing.End(span, err)
+	return client, err
+}
+
+func (t traced) ListClients(ctx context.Context) ([]model.OAuthClient, error) {
+	ctx, span := tracing.Start(ctx, "authserver.ListClients")
+	clients, err := t.next.ListClients(ctx)
+	tracing.End(span, err)
+	return clients, err
+}
+
+func (t traced) DeleteClient(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "authserver.DeleteClient")
+	err := t.next.DeleteClient(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) ValidateAuthorization(ctx context.Context, req AuthorizationRequest) (AuthorizationRequest, model.OAuthClient, error) {
+	ctx, span := tracing.Start(ctx, "authserver.ValidateAuthorization")
+	validated, client, err := t.next.ValidateAuthorization(ctx, req)
+	tracing.End(span, err)
+	return validated, client, err
+}
+
+func (t traced) Authorize(ctx context.Context, input AuthorizeInput) (string, error) {
+	ctx, span := tracing.Start(ctx, "authserver.Authorize")
+	redirectURL, err := t.next.Authorize(ctx, input)
+	tracing.End(span, err)
+	return redirectURL, err
+}
+
+func (t traced) Token(ctx context.Context, req TokenRequest) (Tokens, error) {
+	ctx, span := tracing.Start(ctx, "authserver.Token")
+	tokens, err := t.next.Token(ctx, req)
+	tracing.End(span, err)
+	return tokens, err
+}
+
+func (t traced) Introspect(ctx context.Context, client ClientCredentials, token string) (Introspection, error) {
+	ctx, span := tracing.Start(ctx, "authserver.Introspect")
+	introspection, err := t.next.Introspect(ctx, client, token)
+	tracing.End(span, err)
+	return introspection, err
+}
+
+func (t traced) Revoke(ctx context.Context, client ClientCredentials, token string) error {
+	ctx, span := tracing.Start(ctx, "authserver.Revoke")
+	err := t.next.Revoke(ctx, client, token)
+	tracing.End(span, err)
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/password"
 	"github.com/namf2001/go-backend-template/internal/repository"
 )
 
@@ -19,15 +20,33 @@ type Controller interface {
 	UpdateUser(ctx context.Context, id int64, input UpdateUserInput) error
 	// DeleteUser deletes a user by ID
 	DeleteUser(ctx context.Context, id int64) error
+	// UpdateProfile updates the profile of the given user
+	UpdateProfile(ctx context.Context, id int64, input UpdateProfileInput) (model.User, error)
+	// ChangePassword verifies the current password and sets a new one
+	ChangePassword(ctx context.Context, id int64, input ChangePasswordInput) error
+	// ListRoles lists the roles granted to a user
+	ListRoles(ctx context.Context, id int64) ([]model.Role, error)
+	// AssignRole grants a role to a user
+	AssignRole(ctx context.Context, id int64, role string) error
+	// RemoveRole revokes a role from a user
+	RemoveRole(ctx context.Context, id int64, role string) error
+	// ListAccounts lists the provider accounts linked to a user
+	ListAccounts(ctx context.Context, id int64) ([]model.Account, error)
+	// UnlinkAccount unlinks a provider from a user, unless it is the user's last way to sign in
+	UnlinkAccount(ctx context.Context, id int64, provider model.Provider) error
 }
 
 // New creates a new users Controller
-func New(repo repository.Registry) Controller {
-	return impl{
-		repo: repo,
-	}
+func New(repo repository.Registry, passwords password.Hasher, policy password.Policy) Controller {
+	return traced{next: impl{
+		repo:      repo,
+		passwords: passwords,
+		policy:    policy,
+	}}
 }
 
 type impl struct {
-	repo repository.Registry
+	repo      repository.Registry
+	passwords password.Hasher
+	policy    password.Policy // Rules new passwords must follow
 }
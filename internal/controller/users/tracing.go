@@ -0,0 +1,97 @@
+package users
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/tracing"
+)
+
+// traced records a span around each call of the Controller it wraps
+type traced struct {
+	next Controller
+}
+
+func (t traced) CreateUser(ctx context.Context, input CreateUserInput) (model.User, error) {
+	ctx, span := tracing.Start(ctx, "users.CreateUser")
+	user, err := t.next.CreateUser(ctx, input)
+	tracing.End(span, err)
+	return user, err
+}
+
+func (t traced) GetUser(ctx context.Context, id int64) (model.User, error) {
+	ctx, span := tracing.Start(ctx, "users.GetUser")
+	user, err := t.next.GetUser(ctx, id)
+	tracing.End(span, err)
+	return user, err
+}
+
+func (t traced) ListUsers(ctx context.Context, filters ListFilters) ([]model.User, int64, error) {
+	ctx, span := tracing.Start(ctx, "users.ListUsers")
+	users, total, err := t.next.ListUsers(ctx, filters)
+	tracing.End(span, err)
+	return users, total, err
+}
+
+func (t traced) UpdateUser(ctx context.Context, id int64, input UpdateUserInput) error {
+	ctx, span := tracing.Start(ctx, "users.UpdateUser")
+	err := t.next.UpdateUser(ctx, id, input)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) DeleteUser(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "users.DeleteUser")
+	err := t.next.DeleteUser(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) UpdateProfile(ctx context.Context, id int64, input UpdateProfileInput) (model.User, error) {
+	ctx, span := tracing.Start(ctx, "users.UpdateProfile")
+	user, err := t.next.UpdateProfile(ctx, id, input)
+	tracing.End(span, err)
+	return user, err
+}
+
+func (t traced) ChangePassword(ctx context.Context, id int64, input ChangePasswordInput) error {
+	ctx, span := tracing.Start(ctx, "users.ChangePassword")
+	err := t.next.ChangePassword(ctx, id, input)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) ListRoles(ctx context.Context, id int64) ([]model.Role, error) {
+	ctx, span := tracing.Start(ctx, "users.ListRoles")
+	roles, err := t.next.ListRoles(ctx, id)
+	tracing.End(span, err)
+	return roles, err
+}
+
+func (t traced) AssignRole(ctx context.Context, id int64, role string) error {
+	ctx, span := tracing.Start(ctx, "users.AssignRole")
+	err := t.next.AssignRole(ctx, id, role)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) RemoveRole(ctx context.Context, id int64, role string) error {
+	ctx, span := tracing.Start(ctx, "users.RemoveRole")
+	err := t.next.RemoveRole(ctx, id, role)
+	tracing.End(span, err)
+	return err
+}
+
+func (t traced) ListAccounts(ctx context.Context, id int64) ([]model.Account, error) {
+	ctx, span := tracing.Start(ctx, "users.ListAccounts")
+	accounts, err := t.next.ListAccounts(ctx, id)
+	tracing.End(span, err)
+	return accounts, err
+}
+
+func (t traced) UnlinkAccount(ctx context.Context, id int64, provider model.Provider) error {
+	ctx, span := tracing.Start(ctx, "users.UnlinkAccount")
+	err := t.next.UnlinkAccount(ctx, id, provider)
+	tracing.End(span, err)
+	return err
+}
@@ -0,0 +1,44 @@
+package users
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/validator"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// UpdateProfileInput represents the fields a user can change on their own profile
+type UpdateProfileInput struct {
+	Name  string `validate:"omitempty,min=2,max=100"`
+	Image string `validate:"omitempty,url"`
+}
+
+// UpdateProfile updates the profile of the given user
+func (i impl) UpdateProfile(ctx context.Context, id int64, input UpdateProfileInput) (model.User, error) {
+	// Validate input
+	if err := validator.Validate(input); err != nil {
+		return model.User{}, pkgerrors.WithStack(err)
+	}
+
+	// Get existing user
+	user, err := i.repo.User().GetByID(ctx, id)
+	if err != nil {
+		return model.User{}, pkgerrors.WithStack(err)
+	}
+
+	// Update fields
+	if input.Name != "" {
+		user.Name = input.Name
+	}
+	if input.Image != "" {
+		user.Image = input.Image
+	}
+
+	// Save changes
+	if err := i.repo.User().Update(ctx, user); err != nil {
+		return model.User{}, pkgerrors.WithStack(err)
+	}
+
+	return user, nil
+}
@@ -2,47 +2,165 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
+	ctrlAuth "github.com/namf2001/go-backend-template/internal/controller/auth"
 	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
 	"github.com/namf2001/go-backend-template/internal/pkg/jwt"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"go.uber.org/zap"
 )
 
 type contextKey string
 
-const contextKeyUserID contextKey = "userID"
+// APIKeyHeader is the header machine clients send their API key in, instead of an Authorization bearer token
+const APIKeyHeader = "X-API-Key"
+
+const (
+	contextKeyUserID      contextKey = "userID"
+	contextKeySessionID   contextKey = "sessionID"
+	contextKeyAPIKeyID    contextKey = "apiKeyID"
+	contextKeyClientID    contextKey = "clientID"
+	contextKeyRoles       contextKey = "roles"
+	contextKeyPermissions contextKey = "permissions"
+)
 
 var (
-	webErrMissingAuth  = &httpserv.Error{Status: http.StatusUnauthorized, Code: "missing_auth", Desc: "Missing authorization header"}
-	webErrInvalidAuth  = &httpserv.Error{Status: http.StatusUnauthorized, Code: "invalid_auth", Desc: "Invalid authorization header format"}
-	webErrInvalidToken = &httpserv.Error{Status: http.StatusUnauthorized, Code: "invalid_token", Desc: "Invalid or expired token"}
+	webErrMissingAuth     = &httpserv.Error{Status: http.StatusUnauthorized, Code: "missing_auth", Desc: "Missing authorization header"}
+	webErrInvalidAuth     = &httpserv.Error{Status: http.StatusUnauthorized, Code: "invalid_auth", Desc: "Invalid authorization header format"}
+	webErrInvalidToken    = &httpserv.Error{Status: http.StatusUnauthorized, Code: "invalid_token", Desc: "Invalid or expired token"}
+	webErrSessionRevoked  = &httpserv.Error{Status: http.StatusUnauthorized, Code: "session_revoked", Desc: "Session has been revoked"}
+	webErrInvalidAPIKey   = &httpserv.Error{Status: http.StatusUnauthorized, Code: "invalid_api_key", Desc: "Invalid, expired or revoked API key"}
+	webErrSessionRequired = &httpserv.Error{Status: http.StatusForbidden, Code: "session_required", Desc: "This action cannot be performed with an API key or an OAuth client token"}
 )
 
-// RequireAuth middleware verifies JWT token
-func RequireAuth(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			httpserv.RespondJSON(r.Context(), w, webErrMissingAuth)
-			return
-		}
+// SessionValidator checks that the session an access token was issued for is still active
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, sessionID string) error
+}
+
+// Authenticator checks the credentials accepted by RequireAuth
+type Authenticator interface {
+	SessionValidator
+
+	// AuthenticateAPIKey resolves the user and permissions of an API key
+	AuthenticateAPIKey(ctx context.Context, key string) (ctrlAuth.APIKeyPrincipal, error)
+}
+
+// RequireAuth middleware verifies the JWT token and rejects tokens whose session was revoked.
+// Requests without an Authorization header may authenticate with an API key instead.
+func RequireAuth(auth Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" {
+					requireAPIKey(auth, apiKey, next, w, r)
+					return
+				}
+				httpserv.RespondJSON(r.Context(), w, webErrMissingAuth)
+				return
+			}
+
+			headerParts := strings.Split(authHeader, " ")
+			if len(headerParts) != 2 || headerParts[0] != "Bearer" {
+				httpserv.RespondJSON(r.Context(), w, webErrInvalidAuth)
+				return
+			}
+
+			tokenString := headerParts[1]
+			claims, err := jwt.ParseToken(tokenString)
+			if err != nil {
+				httpserv.RespondJSON(r.Context(), w, webErrInvalidToken)
+				return
+			}
+
+			if err := auth.ValidateSession(r.Context(), claims.SessionID); err != nil {
+				if errors.Is(err, ctrlAuth.ErrSessionRevoked) {
+					httpserv.RespondJSON(r.Context(), w, webErrSessionRevoked)
+					return
+				}
+				httpserv.RespondJSON(r.Context(), w, err)
+				return
+			}
+
+			// Add UserID, SessionID, roles and permissions to context
+			ctx := context.WithValue(r.Context(), contextKeyUserID, claims.UserID)
+			ctx = context.WithValue(ctx, contextKeySessionID, claims.SessionID)
+			ctx = context.WithValue(ctx, contextKeyRoles, claims.Roles)
+			ctx = context.WithValue(ctx, contextKeyPermissions, claims.Permissions)
+			if claims.ClientID != "" {
+				ctx = context.WithValue(ctx, contextKeyClientID, claims.ClientID)
+			}
+			logger.AddFields(ctx, zap.Int64(logger.FieldUserID, claims.UserID))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-		headerParts := strings.Split(authHeader, " ")
-		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
-			httpserv.RespondJSON(r.Context(), w, webErrInvalidAuth)
+// requireAPIKey authenticates the request with an API key. There is no session, handlers needing one
+// see SessionIDFromContext fail.
+func requireAPIKey(auth Authenticator, apiKey string, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	principal, err := auth.AuthenticateAPIKey(r.Context(), apiKey)
+	if err != nil {
+		if errors.Is(err, ctrlAuth.ErrInvalidAPIKey) {
+			httpserv.RespondJSON(r.Context(), w, webErrInvalidAPIKey)
 			return
 		}
+		httpserv.RespondJSON(r.Context(), w, err)
+		return
+	}
 
-		tokenString := headerParts[1]
-		claims, err := jwt.ParseToken(tokenString)
-		if err != nil {
-			httpserv.RespondJSON(r.Context(), w, webErrInvalidToken)
+	ctx := context.WithValue(r.Context(), contextKeyUserID, principal.UserID)
+	ctx = context.WithValue(ctx, contextKeyAPIKeyID, principal.APIKeyID)
+	ctx = context.WithValue(ctx, contextKeyRoles, principal.Roles)
+	ctx = context.WithValue(ctx, contextKeyPermissions, principal.Permissions)
+	logger.AddFields(ctx, zap.Int64(logger.FieldUserID, principal.UserID))
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequireSession rejects requests authenticated with an API key or by an OAuth client acting for the user,
+// for actions only the user should take such as changing credentials. It must be used behind RequireAuth.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isDelegated(r.Context()) {
+			httpserv.RespondJSON(r.Context(), w, webErrSessionRequired)
 			return
 		}
-
-		// Add UserID to context
-		ctx := context.WithValue(r.Context(), contextKeyUserID, claims.UserID)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r)
 	})
 }
+
+// isDelegated reports whether the request was authenticated with an API key or an OAuth client token,
+// whose permissions are limited by their scopes
+func isDelegated(ctx context.Context) bool {
+	_, isAPIKey := APIKeyIDFromContext(ctx)
+	_, isClient := ClientIDFromContext(ctx)
+	return isAPIKey || isClient
+}
+
+// UserIDFromContext returns the ID of the authenticated user set by RequireAuth
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(contextKeyUserID).(int64)
+	return userID, ok
+}
+
+// SessionIDFromContext returns the session ID of the access token set by RequireAuth
+func SessionIDFromContext(ctx context.Context) (string, bool) {
+	sessionID, ok := ctx.Value(contextKeySessionID).(string)
+	return sessionID, ok
+}
+
+// APIKeyIDFromContext returns the ID of the API key the request was authenticated with by RequireAuth
+func APIKeyIDFromContext(ctx context.Context) (int64, bool) {
+	apiKeyID, ok := ctx.Value(contextKeyAPIKeyID).(int64)
+	return apiKeyID, ok
+}
+
+// ClientIDFromContext returns the OAuth client the access token was issued to, set by RequireAuth
+func ClientIDFromContext(ctx context.Context) (string, bool) {
+	clientID, ok := ctx.Value(contextKeyClientID).(string)
+	return clientID, ok
+}
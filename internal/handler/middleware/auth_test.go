@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/config"
+	ctrlAuth "github.com/namf2001/go-backend-template/internal/controller/auth"
+	"github.com/namf2001/go-backend-template/internal/pkg/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAuthenticator struct {
+	revoked map[string]bool
+	apiKeys map[string]ctrlAuth.APIKeyPrincipal
+}
+
+func (f fakeAuthenticator) ValidateSession(_ context.Context, sessionID string) error {
+	if f.revoked[sessionID] {
+		return ctrlAuth.ErrSessionRevoked
+	}
+	return nil
+}
+
+func (f fakeAuthenticator) AuthenticateAPIKey(_ context.Context, key string) (ctrlAuth.APIKeyPrincipal, error) {
+	principal, ok := f.apiKeys[key]
+	if !ok {
+		return ctrlAuth.APIKeyPrincipal{}, ctrlAuth.ErrInvalidAPIKey
+	}
+	return principal, nil
+}
+
+func TestRequireAuth(t *testing.T) {
+	config.Init("test")
+	config.GetConfig().Set("JWT_SECRET", "test-secret")
+	config.GetConfig().Set("APP_BASE_URL", "http://localhost:8080")
+
+	activeToken, err := jwt.GenerateToken(jwt.Subject{UserID: 1001, Email: "test1@example.com", SessionID: "active-session"})
+	require.NoError(t, err)
+	revokedToken, err := jwt.GenerateToken(jwt.Subject{UserID: 1001, Email: "test1@example.com", SessionID: "revoked-session"})
+	require.NoError(t, err)
+
+	type args struct {
+		givenHeader      string
+		givenAPIKey      string
+		expStatus        int
+		expUserID        int64
+		expAPIKeyID      int64
+		expHasPermission bool
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenHeader: "Bearer " + activeToken,
+			expStatus:   http.StatusOK,
+			expUserID:   1001,
+		},
+		"err - missing header": {
+			expStatus: http.StatusUnauthorized,
+		},
+		"err - invalid header format": {
+			givenHeader: "Token " + activeToken,
+			expStatus:   http.StatusUnauthorized,
+		},
+		"err - invalid token": {
+			givenHeader: "Bearer not-a-jwt",
+			expStatus:   http.StatusUnauthorized,
+		},
+		"err - revoked session": {
+			givenHeader: "Bearer " + revokedToken,
+			expStatus:   http.StatusUnauthorized,
+		},
+		"success - api key": {
+			givenAPIKey:      "gbt_valid",
+			expStatus:        http.StatusOK,
+			expUserID:        1002,
+			expAPIKeyID:      11,
+			expHasPermission: true,
+		},
+		"err - invalid api key": {
+			givenAPIKey: "gbt_revoked",
+			expStatus:   http.StatusUnauthorized,
+		},
+		"err - invalid bearer token is not retried as api key": {
+			givenHeader: "Bearer not-a-jwt",
+			givenAPIKey: "gbt_valid",
+			expStatus:   http.StatusUnauthorized,
+		},
+	}
+
+	validator := fakeAuthenticator{
+		revoked: map[string]bool{"revoked-session": true},
+		apiKeys: map[string]ctrlAuth.APIKeyPrincipal{
+			"gbt_valid": {UserID: 1002, APIKeyID: 11, Permissions: []string{"users:read"}},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			var (
+				gotUserID        int64
+				gotAPIKeyID      int64
+				gotHasPermission bool
+			)
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUserID, _ = UserIDFromContext(r.Context())
+				gotAPIKeyID, _ = APIKeyIDFromContext(r.Context())
+				gotHasPermission = HasPermission(r.Context(), "users:read")
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.givenHeader != "" {
+				req.Header.Set("Authorization", tc.givenHeader)
+			}
+			if tc.givenAPIKey != "" {
+				req.Header.Set(APIKeyHeader, tc.givenAPIKey)
+			}
+			rec := httptest.NewRecorder()
+
+			RequireAuth(validator)(next).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expStatus, rec.Code)
+			require.Equal(t, tc.expUserID, gotUserID)
+			require.Equal(t, tc.expAPIKeyID, gotAPIKeyID)
+			require.Equal(t, tc.expHasPermission, gotHasPermission)
+		})
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	type args struct {
+		givenAPIKeyID int64
+		givenClientID string
+		expStatus     int
+	}
+
+	tcs := map[string]args{
+		"success - session": {
+			expStatus: http.StatusOK,
+		},
+		"err - api key": {
+			givenAPIKeyID: 11,
+			expStatus:     http.StatusForbidden,
+		},
+		"err - oauth client token": {
+			givenClientID: "web-app",
+			expStatus:     http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			ctx := context.WithValue(context.Background(), contextKeyUserID, int64(1001))
+			if tc.givenAPIKeyID != 0 {
+				ctx = context.WithValue(ctx, contextKeyAPIKeyID, tc.givenAPIKeyID)
+			}
+			if tc.givenClientID != "" {
+				ctx = context.WithValue(ctx, contextKeyClientID, tc.givenClientID)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			RequireSession(next).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expStatus, rec.Code)
+		})
+	}
+}
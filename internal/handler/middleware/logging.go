@@ -5,28 +5,38 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
-// Logger adds request logging
-func Logger(l *zap.Logger) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+// Logger scopes the request logs with its request and trace IDs, and logs the request once completed.
+// It must be used after chi's RequestID and the Tracing middlewares.
+func Logger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
-			defer func() {
-				l.Info("request completed",
-					zap.String("req_id", middleware.GetReqID(r.Context())),
-					zap.String("method", r.Method),
-					zap.String("path", r.URL.Path),
-					zap.Int("status", ww.Status()),
-					zap.Duration("duration", time.Since(start)),
-					zap.Int("bytes", ww.BytesWritten()),
-				)
-			}()
+		fields := []zap.Field{zap.String(logger.FieldRequestID, middleware.GetReqID(r.Context()))}
+		if span := trace.SpanContextFromContext(r.Context()); span.IsValid() {
+			fields = append(fields,
+				zap.String(logger.FieldTraceID, span.TraceID().String()),
+				zap.String(logger.FieldSpanID, span.SpanID().String()),
+			)
+		}
+		ctx := logger.NewContext(r.Context(), fields...)
 
-			next.ServeHTTP(ww, r)
-		})
-	}
-}
\ No newline at end of file
+		defer func() {
+			logger.Info(ctx, "request completed",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.Int("status", ww.Status()),
+				zap.Duration("duration", time.Since(start)),
+				zap.Int("bytes", ww.BytesWritten()),
+				zap.String("remote_addr", r.RemoteAddr),
+			)
+		}()
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/namf2001/go-backend-template/internal/pkg/tracing"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLogger(t *testing.T) {
+	type args struct {
+		traceParent string
+		userID      int64
+		expTraceID  interface{}
+		expUserID   interface{}
+	}
+	tcs := map[string]args{
+		"success - anonymous": {},
+		"success - authenticated": {
+			userID:    1001,
+			expUserID: float64(1001),
+		},
+		"success - traced": {
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expTraceID:  "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		"success - invalid trace, new trace started": {
+			traceParent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			var buf bytes.Buffer
+			l, err := logger.New(&buf, logger.FormatJSON, zap.NewAtomicLevelAt(zapcore.InfoLevel))
+			require.NoError(t, err)
+			previous := logger.L()
+			logger.SetDefault(l)
+			t.Cleanup(func() { logger.SetDefault(previous) })
+
+			_, restore := tracing.NewInMemory()
+			t.Cleanup(restore)
+
+			handler := middleware.RequestID(Tracing(Logger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.userID != 0 {
+					// As RequireAuth does
+					logger.AddFields(r.Context(), zap.Int64(logger.FieldUserID, tc.userID))
+				}
+				w.WriteHeader(http.StatusTeapot)
+			}))))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/me", nil).WithContext(context.Background())
+			if tc.traceParent != "" {
+				req.Header.Set("traceparent", tc.traceParent)
+			}
+
+			// When
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Then
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			require.Equal(t, "request completed", entry["message"])
+			require.NotEmpty(t, entry[logger.FieldRequestID])
+			require.Equal(t, "/api/v1/me", entry["path"])
+			require.Equal(t, float64(http.StatusTeapot), entry["status"])
+			require.NotEmpty(t, entry[logger.FieldTraceID])
+			require.NotEmpty(t, entry[logger.FieldSpanID])
+			if tc.expTraceID != nil {
+				require.Equal(t, tc.expTraceID, entry[logger.FieldTraceID])
+			}
+			require.Equal(t, tc.expUserID, entry[logger.FieldUserID])
+		})
+	}
+}
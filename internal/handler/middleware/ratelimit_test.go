@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository/ratelimits"
+	"github.com/stretchr/testify/require"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, time.Time, model.RateLimit) (model.RateLimitResult, error) {
+	return model.RateLimitResult{}, errors.New("connection refused")
+}
+
+func TestRateLimit(t *testing.T) {
+	type request struct {
+		remoteAddr string
+		userID     int64
+		apiKeyID   int64
+	}
+	type args struct {
+		key          KeyFunc
+		store        ratelimits.Repository
+		givenBefore  []request // Requests sent first, all allowed
+		given        request
+		expStatus    int
+		expRemaining string
+		expRetry     string
+	}
+
+	ip1 := request{remoteAddr: "192.0.2.1:1234"}
+	tcs := map[string]args{
+		"success - within the limit": {
+			key:          KeyByIP,
+			givenBefore:  []request{ip1},
+			given:        ip1,
+			expStatus:    http.StatusOK,
+			expRemaining: "0",
+		},
+		"success - other ip": {
+			key:          KeyByIP,
+			givenBefore:  []request{ip1, ip1},
+			given:        request{remoteAddr: "192.0.2.2:1234"},
+			expStatus:    http.StatusOK,
+			expRemaining: "1",
+		},
+		"success - users behind the same ip": {
+			key:          KeyByUser,
+			givenBefore:  []request{{remoteAddr: "192.0.2.1:1234", userID: 1001}, {remoteAddr: "192.0.2.1:1234", userID: 1001}},
+			given:        request{remoteAddr: "192.0.2.1:1234", userID: 1002},
+			expStatus:    http.StatusOK,
+			expRemaining: "1",
+		},
+		"success - api keys of the same user": {
+			key:          KeyByAPIKey,
+			givenBefore:  []request{{userID: 1001, apiKeyID: 11}, {userID: 1001, apiKeyID: 11}},
+			given:        request{userID: 1001, apiKeyID: 12},
+			expStatus:    http.StatusOK,
+			expRemaining: "1",
+		},
+		"success - store failure lets requests through": {
+			key:         KeyByIP,
+			store:       failingStore{},
+			givenBefore: []request{ip1, ip1},
+			given:       ip1,
+			expStatus:   http.StatusOK,
+		},
+		"err - over the limit": {
+			key:          KeyByIP,
+			givenBefore:  []request{ip1, ip1},
+			given:        ip1,
+			expStatus:    http.StatusTooManyRequests,
+			expRemaining: "0",
+			expRetry:     "30",
+		},
+		"err - unauthenticated users share their ip": {
+			key:          KeyByUser,
+			givenBefore:  []request{ip1, ip1},
+			given:        ip1,
+			expStatus:    http.StatusTooManyRequests,
+			expRemaining: "0",
+			expRetry:     "30",
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			store := tc.store
+			if store == nil {
+				store = ratelimits.NewMemory()
+			}
+			policy := RateLimitPolicy{Name: "login", Limit: model.RateLimit{Limit: 2, Period: time.Minute}, Key: tc.key}
+			handler := RateLimit(store, policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			send := func(given request) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+				if given.remoteAddr != "" {
+					req.RemoteAddr = given.remoteAddr
+				}
+				ctx := req.Context()
+				if given.userID != 0 {
+					ctx = context.WithValue(ctx, contextKeyUserID, given.userID)
+				}
+				if given.apiKeyID != 0 {
+					ctx = context.WithValue(ctx, contextKeyAPIKeyID, given.apiKeyID)
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req.WithContext(ctx))
+				return rec
+			}
+
+			for _, given := range tc.givenBefore {
+				require.Equal(t, http.StatusOK, send(given).Code)
+			}
+			rec := send(tc.given)
+
+			require.Equal(t, tc.expStatus, rec.Code)
+			require.Equal(t, tc.expRemaining, rec.Header().Get(HeaderRateLimitRemaining))
+			require.Equal(t, tc.expRetry, rec.Header().Get(HeaderRetryAfter))
+			if tc.expRemaining != "" {
+				require.Equal(t, "2", rec.Header().Get(HeaderRateLimitLimit))
+				require.Equal(t, "2;w=60", rec.Header().Get(HeaderRateLimitPolicy))
+				require.NotEmpty(t, rec.Header().Get(HeaderRateLimitReset))
+			}
+			if tc.expStatus == http.StatusTooManyRequests {
+				require.JSONEq(t, `{"error":"rate_limited","error_description":"Too many requests, retry later"}`, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	type args struct {
+		given    string
+		expLimit model.RateLimit
+		expErr   bool
+	}
+	tcs := map[string]args{
+		"per minute":      {given: "10/1m", expLimit: model.RateLimit{Limit: 10, Period: time.Minute}},
+		"with spaces":     {given: "1000 / 1h", expLimit: model.RateLimit{Limit: 1000, Period: time.Hour}},
+		"err - no period": {given: "10", expErr: true},
+		"err - zero":      {given: "0/1m", expErr: true},
+		"err - bad unit":  {given: "10/minute", expErr: true},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			limit, err := ParseRateLimit(tc.given)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expLimit, limit)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func withIdentity(userID int64, roles, permissions []string) context.Context {
+	ctx := context.WithValue(context.Background(), contextKeyUserID, userID)
+	ctx = context.WithValue(ctx, contextKeyRoles, roles)
+	return context.WithValue(ctx, contextKeyPermissions, permissions)
+}
+
+func TestRequireRole(t *testing.T) {
+	type args struct {
+		givenRoles []string
+		expStatus  int
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenRoles: []string{model.RoleUser, model.RoleAdmin},
+			expStatus:  http.StatusOK,
+		},
+		"err - missing role": {
+			givenRoles: []string{model.RoleUser},
+			expStatus:  http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(withIdentity(1001, tc.givenRoles, nil))
+			rec := httptest.NewRecorder()
+
+			RequireRole(model.RoleAdmin)(next).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expStatus, rec.Code)
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	type args struct {
+		givenPermissions []string
+		expStatus        int
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenPermissions: []string{model.PermissionUsersRead, model.PermissionUsersDelete},
+			expStatus:        http.StatusOK,
+		},
+		"err - missing one permission": {
+			givenPermissions: []string{model.PermissionUsersRead},
+			expStatus:        http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(withIdentity(1001, nil, tc.givenPermissions))
+			rec := httptest.NewRecorder()
+
+			RequirePermission(model.PermissionUsersRead, model.PermissionUsersDelete)(next).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expStatus, rec.Code)
+		})
+	}
+}
+
+func TestRequireOwnerOrPermission(t *testing.T) {
+	type args struct {
+		givenUserID      int64
+		givenPermissions []string
+		givenClientID    string
+		givenAPIKeyID    int64
+		givenPath        string
+		expStatus        int
+	}
+
+	tcs := map[string]args{
+		"success - owner": {
+			givenUserID: 1001,
+			givenPath:   "/users/1001",
+			expStatus:   http.StatusOK,
+		},
+		"success - has permission": {
+			givenUserID:      1003,
+			givenPermissions: []string{model.PermissionUsersUpdate},
+			givenPath:        "/users/1001",
+			expStatus:        http.StatusOK,
+		},
+		"err - other user": {
+			givenUserID: 1002,
+			givenPath:   "/users/1001",
+			expStatus:   http.StatusForbidden,
+		},
+		"success - client token with permission": {
+			givenUserID:      1001,
+			givenPermissions: []string{model.PermissionUsersUpdate},
+			givenClientID:    "client-1",
+			givenPath:        "/users/1001",
+			expStatus:        http.StatusOK,
+		},
+		"err - client token of owner without permission": {
+			givenUserID:      1001,
+			givenPermissions: []string{model.PermissionUsersRead},
+			givenClientID:    "client-1",
+			givenPath:        "/users/1001",
+			expStatus:        http.StatusForbidden,
+		},
+		"err - scoped api key of owner without permission": {
+			givenUserID:      1001,
+			givenPermissions: []string{model.PermissionUsersRead},
+			givenAPIKeyID:    11,
+			givenPath:        "/users/1001",
+			expStatus:        http.StatusForbidden,
+		},
+		"err - invalid id": {
+			givenUserID: 1001,
+			givenPath:   "/users/abc",
+			expStatus:   http.StatusForbidden,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			r := chi.NewRouter()
+			r.With(RequireOwnerOrPermission("id", model.PermissionUsersUpdate)).Put("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			ctx := withIdentity(tc.givenUserID, nil, tc.givenPermissions)
+			if tc.givenClientID != "" {
+				ctx = context.WithValue(ctx, contextKeyClientID, tc.givenClientID)
+			}
+			if tc.givenAPIKeyID != 0 {
+				ctx = context.WithValue(ctx, contextKeyAPIKeyID, tc.givenAPIKeyID)
+			}
+			req := httptest.NewRequest(http.MethodPut, tc.givenPath, nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expStatus, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository/ratelimits"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8, 192.0.2.10")
+	require.NoError(t, err)
+
+	type args struct {
+		remoteAddr   string
+		forwardedFor []string
+		realIP       string
+		expIP        string
+	}
+	tcs := map[string]args{
+		"success - from trusted proxy": {
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: []string{"203.0.113.7"},
+			expIP:        "203.0.113.7",
+		},
+		"success - chain of trusted proxies": {
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: []string{"203.0.113.7, 192.0.2.10", "10.0.0.2"},
+			expIP:        "203.0.113.7",
+		},
+		"success - spoofed hops before the proxy are ignored": {
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: []string{"198.51.100.1, 203.0.113.7"},
+			expIP:        "203.0.113.7",
+		},
+		"success - x-real-ip from trusted proxy": {
+			remoteAddr: "192.0.2.10:1234",
+			realIP:     "203.0.113.7",
+			expIP:      "203.0.113.7",
+		},
+		"success - garbage hop stops at the proxy": {
+			remoteAddr:   "10.0.0.1:1234",
+			forwardedFor: []string{"not-an-ip, 10.0.0.2"},
+			expIP:        "10.0.0.2",
+		},
+		"err - headers from untrusted client are ignored": {
+			remoteAddr:   "203.0.113.7:1234",
+			forwardedFor: []string{"198.51.100.1"},
+			realIP:       "198.51.100.2",
+			expIP:        "203.0.113.7:1234",
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			var got string
+			handler := RealIP(proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for _, v := range tc.forwardedFor {
+				req.Header.Add(headerForwardedFor, v)
+			}
+			if tc.realIP != "" {
+				req.Header.Set(headerRealIP, tc.realIP)
+			}
+
+			// When
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Then
+			require.Equal(t, tc.expIP, got)
+		})
+	}
+}
+
+func TestRealIP_RateLimitBypass(t *testing.T) {
+	type args struct {
+		proxies   string
+		expStatus int
+	}
+	tcs := map[string]args{
+		"err - no trusted proxy": {
+			expStatus: http.StatusTooManyRequests,
+		},
+		"err - client is not a trusted proxy": {
+			proxies:   "10.0.0.0/8",
+			expStatus: http.StatusTooManyRequests,
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			proxies, err := ParseTrustedProxies(tc.proxies)
+			require.NoError(t, err)
+			policy := RateLimitPolicy{Name: "login", Limit: model.RateLimit{Limit: 2, Period: time.Minute}, Key: KeyByIP}
+			handler := RealIP(proxies)(RateLimit(ratelimits.NewMemory(), policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})))
+			send := func(n int) int {
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+				req.RemoteAddr = "203.0.113.7:1234"
+				req.Header.Set(headerForwardedFor, "198.51.100."+strconv.Itoa(n))
+				req.Header.Set(headerRealIP, "198.51.100."+strconv.Itoa(n))
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				return rec.Code
+			}
+
+			// When
+			send(1)
+			send(2)
+			status := send(3)
+
+			// Then
+			require.Equal(t, tc.expStatus, status)
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	type args struct {
+		given    string
+		expCount int
+		expErr   bool
+	}
+	tcs := map[string]args{
+		"success - empty":         {given: ""},
+		"success - cidrs and ips": {given: "10.0.0.0/8, 192.0.2.10,2001:db8::1", expCount: 3},
+		"err - invalid ip":        {given: "10.0.0", expErr: true},
+		"err - invalid cidr":      {given: "10.0.0.0/33", expErr: true},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+
+			// When
+			proxies, err := ParseTrustedProxies(tc.given)
+
+			// Then
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, proxies, tc.expCount)
+		})
+	}
+}
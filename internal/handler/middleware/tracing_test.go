@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/namf2001/go-backend-template/internal/pkg/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	type args struct {
+		path        string
+		traceParent string
+		expName     string
+		expStatus   int64
+		expRoute    string
+		expTraceID  string
+		expCode     codes.Code
+	}
+	tcs := map[string]args{
+		"success - route pattern": {
+			path:      "/users/1001",
+			expName:   "GET /users/{id}",
+			expStatus: http.StatusOK,
+			expRoute:  "/users/{id}",
+		},
+		"success - continues the caller's trace": {
+			path:        "/users/1001",
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expName:     "GET /users/{id}",
+			expStatus:   http.StatusOK,
+			expRoute:    "/users/{id}",
+			expTraceID:  "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		"err - server error": {
+			path:      "/fail",
+			expName:   "GET /fail",
+			expStatus: http.StatusInternalServerError,
+			expRoute:  "/fail",
+			expCode:   codes.Error,
+		},
+		"err - not found": {
+			path:      "/unknown",
+			expName:   "GET",
+			expStatus: http.StatusNotFound,
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			spans, restore := tracing.NewInMemory()
+			t.Cleanup(restore)
+
+			r := chi.NewRouter()
+			r.Use(Tracing)
+			r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				require.True(t, trace.SpanContextFromContext(r.Context()).IsValid())
+			})
+			r.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.traceParent != "" {
+				req.Header.Set("traceparent", tc.traceParent)
+			}
+
+			// When
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Then
+			ended := spans.GetSpans()
+			require.Len(t, ended, 1)
+			span := ended[0]
+			require.Equal(t, tc.expName, span.Name)
+			require.Equal(t, trace.SpanKindServer, span.SpanKind)
+			require.Equal(t, tc.expCode, span.Status.Code)
+
+			attrs := map[attribute.Key]attribute.Value{}
+			for _, kv := range span.Attributes {
+				attrs[kv.Key] = kv.Value
+			}
+			require.Equal(t, tc.expStatus, attrs["http.response.status_code"].AsInt64())
+			require.Equal(t, tc.expRoute, attrs["http.route"].AsString())
+			if tc.expTraceID != "" {
+				require.Equal(t, tc.expTraceID, span.SpanContext.TraceID().String())
+				require.True(t, span.Parent.IsRemote())
+			}
+		})
+	}
+}
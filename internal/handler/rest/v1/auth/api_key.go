@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	ctrlAuth "github.com/namf2001/go-backend-template/internal/controller/auth"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/validator"
+)
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=255"`
+	Scopes    []string   `json:"scopes" validate:"omitempty,dive,required"` // Permissions to limit the key to, all of the user's when empty
+	ExpiresAt *time.Time `json:"expires_at"`                                // Never expires when null
+}
+
+// APIKeyResponse represents an API key of the current user
+type APIKeyResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse represents a new API key, the key itself is not shown again
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// ListAPIKeysResponse represents the response for listing API keys
+type ListAPIKeysResponse struct {
+	APIKeys []APIKeyResponse `json:"api_keys"`
+}
+
+func newAPIKeyResponse(k model.APIKey) APIKeyResponse {
+	scopes := k.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+// CreateAPIKey creates an API key for the current user
+// @Summary      Create API key
+// @Description  Create an API key machine clients send in the X-API-Key header. The key is only returned once.
+// @Tags         me
+// @Accept       json
+// @Produce      json
+// @Param        input body auth.CreateAPIKeyRequest true "API key settings"
+// @Success      200  {object} auth.CreateAPIKeyResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /me/api-keys [post]
+func (h *Handler) CreateAPIKey() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		var req CreateAPIKeyRequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		created, err := h.ctrl.CreateAPIKey(r.Context(), ctrlAuth.CreateAPIKeyInput{
+			UserID:    userID,
+			Name:      req.Name,
+			Scopes:    req.Scopes,
+			ExpiresAt: req.ExpiresAt,
+		})
+		if err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, CreateAPIKeyResponse{
+			APIKeyResponse: newAPIKeyResponse(created.APIKey),
+			Key:            created.Key,
+		})
+		return nil
+	})
+}
+
+// ListAPIKeys lists the API keys of the current user
+// @Summary      List API keys
+// @Description  List the API keys of the current user that are not revoked, without their secret
+// @Tags         me
+// @Produce      json
+// @Success      200  {object} auth.ListAPIKeysResponse
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /me/api-keys [get]
+func (h *Handler) ListAPIKeys() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		keys, err := h.ctrl.ListAPIKeys(r.Context(), userID)
+		if err != nil {
+			return convertError(err)
+		}
+
+		resp := ListAPIKeysResponse{APIKeys: make([]APIKeyResponse, 0, len(keys))}
+		for _, k := range keys {
+			resp.APIKeys = append(resp.APIKeys, newAPIKeyResponse(k))
+		}
+
+		httpserv.RespondJSON(r.Context(), w, resp)
+		return nil
+	})
+}
+
+// RevokeAPIKey revokes an API key of the current user
+// @Summary      Revoke API key
+// @Description  Revoke an API key of the current user, requests using it are rejected right away
+// @Tags         me
+// @Produce      json
+// @Param        id   path      int  true  "API key ID"
+// @Success      204  {object} nil
+// @Failure      401  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /me/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			return webErrAPIKeyNotFound
+		}
+
+		if err := h.ctrl.RevokeAPIKey(r.Context(), userID, id); err != nil {
+			return convertError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
@@ -1,15 +1,110 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 
+	ctrlAuth "github.com/namf2001/go-backend-template/internal/controller/auth"
 	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/password"
 )
 
 var (
-	webErrValidationFailed   = &httpserv.Error{Status: http.StatusBadRequest, Code: "validation_failed", Desc: "Validation failed"}
-	webErrInvalidCredentials = &httpserv.Error{Status: http.StatusUnauthorized, Code: "invalid_credentials", Desc: "Invalid email or password"}
-	webErrInvalidOAuthState  = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_oauth_state", Desc: "Invalid OAuth state"}
-	webErrCodeExchangeFailed = &httpserv.Error{Status: http.StatusBadRequest, Code: "code_exchange_failed", Desc: "OAuth code exchange failed"}
-	webErrGetUserInfoFailed  = &httpserv.Error{Status: http.StatusInternalServerError, Code: "get_user_info_failed", Desc: "Failed to get user info from provider"}
+	webErrValidationFailed         = &httpserv.Error{Status: http.StatusBadRequest, Code: "validation_failed", Desc: "Validation failed"}
+	webErrInvalidCredentials       = &httpserv.Error{Status: http.StatusUnauthorized, Code: "invalid_credentials", Desc: "Invalid email or password"}
+	webErrAccountLocked            = &httpserv.Error{Status: http.StatusTooManyRequests, Code: "account_locked", Desc: "Too many failed login attempts, try again later"}
+	webErrInvalidOAuthState        = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_oauth_state", Desc: "Invalid OAuth state"}
+	webErrCodeExchangeFailed       = &httpserv.Error{Status: http.StatusBadRequest, Code: "code_exchange_failed", Desc: "OAuth code exchange failed"}
+	webErrGetUserInfoFailed        = &httpserv.Error{Status: http.StatusInternalServerError, Code: "get_user_info_failed", Desc: "Failed to get user info from provider"}
+	webErrInvalidIDToken           = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_id_token", Desc: "The provider returned an invalid ID token"}
+	webErrOAuthDenied              = &httpserv.Error{Status: http.StatusBadRequest, Code: "oauth_denied", Desc: "The provider denied the authorization request"}
+	webErrOAuthEmailNotVerified    = &httpserv.Error{Status: http.StatusForbidden, Code: "oauth_email_not_verified", Desc: "The provider did not verify this email, sign in another way and link the provider from your profile"}
+	webErrAccountAlreadyLinked     = &httpserv.Error{Status: http.StatusConflict, Code: "account_already_linked", Desc: "This provider account is linked to another user"}
+	webErrProviderAlreadyLinked    = &httpserv.Error{Status: http.StatusConflict, Code: "provider_already_linked", Desc: "Another account of this provider is already linked"}
+	webErrProviderNotFound         = &httpserv.Error{Status: http.StatusNotFound, Code: "provider_not_found", Desc: "OAuth provider not found"}
+	webErrOAuthEmailMissing        = &httpserv.Error{Status: http.StatusBadRequest, Code: "oauth_email_missing", Desc: "The provider did not return an email address"}
+	webErrInvalidRefreshToken      = &httpserv.Error{Status: http.StatusUnauthorized, Code: "invalid_refresh_token", Desc: "Invalid or expired refresh token"}
+	webErrRefreshTokenReused       = &httpserv.Error{Status: http.StatusUnauthorized, Code: "refresh_token_reused", Desc: "Refresh token was already used, please log in again"}
+	webErrUnauthenticated          = &httpserv.Error{Status: http.StatusUnauthorized, Code: "unauthenticated", Desc: "Authentication required"}
+	webErrSessionNotFound          = &httpserv.Error{Status: http.StatusNotFound, Code: "session_not_found", Desc: "Session not found"}
+	webErrInvalidVerificationToken = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_verification_token", Desc: "Invalid or expired verification link"}
+	webErrEmailAlreadyVerified     = &httpserv.Error{Status: http.StatusConflict, Code: "email_already_verified", Desc: "Email is already verified"}
+	webErrInvalidMFAToken          = &httpserv.Error{Status: http.StatusUnauthorized, Code: "invalid_mfa_token", Desc: "Invalid or expired MFA token, please log in again"}
+	webErrInvalidMFACode           = &httpserv.Error{Status: http.StatusUnauthorized, Code: "invalid_mfa_code", Desc: "Invalid two-factor authentication code"}
+	webErrMFAAlreadyEnabled        = &httpserv.Error{Status: http.StatusConflict, Code: "mfa_already_enabled", Desc: "Two-factor authentication is already enabled"}
+	webErrMFANotEnrolled           = &httpserv.Error{Status: http.StatusNotFound, Code: "mfa_not_enrolled", Desc: "Two-factor authentication is not enrolled"}
+	webErrMFARequiresPassword      = &httpserv.Error{Status: http.StatusBadRequest, Code: "mfa_requires_password", Desc: "Set a password before enabling two-factor authentication"}
+	webErrInvalidPasskey           = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_passkey", Desc: "The passkey response could not be verified"}
+	webErrPasskeyCloned            = &httpserv.Error{Status: http.StatusUnauthorized, Code: "passkey_cloned", Desc: "This passkey may have been copied, sign in another way and replace it"}
+	webErrPasskeyAlreadyRegistered = &httpserv.Error{Status: http.StatusConflict, Code: "passkey_already_registered", Desc: "This passkey is already registered"}
+	webErrPasskeyNotFound          = &httpserv.Error{Status: http.StatusNotFound, Code: "passkey_not_found", Desc: "Passkey not found"}
+	webErrLastSignInMethod         = &httpserv.Error{Status: http.StatusConflict, Code: "last_sign_in_method", Desc: "Cannot delete the last way to sign in, set a password or link another provider first"}
+	webErrInvalidAPIKeyScope       = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_api_key_scope", Desc: "API key scopes must be permissions you have"}
+	webErrInvalidAPIKeyExpiry      = &httpserv.Error{Status: http.StatusBadRequest, Code: "invalid_api_key_expiry", Desc: "API key expiry must be in the future"}
+	webErrAPIKeyNotFound           = &httpserv.Error{Status: http.StatusNotFound, Code: "api_key_not_found", Desc: "API key not found"}
 )
+
+func convertError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var policyErr *password.PolicyError
+	switch {
+	case errors.As(err, &policyErr):
+		return passwordPolicyError("password", policyErr)
+	case errors.Is(err, ctrlAuth.ErrInvalidRefreshToken):
+		return webErrInvalidRefreshToken
+	case errors.Is(err, ctrlAuth.ErrRefreshTokenReused):
+		return webErrRefreshTokenReused
+	case errors.Is(err, ctrlAuth.ErrSessionNotFound):
+		return webErrSessionNotFound
+	case errors.Is(err, ctrlAuth.ErrInvalidVerificationToken):
+		return webErrInvalidVerificationToken
+	case errors.Is(err, ctrlAuth.ErrEmailAlreadyVerified):
+		return webErrEmailAlreadyVerified
+	case errors.Is(err, ctrlAuth.ErrOAuthEmailNotVerified):
+		return webErrOAuthEmailNotVerified
+	case errors.Is(err, ctrlAuth.ErrAccountAlreadyLinked):
+		return webErrAccountAlreadyLinked
+	case errors.Is(err, ctrlAuth.ErrProviderAlreadyLinked):
+		return webErrProviderAlreadyLinked
+	case errors.Is(err, ctrlAuth.ErrInvalidMFAToken):
+		return webErrInvalidMFAToken
+	case errors.Is(err, ctrlAuth.ErrInvalidMFACode):
+		return webErrInvalidMFACode
+	case errors.Is(err, ctrlAuth.ErrMFAAlreadyEnabled):
+		return webErrMFAAlreadyEnabled
+	case errors.Is(err, ctrlAuth.ErrMFANotEnrolled):
+		return webErrMFANotEnrolled
+	case errors.Is(err, ctrlAuth.ErrMFARequiresPassword):
+		return webErrMFARequiresPassword
+	case errors.Is(err, ctrlAuth.ErrInvalidPasskey):
+		return webErrInvalidPasskey
+	case errors.Is(err, ctrlAuth.ErrPasskeyCloned):
+		return webErrPasskeyCloned
+	case errors.Is(err, ctrlAuth.ErrPasskeyAlreadyRegistered):
+		return webErrPasskeyAlreadyRegistered
+	case errors.Is(err, ctrlAuth.ErrPasskeyNotFound):
+		return webErrPasskeyNotFound
+	case errors.Is(err, ctrlAuth.ErrLastSignInMethod):
+		return webErrLastSignInMethod
+	case errors.Is(err, ctrlAuth.ErrInvalidAPIKeyScope):
+		return webErrInvalidAPIKeyScope
+	case errors.Is(err, ctrlAuth.ErrInvalidAPIKeyExpiry):
+		return webErrInvalidAPIKeyExpiry
+	case errors.Is(err, ctrlAuth.ErrAPIKeyNotFound):
+		return webErrAPIKeyNotFound
+	default:
+		return err
+	}
+}
+
+// passwordPolicyError reports every rule of the password policy the field breaks
+func passwordPolicyError(field string, err *password.PolicyError) *httpserv.Error {
+	webErr := &httpserv.Error{Status: webErrValidationFailed.Status, Code: webErrValidationFailed.Code, Desc: "Password does not meet the password policy"}
+	for _, v := range err.Violations {
+		webErr.Fields = append(webErr.Fields, httpserv.FieldError{Field: field, Rule: v.Rule, Message: v.Message})
+	}
+	return webErr
+}
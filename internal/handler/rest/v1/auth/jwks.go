@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/jwt"
+)
+
+// JWKS handles publishing the public keys access tokens can be verified with
+// @Summary      JSON Web Key Set
+// @Description  Public keys other services use to verify access tokens. Served at /.well-known/jwks.json.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object} jwt.JSONWebKeySet
+// @Failure      500  {object} httpserv.Error
+// @Router       /.well-known/jwks.json [get]
+func (h *Handler) JWKS() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		set, err := jwt.JWKS()
+		if err != nil {
+			return err
+		}
+
+		httpserv.RespondJSONWithHeaders(r.Context(), w, set, map[string]string{
+			// Short enough for verifiers to pick up a new key soon after a rotation
+			"Cache-Control": "public, max-age=300",
+		})
+		return nil
+	})
+}
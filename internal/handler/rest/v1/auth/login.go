@@ -1,7 +1,11 @@
 package auth
 
 import (
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
 	ctrlAuth "github.com/namf2001/go-backend-template/internal/controller/auth"
 	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
@@ -14,12 +18,20 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	TokenResponse
+}
+
+// MFARequiredResponse is returned by login instead of tokens when the user enabled two-factor authentication
+type MFARequiredResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`  // Exchanged with a code at /auth/mfa/verify
+	ExpiresIn   int64  `json:"expires_in"` // MFA token lifetime in seconds
 }
 
 // Login handles manual login
 // @Summary      User login
-// @Description  Authenticate user and return token
+// @Description  Authenticate user and return token. When the user enabled two-factor authentication an
+// @Description  auth.MFARequiredResponse is returned instead, to be completed at /auth/mfa/verify.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -27,6 +39,7 @@ type LoginResponse struct {
 // @Success      200  {object} auth.LoginResponse
 // @Failure      400  {object} httpserv.Error
 // @Failure      401  {object} httpserv.Error
+// @Failure      429  {object} httpserv.Error "account_locked, see the Retry-After header"
 // @Failure      500  {object} httpserv.Error
 // @Router       /auth/login [post]
 func (h *Handler) Login() http.HandlerFunc {
@@ -43,14 +56,52 @@ func (h *Handler) Login() http.HandlerFunc {
 		input := ctrlAuth.ValidationInput{
 			Email:    req.Email,
 			Password: req.Password,
+			IP:       clientIP(r),
 		}
 
-		token, err := h.ctrl.Login(r.Context(), input)
+		tokens, err := h.ctrl.Login(r.Context(), input)
 		if err != nil {
+			if lockedErr := lockedError(w, err); lockedErr != nil {
+				return lockedErr
+			}
 			return webErrInvalidCredentials
 		}
 
-		httpserv.RespondJSON(r.Context(), w, LoginResponse{Token: token})
+		respondLogin(w, r, tokens)
 		return nil
 	})
 }
+
+// respondLogin responds with the tokens of a login, or with the mfa_pending token if a second factor is required
+func respondLogin(w http.ResponseWriter, r *http.Request, tokens ctrlAuth.Tokens) {
+	if tokens.MFAToken != "" {
+		httpserv.RespondJSON(r.Context(), w, MFARequiredResponse{
+			MFARequired: true,
+			MFAToken:    tokens.MFAToken,
+			ExpiresIn:   int64(tokens.ExpiresIn.Seconds()),
+		})
+		return
+	}
+
+	httpserv.RespondJSON(r.Context(), w, LoginResponse{TokenResponse: newTokenResponse(tokens)})
+}
+
+// lockedError sets Retry-After and returns webErrAccountLocked if err is a LockedError
+func lockedError(w http.ResponseWriter, err error) error {
+	var locked *ctrlAuth.LockedError
+	if !errors.As(err, &locked) {
+		return nil
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+	return webErrAccountLocked
+}
+
+// clientIP returns the IP of the client. RemoteAddr is set from X-Forwarded-For by the RealIP middleware,
+// only for requests coming from a trusted proxy.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+)
+
+// Logout revokes the session of the current access token
+// @Summary      Logout
+// @Description  Revoke the current session and its refresh token
+// @Tags         auth
+// @Produce      json
+// @Success      204  {object} nil
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/logout [post]
+func (h *Handler) Logout() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		sessionID, ok := middleware.SessionIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		if err := h.ctrl.Logout(r.Context(), sessionID); err != nil {
+			return convertError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
+
+// LogoutAll revokes every session of the current user
+// @Summary      Logout everywhere
+// @Description  Revoke all sessions of the current user, including the current one
+// @Tags         auth
+// @Produce      json
+// @Success      204  {object} nil
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /auth/logout-all [post]
+func (h *Handler) LogoutAll() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		if err := h.ctrl.LogoutAll(r.Context(), userID); err != nil {
+			return convertError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
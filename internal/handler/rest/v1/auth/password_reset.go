@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"net/http"
+
+	ctrlAuth "github.com/namf2001/go-backend-template/internal/controller/auth"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/validator"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"` // Checked against the password policy
+}
+
+// ForgotPassword sends a password reset link
+// @Summary      Forgot password
+// @Description  Email a password reset link. The response is the same whether or not the email is registered.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input body auth.ForgotPasswordRequest true "Email"
+// @Success      200  {object} httpserv.Success
+// @Failure      400  {object} httpserv.Error
+// @Router       /auth/password/forgot [post]
+func (h *Handler) ForgotPassword() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var req ForgotPasswordRequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		if err := h.ctrl.ForgotPassword(r.Context(), req.Email); err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, httpserv.Success{Message: "If the email is registered, a password reset link has been sent"})
+		return nil
+	})
+}
+
+// ResetPassword sets a new password using a reset token
+// @Summary      Reset password
+// @Description  Set a new password using the token from the reset link. All sessions of the user are revoked. A password breaking the password policy is refused with every broken rule in fields.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input body auth.ResetPasswordRequest true "Reset info"
+// @Success      200  {object} httpserv.Success
+// @Failure      400  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Router       /auth/password/reset [post]
+func (h *Handler) ResetPassword() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var req ResetPasswordRequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		input := ctrlAuth.ResetPasswordInput{
+			Email:    req.Email,
+			Token:    req.Token,
+			Password: req.Password,
+		}
+
+		if err := h.ctrl.ResetPassword(r.Context(), input); err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, httpserv.Success{Message: "Password has been reset"})
+		return nil
+	})
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/validator"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type RefreshResponse struct {
+	TokenResponse
+}
+
+// Refresh handles refresh token rotation
+// @Summary      Refresh tokens
+// @Description  Exchange a refresh token for a new access token and refresh token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        input body auth.RefreshRequest true "Refresh token"
+// @Success      200  {object} auth.RefreshResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Router       /auth/refresh [post]
+func (h *Handler) Refresh() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var req RefreshRequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		tokens, err := h.ctrl.Refresh(r.Context(), req.RefreshToken)
+		if err != nil {
+			return convertError(err)
+		}
+
+		httpserv.RespondJSON(r.Context(), w, RefreshResponse{TokenResponse: newTokenResponse(tokens)})
+		return nil
+	})
+}
@@ -0,0 +1,20 @@
+package auth
+
+import (
+	ctrlAuth "github.com/namf2001/go-backend-template/internal/controller/auth"
+)
+
+// TokenResponse represents the tokens returned after a successful authentication
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // Access token lifetime in seconds
+}
+
+func newTokenResponse(tokens ctrlAuth.Tokens) TokenResponse {
+	return TokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    int64(tokens.ExpiresIn.Seconds()),
+	}
+}
@@ -0,0 +1,151 @@
+package authserver
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	ctrlAuthServer "github.com/namf2001/go-backend-template/internal/controller/authserver"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+)
+
+// AuthorizeRequest is the decision of the signed in user on an authorization request
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	Approved            bool   `json:"approved"`
+}
+
+// AuthorizeResponse tells the consent page where to send the user back to the client
+type AuthorizeResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// Authorize starts the authorization code grant
+// @Summary      OAuth authorization endpoint
+// @Description  Validate an authorization request (RFC 6749 section 4.1.1) and redirect the user to the consent page
+// @Description  with the request in the query. Errors are redirected to the client, unless the client or redirect_uri is invalid.
+// @Tags         oauth
+// @Produce      json
+// @Param        response_type          query  string  true   "code"
+// @Param        client_id              query  string  true   "Client ID"
+// @Param        redirect_uri           query  string  false  "Registered redirect URI, optional when the client has only one"
+// @Param        scope                  query  string  false  "Space separated scopes, every scope of the client when empty"
+// @Param        state                  query  string  false  "Opaque value returned to the client"
+// @Param        code_challenge         query  string  true   "PKCE challenge"
+// @Param        code_challenge_method  query  string  true   "S256"
+// @Success      302
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Router       /oauth/authorize [get]
+func (h *Handler) Authorize() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		q := r.URL.Query()
+		req, client, err := h.ctrl.ValidateAuthorization(r.Context(), ctrlAuthServer.AuthorizationRequest{
+			ResponseType:        q.Get("response_type"),
+			ClientID:            q.Get("client_id"),
+			RedirectURI:         q.Get("redirect_uri"),
+			Scope:               q.Get("scope"),
+			State:               q.Get("state"),
+			CodeChallenge:       q.Get("code_challenge"),
+			CodeChallengeMethod: q.Get("code_challenge_method"),
+		})
+		if err != nil {
+			redirectTo, err := errorRedirect(req, err)
+			if err != nil {
+				return err
+			}
+			http.Redirect(w, r, redirectTo, http.StatusFound)
+			return nil
+		}
+
+		http.Redirect(w, r, ctrlAuthServer.RedirectURL(h.consentURL, url.Values{
+			"response_type":         {req.ResponseType},
+			"client_id":             {req.ClientID},
+			"client_name":           {client.Name},
+			"redirect_uri":          {req.RedirectURI},
+			"scope":                 {req.Scope},
+			"state":                 {req.State},
+			"code_challenge":        {req.CodeChallenge},
+			"code_challenge_method": {req.CodeChallengeMethod},
+		}), http.StatusFound)
+		return nil
+	})
+}
+
+// Consent records the decision of the current user on an authorization request
+// @Summary      OAuth consent
+// @Description  Called by the consent page once the user approved or denied the request it was redirected with.
+// @Description  The response tells where to send the user: the client's redirect URI with a code, or with an error.
+// @Tags         oauth
+// @Accept       json
+// @Produce      json
+// @Param        input body authserver.AuthorizeRequest true "Authorization request and decision"
+// @Success      200  {object} authserver.AuthorizeResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      401  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /oauth/authorize [post]
+func (h *Handler) Consent() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		var body AuthorizeRequest
+		if err := httpserv.ParseJSON(r.Body, &body); err != nil {
+			return err
+		}
+
+		req := ctrlAuthServer.AuthorizationRequest{
+			ResponseType:        body.ResponseType,
+			ClientID:            body.ClientID,
+			RedirectURI:         body.RedirectURI,
+			Scope:               body.Scope,
+			State:               body.State,
+			CodeChallenge:       body.CodeChallenge,
+			CodeChallengeMethod: body.CodeChallengeMethod,
+		}
+		redirectTo, err := h.ctrl.Authorize(r.Context(), ctrlAuthServer.AuthorizeInput{
+			UserID:   userID,
+			Request:  req,
+			Approved: body.Approved,
+		})
+		if err != nil {
+			if redirectTo, err = errorRedirect(req, err); err != nil {
+				return err
+			}
+		}
+
+		httpserv.RespondJSON(r.Context(), w, AuthorizeResponse{RedirectTo: redirectTo})
+		return nil
+	})
+}
+
+// errorRedirect returns the redirect URI of the request with the error of RFC 6749 section 4.1.2.1.
+// It returns the error itself when the redirect URI cannot be trusted or the error is not the request's fault.
+func errorRedirect(req ctrlAuthServer.AuthorizationRequest, err error) (string, error) {
+	if errors.Is(err, ctrlAuthServer.ErrInvalidClient) || errors.Is(err, ctrlAuthServer.ErrInvalidRedirectURI) {
+		return "", convertError(err)
+	}
+
+	var webErr *httpserv.Error
+	if !errors.As(convertError(err), &webErr) {
+		return "", err
+	}
+
+	return ctrlAuthServer.RedirectURL(req.RedirectURI, url.Values{
+		"error":             {webErr.Code},
+		"error_description": {webErr.Desc},
+		"state":             {req.State},
+	}), nil
+}
@@ -0,0 +1,87 @@
+package users
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/namf2001/go-backend-template/internal/handler/middleware"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+)
+
+// AccountResponse represents a provider account linked to the current user
+type AccountResponse struct {
+	Provider          model.Provider `json:"provider"`
+	ProviderAccountID string         `json:"provider_account_id"`
+	Type              string         `json:"type"`
+}
+
+// ListAccountsResponse represents the response for listing the current user's linked accounts
+type ListAccountsResponse struct {
+	Accounts []AccountResponse `json:"accounts"`
+}
+
+// ListAccounts handles the listing of the current user's linked provider accounts
+// @Summary      List linked accounts
+// @Description  List the OAuth provider accounts linked to the authenticated user
+// @Tags         me
+// @Produce      json
+// @Success      200  {object} users.ListAccountsResponse
+// @Failure      401  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /me/accounts [get]
+func (h Handler) ListAccounts() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		accounts, err := h.userCtrl.ListAccounts(r.Context(), userID)
+		if err != nil {
+			return convertError(err)
+		}
+
+		resp := ListAccountsResponse{Accounts: make([]AccountResponse, 0, len(accounts))}
+		for _, a := range accounts {
+			resp.Accounts = append(resp.Accounts, AccountResponse{
+				Provider:          a.Provider,
+				ProviderAccountID: a.ProviderAccountID,
+				Type:              a.Type,
+			})
+		}
+
+		httpserv.RespondJSON(r.Context(), w, resp)
+		return nil
+	})
+}
+
+// UnlinkAccount handles unlinking a provider from the current user
+// @Summary      Unlink account
+// @Description  Unlink an OAuth provider from the authenticated user. The last way to sign in cannot be unlinked.
+// @Tags         me
+// @Param        provider  path  string  true  "Provider name"
+// @Success      204  {object} nil
+// @Failure      401  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Failure      409  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /me/accounts/{provider} [delete]
+func (h Handler) UnlinkAccount() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			return webErrUnauthenticated
+		}
+
+		provider := model.Provider(chi.URLParam(r, "provider"))
+		if err := h.userCtrl.UnlinkAccount(r.Context(), userID, provider); err != nil {
+			return convertError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
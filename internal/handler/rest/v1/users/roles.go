@@ -0,0 +1,124 @@
+package users
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/httpserv"
+	"github.com/namf2001/go-backend-template/internal/pkg/validator"
+)
+
+// ListRolesResponse represents the response for listing a user's roles
+type ListRolesResponse struct {
+	Roles []model.Role `json:"roles"`
+}
+
+// AssignRoleRequest represents the request for granting a role to a user
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// ListRoles handles the listing of a user's roles
+// @Summary      List user roles
+// @Description  List the roles granted to a user
+// @Tags         users
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object} users.ListRolesResponse
+// @Failure      400  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /users/{id}/roles [get]
+func (h Handler) ListRoles() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			return webErrInvalidID
+		}
+
+		roles, err := h.userCtrl.ListRoles(r.Context(), id)
+		if err != nil {
+			return convertError(err)
+		}
+
+		if roles == nil {
+			roles = []model.Role{}
+		}
+		httpserv.RespondJSON(r.Context(), w, ListRolesResponse{Roles: roles})
+		return nil
+	})
+}
+
+// AssignRole handles granting a role to a user
+// @Summary      Assign role
+// @Description  Grant a role to a user. The change applies once the user's access token is refreshed.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        id     path      int                      true  "User ID"
+// @Param        input  body      users.AssignRoleRequest  true  "Role"
+// @Success      204  {object} nil
+// @Failure      400  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /users/{id}/roles [post]
+func (h Handler) AssignRole() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			return webErrInvalidID
+		}
+
+		var req AssignRoleRequest
+		if err := httpserv.ParseJSON(r.Body, &req); err != nil {
+			return err
+		}
+
+		if err := validator.Validate(req); err != nil {
+			return webErrValidationFailed
+		}
+
+		if err := h.userCtrl.AssignRole(r.Context(), id, req.Role); err != nil {
+			return convertError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
+
+// RemoveRole handles revoking a role from a user
+// @Summary      Remove role
+// @Description  Revoke a role from a user. The change applies once the user's access token is refreshed.
+// @Tags         users
+// @Produce      json
+// @Param        id    path      int     true  "User ID"
+// @Param        role  path      string  true  "Role name"
+// @Success      204  {object} nil
+// @Failure      400  {object} httpserv.Error
+// @Failure      403  {object} httpserv.Error
+// @Failure      404  {object} httpserv.Error
+// @Failure      500  {object} httpserv.Error
+// @Security     BearerAuth
+// @Router       /users/{id}/roles/{role} [delete]
+func (h Handler) RemoveRole() http.HandlerFunc {
+	return httpserv.ErrHandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			return webErrInvalidID
+		}
+
+		if err := h.userCtrl.RemoveRole(r.Context(), id, chi.URLParam(r, "role")); err != nil {
+			return convertError(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	})
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Grant types of the OAuth2 authorization server
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeRefreshToken      = "refresh_token"
+)
+
+// OAuthClient is an application allowed to get tokens from this service's authorization server
+type OAuthClient struct {
+	ID           int64     `json:"id" db:"id"`
+	ClientID     string    `json:"client_id" db:"client_id"`
+	SecretHash   string    `json:"-" db:"secret_hash"` // Empty for public clients
+	Name         string    `json:"name" db:"name"`
+	RedirectURIs []string  `json:"redirect_uris" db:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types" db:"grant_types"`
+	Scopes       []string  `json:"scopes" db:"scopes"` // Permissions the client may ask for
+	CreatedBy    *int64    `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsConfidential reports whether the client authenticates with a secret
+func (c OAuthClient) IsConfidential() bool {
+	return c.SecretHash != ""
+}
+
+// AllowsGrant reports whether the client was registered for the grant type
+func (c OAuthClient) AllowsGrant(grantType string) bool {
+	return slices.Contains(c.GrantTypes, grantType)
+}
+
+// AllowsRedirectURI reports whether the redirect URI exactly matches a registered one
+func (c OAuthClient) AllowsRedirectURI(redirectURI string) bool {
+	return slices.Contains(c.RedirectURIs, redirectURI)
+}
+
+// OAuthAuthorizationCode is issued to a client once a user approved its authorization request
+type OAuthAuthorizationCode struct {
+	CodeHash      string     `json:"-" db:"code_hash"`
+	ClientID      string     `json:"client_id" db:"client_id"`
+	UserID        int64      `json:"user_id" db:"user_id"`
+	RedirectURI   string     `json:"redirect_uri" db:"redirect_uri"`
+	Scopes        []string   `json:"scopes" db:"scopes"`
+	CodeChallenge string     `json:"-" db:"code_challenge"` // S256 PKCE challenge
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UsedAt        *time.Time `json:"used_at" db:"used_at"`
+	FamilyID      string     `json:"-" db:"family_id"` // Token family issued for the code, revoked if it is replayed
+}
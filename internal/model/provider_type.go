@@ -7,14 +7,26 @@ const (
 	ProviderCredentials Provider = "Credentials"
 	// Google is for Google OAuth
 	ProviderGoogle Provider = "google"
+	// GitHub is for GitHub OAuth
+	ProviderGitHub Provider = "github"
+	// Microsoft is for Microsoft (Azure AD) OAuth
+	ProviderMicrosoft Provider = "microsoft"
+	// OIDC is for a generic OpenID Connect provider
+	ProviderOIDC Provider = "oidc"
+	// WebAuthn is for passkeys
+	ProviderWebAuthn Provider = "webauthn"
 )
 
 // String converts to string value
 func (p Provider) String() string {
 	return string(p)
 }
-	
+
 // IsValid checks if the provider is valid
 func (p Provider) IsValid() bool {
-	return p == ProviderCredentials || p == ProviderGoogle
+	switch p {
+	case ProviderCredentials, ProviderGoogle, ProviderGitHub, ProviderMicrosoft, ProviderOIDC, ProviderWebAuthn:
+		return true
+	}
+	return false
 }
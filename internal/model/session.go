@@ -2,10 +2,22 @@ package model
 
 import "time"
 
-// Session represents a user session
+// Session represents a refresh token issued to a user.
+// Rotating a refresh token revokes its session and creates a new one in the same family.
+// Sessions with a ClientID were granted to an OAuth client and are limited to their scopes.
 type Session struct {
-	ID           int64     `json:"id" db:"id"`
-	UserID       int64     `json:"userId" db:"userId"`
-	Expires      time.Time `json:"expires" db:"expires"`
-	SessionToken string    `json:"sessionToken" db:"sessionToken"`
+	ID           int64      `json:"id" db:"id"`
+	UserID       int64      `json:"userId" db:"userId"`
+	Expires      time.Time  `json:"expires" db:"expires"`
+	SessionToken string     `json:"-" db:"sessionToken"` // SHA-256 hash of the refresh token
+	FamilyID     string     `json:"familyId" db:"familyId"`
+	ClientID     string     `json:"clientId" db:"clientId"`
+	Scopes       []string   `json:"scopes" db:"scopes"`
+	RevokedAt    *time.Time `json:"revokedAt" db:"revokedAt"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsActive reports whether the session is neither revoked nor expired at the given time
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && s.Expires.After(now)
 }
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// WebAuthnCredential is a passkey registered by a user
+type WebAuthnCredential struct {
+	ID              int64      `json:"id" db:"id"`
+	AccountID       int64      `json:"-" db:"account_id"`
+	UserID          int64      `json:"user_id" db:"user_id"`
+	CredentialID    []byte     `json:"-" db:"credential_id"`
+	PublicKey       []byte     `json:"-" db:"public_key"` // COSE encoded
+	AttestationType string     `json:"-" db:"attestation_type"`
+	AAGUID          []byte     `json:"-" db:"aaguid"`
+	SignCount       uint32     `json:"-" db:"sign_count"`
+	Transports      []string   `json:"-" db:"transports"`
+	BackupEligible  bool       `json:"backup_eligible" db:"backup_eligible"`
+	BackupState     bool       `json:"backup_state" db:"backup_state"`
+	Name            string     `json:"name" db:"name"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt      *time.Time `json:"last_used_at" db:"last_used_at"`
+}
@@ -0,0 +1,77 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/namf2001/go-backend-template/config"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Cipher encrypts small secrets at rest with AES-256-GCM
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New returns a Cipher using a 32 byte key
+func New(key []byte) (*Cipher, error) {
+	if len(key) != 32 {
+		return nil, pkgerrors.New("encryption key must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// NewFromConfig returns a Cipher using the base64 encoded ENCRYPTION_KEY
+func NewFromConfig() (*Cipher, error) {
+	encoded := config.GetConfig().GetString("ENCRYPTION_KEY")
+	if encoded == "" {
+		return nil, pkgerrors.New("ENCRYPTION_KEY is required")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "ENCRYPTION_KEY must be base64 encoded")
+	}
+
+	return New(key)
+}
+
+// Encrypt returns the base64 encoded nonce and ciphertext of plaintext
+func (c *Cipher) Encrypt(plaintext []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", pkgerrors.WithStack(err)
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt returns the plaintext of a value returned by Encrypt
+func (c *Cipher) Decrypt(ciphertext string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return nil, pkgerrors.WithStack(ErrInvalidCiphertext)
+	}
+
+	nonce, sealed := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, pkgerrors.WithStack(ErrInvalidCiphertext)
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,34 @@
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher(t *testing.T) {
+	c, err := New(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	first, err := c.Encrypt([]byte("JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+	second, err := c.Encrypt([]byte("JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+	require.NotEqual(t, first, second, "a fresh nonce is used for every value")
+
+	plaintext, err := c.Decrypt(first)
+	require.NoError(t, err)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", string(plaintext))
+
+	other, err := New(bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+	_, err = other.Decrypt(first)
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = c.Decrypt("not base64!")
+	require.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = New([]byte("short"))
+	require.Error(t, err)
+}
@@ -0,0 +1,7 @@
+package encryption
+
+import "errors"
+
+var (
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
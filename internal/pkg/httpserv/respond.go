@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"go.uber.org/zap"
 )
 
 // Success is the response format when http handler succeeds
@@ -38,7 +39,7 @@ func RespondJSONWithHeaders(ctx context.Context, w http.ResponseWriter, obj inte
 		status = parsed.Status
 		if status == 0 {
 			status = http.StatusInternalServerError
-			logger.ERROR.Printf("[RespondJSON] got httpserv.Error without status")
+			logger.Error(ctx, "httpserv.Error without status", zap.String("code", parsed.Code))
 		}
 		respBytes, err = json.Marshal(parsed)
 	case error:
@@ -49,13 +50,13 @@ func RespondJSONWithHeaders(ctx context.Context, w http.ResponseWriter, obj inte
 	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		logger.ERROR.Printf("[RespondJSON] marshal failed: %v", err)
+		logger.Error(ctx, "marshal response failed", zap.Error(err))
 		return
 	}
 
 	// Write response
 	w.WriteHeader(status)
 	if _, err = w.Write(respBytes); err != nil {
-		logger.ERROR.Printf("[RespondJSON] write failed: %v", err)
+		logger.Error(ctx, "write response failed", zap.Error(err))
 	}
 }
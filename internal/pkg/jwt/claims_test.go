@@ -0,0 +1,105 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring_GenerateToken_RegisteredClaims(t *testing.T) {
+	k := loadTestKeyring(t, map[string]string{
+		"JWT_SECRET":         "test-secret",
+		"JWT_ISSUER":         "https://api.example.com",
+		"JWT_AUDIENCE":       "https://api.example.com, https://billing.example.com",
+		"JWT_SUBJECT_PREFIX": "user:",
+	})
+
+	first, err := k.GenerateToken(Subject{UserID: 1001})
+	require.NoError(t, err)
+	second, err := k.GenerateToken(Subject{UserID: 1001})
+	require.NoError(t, err)
+
+	claims, err := k.ParseToken(first)
+	require.NoError(t, err)
+	require.Equal(t, "https://api.example.com", claims.Issuer)
+	require.Equal(t, jwt.ClaimStrings{"https://api.example.com", "https://billing.example.com"}, claims.Audience)
+	require.Equal(t, "user:1001", claims.Subject)
+	require.NotNil(t, claims.NotBefore)
+	require.NotEmpty(t, claims.ID)
+
+	other, err := k.ParseToken(second)
+	require.NoError(t, err)
+	require.NotEqual(t, claims.ID, other.ID)
+}
+
+func TestKeyring_ParseToken_RegisteredClaims(t *testing.T) {
+	production := map[string]string{
+		"JWT_SECRET":   "shared-secret",
+		"APP_BASE_URL": "https://api.example.com",
+	}
+
+	type args struct {
+		signer map[string]string // Settings of the keyring minting the token
+		claims func(c *Claims)   // Alters a token minted by the production keyring
+		expErr bool
+	}
+	tcs := map[string]args{
+		"success": {},
+		"err - token minted for staging": {
+			signer: map[string]string{"JWT_SECRET": "shared-secret", "APP_BASE_URL": "https://staging.example.com"},
+			expErr: true,
+		},
+		"err - wrong audience": {
+			claims: func(c *Claims) { c.Audience = jwt.ClaimStrings{"https://other.example.com"} },
+			expErr: true,
+		},
+		"err - missing jti": {
+			claims: func(c *Claims) { c.ID = "" },
+			expErr: true,
+		},
+		"err - subject does not match the user": {
+			claims: func(c *Claims) { c.Subject = "1002" },
+			expErr: true,
+		},
+		"not before within leeway": {
+			claims: func(c *Claims) { c.NotBefore = jwt.NewNumericDate(time.Now().Add(10 * time.Second)) },
+		},
+		"err - not before beyond leeway": {
+			claims: func(c *Claims) { c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Minute)) },
+			expErr: true,
+		},
+		"err - expired beyond leeway": {
+			claims: func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) },
+			expErr: true,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			signer := production
+			if tc.signer != nil {
+				signer = tc.signer
+			}
+			minting := loadTestKeyring(t, signer)
+			token, err := minting.GenerateToken(Subject{UserID: 1001})
+			require.NoError(t, err)
+
+			if tc.claims != nil {
+				claims, err := minting.ParseToken(token)
+				require.NoError(t, err)
+				tc.claims(claims)
+				token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("shared-secret"))
+				require.NoError(t, err)
+			}
+
+			_, err = loadTestKeyring(t, production).ParseToken(token)
+			if tc.expErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
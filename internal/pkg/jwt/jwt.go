@@ -2,39 +2,110 @@ package jwt
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/pkg/utils"
 	pkgerrors "github.com/pkg/errors"
 )
 
+const defaultAccessDuration = 15 * time.Minute
+
+// Values of the token_use claim, a token is only accepted where its use is expected
+const (
+	TokenUseAccess = "access"
+	TokenUseMFA    = "mfa_pending"
+	TokenUseClient = "client"
+)
+
 type Claims struct {
-	UserID int64  `json:"user_id"`
-	Email  string `json:"email"`
+	UserID      int64    `json:"user_id"`
+	Email       string   `json:"email"`
+	TokenUse    string   `json:"token_use"`
+	SessionID   string   `json:"sid,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	ClientID    string   `json:"client_id,omitempty"` // OAuth client the token was issued to
+	Scope       string   `json:"scope,omitempty"`     // Space separated scopes granted to the OAuth client
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a new JWT token
-func GenerateToken(userID int64, email string) (string, error) {
-	cfg := config.GetConfig()
-	secretKey := cfg.GetString("JWT_SECRET")
-	accessDuration := cfg.GetDuration("JWT_ACCESS_DURATION")
+// Scopes returns the scopes of the scope claim
+func (c Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// Subject describes who an access token is issued to
+type Subject struct {
+	UserID      int64
+	Email       string
+	SessionID   string
+	Roles       []string
+	Permissions []string
+	ClientID    string   // Set when an OAuth client acts on behalf of the user
+	Scopes      []string // Scopes granted to the OAuth client
+}
+
+// AccessDuration returns how long an access token is valid for
+func AccessDuration() time.Duration {
+	accessDuration := config.GetConfig().GetDuration("JWT_ACCESS_DURATION")
 	if accessDuration == 0 {
-		accessDuration = 24 * time.Hour
+		accessDuration = defaultAccessDuration
+	}
+	return accessDuration
+}
+
+// GenerateToken generates a new JWT access token for the subject
+func GenerateToken(subject Subject) (string, error) {
+	k, err := keyring()
+	if err != nil {
+		return "", err
+	}
+	return k.GenerateToken(subject)
+}
+
+// GenerateToken generates a new JWT access token for the subject, signed with the keyring's signing key
+func (k *Keyring) GenerateToken(subject Subject) (string, error) {
+	return k.sign(Claims{
+		UserID:      subject.UserID,
+		Email:       subject.Email,
+		TokenUse:    TokenUseAccess,
+		SessionID:   subject.SessionID,
+		Roles:       subject.Roles,
+		Permissions: subject.Permissions,
+		ClientID:    subject.ClientID,
+		Scope:       strings.Join(subject.Scopes, " "),
+	}, AccessDuration())
+}
+
+// sign fills in the registered claims and signs the token with the keyring's signing key
+func (k *Keyring) sign(claims Claims, duration time.Duration) (string, error) {
+	// A unique ID lets a single token be denylisted
+	jti, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    k.issuer,
+		Subject:   k.subjectOf(claims),
+		Audience:  k.audience,
+		ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        jti,
 	}
 
-	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(accessDuration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	token := jwt.NewWithClaims(k.signing.method, claims)
+	if k.signing.id != "" {
+		token.Header["kid"] = k.signing.id
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secretKey))
+	tokenString, err := token.SignedString(k.signing.private)
 	if err != nil {
 		return "", pkgerrors.WithStack(err)
 	}
@@ -43,21 +114,70 @@ func GenerateToken(userID int64, email string) (string, error) {
 
 // ParseToken parses and validates a JWT token
 func ParseToken(tokenString string) (*Claims, error) {
-	cfg := config.GetConfig()
+	k, err := keyring()
+	if err != nil {
+		return nil, err
+	}
+	return k.ParseToken(tokenString)
+}
+
+// ParseToken parses and validates a JWT token against the keyring's verification keys
+func (k *Keyring) ParseToken(tokenString string) (*Claims, error) {
+	return k.parse(tokenString, TokenUseAccess)
+}
+
+// parse validates a token and checks that it was issued for the given use
+func (k *Keyring) parse(tokenString, use string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		v, ok := k.verification[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		// The algorithm is bound to the key, never to the token header
+		if token.Method.Alg() != v.method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(cfg.GetString("JWT_SECRET")), nil
-	})
+		return v.public, nil
+	}, k.parserOptions()...)
 
 	if err != nil {
 		return nil, pkgerrors.WithStack(err)
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid || claims.ID == "" || claims.Subject != k.subjectOf(*claims) || claims.TokenUse != use {
+		return nil, pkgerrors.WithStack(ErrInvalidToken)
+	}
+
+	return claims, nil
+}
+
+func (k *Keyring) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{AlgorithmHS256, AlgorithmRS256, AlgorithmEdDSA}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(k.leeway),
+	}
+	if k.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(k.issuer))
+	}
+	if len(k.audience) > 0 {
+		opts = append(opts, jwt.WithAudience(k.audience...))
 	}
+	return opts
+}
+
+// subject returns the sub claim of the user
+func (k *Keyring) subject(userID int64) string {
+	return k.subjectPrefix + strconv.FormatInt(userID, 10)
+}
 
-	return nil, pkgerrors.WithStack(ErrInvalidToken)
+// subjectOf returns the sub claim of a token, the client itself for client tokens
+func (k *Keyring) subjectOf(claims Claims) string {
+	if claims.TokenUse == TokenUseClient {
+		return clientSubjectPrefix + claims.ClientID
+	}
+	return k.subject(claims.UserID)
 }
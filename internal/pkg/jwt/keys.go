@@ -0,0 +1,260 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/namf2001/go-backend-template/config"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	defaultLeeway = 30 * time.Second
+)
+
+// key is a key used to sign or verify tokens
+type key struct {
+	id     string // kid header, empty for the shared secret
+	method jwt.SigningMethod
+	// private is used to sign, it is nil for verification-only keys
+	private interface{}
+	// public is used to verify, it is the secret itself for HS256
+	public interface{}
+}
+
+// Keyring holds the key tokens are signed with and every key tokens can be verified with.
+// Keeping the previous keys for verification lets keys be rotated without logging everyone out.
+// It also holds the registered claims tokens are issued and checked with.
+type Keyring struct {
+	signing      key
+	verification map[string]key
+
+	issuer        string
+	audience      []string
+	leeway        time.Duration
+	subjectPrefix string
+}
+
+var (
+	mu      sync.RWMutex
+	current *Keyring
+)
+
+// Init loads the keyring from config, it must be called again for config changes to apply
+func Init() error {
+	k, err := LoadKeyring()
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	current = k
+	return nil
+}
+
+// keyring returns the keyring loaded by Init, loading it on first use
+func keyring() (*Keyring, error) {
+	mu.RLock()
+	k := current
+	mu.RUnlock()
+	if k != nil {
+		return k, nil
+	}
+
+	if err := Init(); err != nil {
+		return nil, err
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	return current, nil
+}
+
+// LoadKeyring builds a keyring from config:
+//   - JWT_ALGORITHM: HS256 (default), RS256 or EdDSA
+//   - JWT_SECRET: the HS256 secret. With an asymmetric algorithm it is only used to verify
+//     tokens issued before the switch, unset it once they have expired.
+//   - JWT_SIGNING_KEY_FILE: PEM private key for RS256 and EdDSA
+//   - JWT_VERIFICATION_KEY_FILES: comma separated PEM public keys of previous signing keys
+//   - JWT_ISSUER: the iss claim, defaults to APP_BASE_URL. One of them is required, without an
+//     issuer iss and aud could not be checked and tokens of another environment would be accepted.
+//   - JWT_AUDIENCE: comma separated aud claim, defaults to the issuer. A token is accepted
+//     when one of its audiences is listed.
+//   - JWT_LEEWAY: allowed clock skew when checking exp, nbf and iat, defaults to 30s
+//   - JWT_SUBJECT_PREFIX: prepended to the user ID in the sub claim, e.g. "user:"
+func LoadKeyring() (*Keyring, error) {
+	cfg := config.GetConfig()
+	k := &Keyring{
+		verification:  map[string]key{},
+		issuer:        cfg.GetString("JWT_ISSUER"),
+		audience:      splitList(cfg.GetString("JWT_AUDIENCE")),
+		leeway:        defaultLeeway,
+		subjectPrefix: cfg.GetString("JWT_SUBJECT_PREFIX"),
+	}
+	if k.issuer == "" {
+		k.issuer = cfg.GetString("APP_BASE_URL")
+	}
+	if k.issuer == "" {
+		return nil, pkgerrors.New("JWT_ISSUER or APP_BASE_URL is required to check the iss and aud claims")
+	}
+	if len(k.audience) == 0 {
+		k.audience = []string{k.issuer}
+	}
+	if cfg.IsSet("JWT_LEEWAY") && cfg.GetString("JWT_LEEWAY") != "" {
+		k.leeway = cfg.GetDuration("JWT_LEEWAY")
+	}
+
+	if secret := cfg.GetString("JWT_SECRET"); secret != "" {
+		k.verification[""] = key{method: jwt.SigningMethodHS256, private: []byte(secret), public: []byte(secret)}
+	}
+
+	algorithm := cfg.GetString("JWT_ALGORITHM")
+	switch algorithm {
+	case "", AlgorithmHS256:
+		hs, ok := k.verification[""]
+		if !ok {
+			return nil, pkgerrors.New("JWT_SECRET is required for HS256")
+		}
+		k.signing = hs
+	case AlgorithmRS256, AlgorithmEdDSA:
+		signing, err := loadPrivateKey(cfg.GetString("JWT_SIGNING_KEY_FILE"))
+		if err != nil {
+			return nil, err
+		}
+		if signing.method.Alg() != algorithm {
+			return nil, pkgerrors.Errorf("JWT_SIGNING_KEY_FILE is a %s key, JWT_ALGORITHM is %s", signing.method.Alg(), algorithm)
+		}
+		k.signing = signing
+		k.verification[signing.id] = signing
+	default:
+		return nil, pkgerrors.Errorf("unsupported JWT_ALGORITHM %q", algorithm)
+	}
+
+	for _, path := range splitList(cfg.GetString("JWT_VERIFICATION_KEY_FILES")) {
+		verification, err := loadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		k.verification[verification.id] = verification
+	}
+
+	return k, nil
+}
+
+// splitList splits a comma separated setting, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func loadPrivateKey(path string) (key, error) {
+	if path == "" {
+		return key{}, pkgerrors.New("JWT_SIGNING_KEY_FILE is required for asymmetric algorithms")
+	}
+
+	block, err := readPEM(path)
+	if err != nil {
+		return key{}, err
+	}
+
+	var private interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return key{}, pkgerrors.Wrapf(err, "parse %s", path)
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return key{}, pkgerrors.Errorf("%s: unsupported private key", path)
+	}
+
+	k, err := newKey(signer.Public())
+	if err != nil {
+		return key{}, pkgerrors.Wrap(err, path)
+	}
+	k.private = private
+	return k, nil
+}
+
+func loadPublicKey(path string) (key, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return key{}, err
+	}
+
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return key{}, pkgerrors.Wrapf(err, "parse %s", path)
+	}
+
+	k, err := newKey(public)
+	if err != nil {
+		return key{}, pkgerrors.Wrap(err, path)
+	}
+	return k, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, pkgerrors.Errorf("%s: no PEM data", path)
+	}
+	return block, nil
+}
+
+// newKey returns the verification key for a public key. The kid is derived from the key itself
+// so every service computes the same one without extra configuration.
+func newKey(public interface{}) (key, error) {
+	var method jwt.SigningMethod
+	switch public.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return key{}, fmt.Errorf("unsupported public key type %T", public)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return key{}, pkgerrors.WithStack(err)
+	}
+	sum := sha256.Sum256(der)
+
+	return key{
+		id:     base64.RawURLEncoding.EncodeToString(sum[:12]),
+		method: method,
+		public: public,
+	}, nil
+}
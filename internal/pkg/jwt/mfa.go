@@ -0,0 +1,50 @@
+package jwt
+
+import (
+	"time"
+
+	"github.com/namf2001/go-backend-template/config"
+)
+
+const defaultMFADuration = 5 * time.Minute
+
+// MFADuration returns how long a user has to enter their second factor after the password
+func MFADuration() time.Duration {
+	d := config.GetConfig().GetDuration("MFA_TOKEN_DURATION")
+	if d == 0 {
+		d = defaultMFADuration
+	}
+	return d
+}
+
+// GenerateMFAToken generates a short lived token proving the password of the user was checked.
+// It is only accepted by ParseMFAToken, never as an access token.
+func GenerateMFAToken(userID int64) (string, error) {
+	k, err := keyring()
+	if err != nil {
+		return "", err
+	}
+	return k.GenerateMFAToken(userID)
+}
+
+// GenerateMFAToken generates an mfa_pending token signed with the keyring's signing key
+func (k *Keyring) GenerateMFAToken(userID int64) (string, error) {
+	return k.sign(Claims{
+		UserID:   userID,
+		TokenUse: TokenUseMFA,
+	}, MFADuration())
+}
+
+// ParseMFAToken parses and validates an mfa_pending token
+func ParseMFAToken(tokenString string) (*Claims, error) {
+	k, err := keyring()
+	if err != nil {
+		return nil, err
+	}
+	return k.ParseMFAToken(tokenString)
+}
+
+// ParseMFAToken parses and validates an mfa_pending token against the keyring's verification keys
+func (k *Keyring) ParseMFAToken(tokenString string) (*Claims, error) {
+	return k.parse(tokenString, TokenUseMFA)
+}
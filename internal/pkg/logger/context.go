@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Context field keys
+const (
+	FieldRequestID = "request_id"
+	FieldUserID    = "user_id"
+	FieldTraceID   = "trace_id"
+	FieldSpanID    = "span_id"
+)
+
+type contextKey struct{}
+
+// scope holds the fields logged with every entry of a context. It is shared by the contexts derived from the one
+// it was created in, so fields added deep in a request, e.g. the user ID, are logged by the request log too.
+type scope struct {
+	mu     sync.RWMutex
+	fields []zap.Field
+}
+
+// NewContext returns a context with a new scope holding the fields of ctx and the given fields
+func NewContext(ctx context.Context, fields ...zap.Field) context.Context {
+	s := &scope{fields: append(contextFields(ctx), fields...)}
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// AddFields adds the fields to the scope of ctx, it is a no-op when ctx has none
+func AddFields(ctx context.Context, fields ...zap.Field) {
+	s, ok := ctx.Value(contextKey{}).(*scope)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fields = append(s.fields, fields...)
+}
+
+// FromContext returns the default logger with the fields of ctx
+func FromContext(ctx context.Context) *zap.Logger {
+	l := L()
+	if fields := contextFields(ctx); len(fields) > 0 {
+		l = l.With(fields...)
+	}
+	return l
+}
+
+func contextFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+	s, ok := ctx.Value(contextKey{}).(*scope)
+	if !ok {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]zap.Field(nil), s.fields...)
+}
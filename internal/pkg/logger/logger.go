@@ -1,63 +1,121 @@
+// Package logger provides the application's structured logger. Entries are written as JSON (or console text for
+// local development) and carry the fields attached to the context, e.g. request, user and trace IDs.
 package logger
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"sync/atomic"
+
+	"github.com/namf2001/go-backend-template/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
-type ErrorType CustomLog
+// Output formats
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
 
 var (
-	writer io.Writer = os.Stderr
-	DEBUG            = New(writer, "DEBUG: ", log.LstdFlags|log.Lshortfile)
-	INFO             = New(writer, "INFO: ", log.LstdFlags)
-	ERROR            = ErrorType(New(writer, "ERROR: ", log.LstdFlags|log.Lshortfile))
+	level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	base  atomic.Pointer[zap.Logger]
 )
 
-type CustomLog struct {
-	Out    io.Writer
-	Prefix string
-	Flag   int
+func init() {
+	l, _ := New(os.Stderr, FormatJSON, level)
+	base.Store(l)
 }
 
-func New(out io.Writer, prefix string, flag int) CustomLog {
-	return CustomLog{Out: out, Prefix: prefix, Flag: flag}
-}
+// New returns a logger writing entries in the format to w, filtered by level
+func New(w io.Writer, format string, level zap.AtomicLevel) (*zap.Logger, error) {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "time"
+	encoderConfig.MessageKey = "message"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	encoderConfig.EncodeDuration = zapcore.MillisDurationEncoder
 
-func (cl *ErrorType) Printf(format string, v ...any) {
-	l := log.New(cl.Out, cl.Prefix, cl.Flag)
-	err := l.Output(2, fmt.Sprintf(format, v...))
-	if err != nil {
-		fmt.Println(err)
-		return
+	var encoder zapcore.Encoder
+	switch format {
+	case FormatJSON:
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case FormatConsole:
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidFormat, format)
 	}
+
+	core := zapcore.NewCore(encoder, zapcore.AddSync(w), level)
+	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)), nil
 }
 
-func (cl *CustomLog) Printf(format string, v ...any) {
-	l := log.New(cl.Out, cl.Prefix, cl.Flag)
-	err := l.Output(2, fmt.Sprintf(format, v...))
-	if err != nil {
-		fmt.Println(err)
-		return
+// Init replaces the default logger by one writing to stderr at LOG_LEVEL (debug, info, warn or error) in
+// LOG_FORMAT (json or console)
+func Init() error {
+	cfg := config.GetConfig()
+
+	format := cfg.GetString("LOG_FORMAT")
+	if format == "" {
+		format = FormatJSON
+	}
+	if lvl := cfg.GetString("LOG_LEVEL"); lvl != "" {
+		if err := SetLevel(lvl); err != nil {
+			return err
+		}
 	}
-}
 
-func (cl *CustomLog) Print(v ...any) {
-	l := log.New(cl.Out, cl.Prefix, cl.Flag)
-	err := l.Output(2, fmt.Sprint(v...))
+	l, err := New(os.Stderr, format, level)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
+	SetDefault(l)
+	return nil
 }
 
-func (cl *CustomLog) Println(v ...any) {
-	l := log.New(cl.Out, cl.Prefix, cl.Flag)
-	err := l.Output(2, fmt.Sprintln(v...))
-	if err != nil {
-		fmt.Println(err)
-		return
+// SetDefault replaces the default logger
+func SetDefault(l *zap.Logger) {
+	base.Store(l)
+}
+
+// L returns the default logger, use FromContext when a context is at hand
+func L() *zap.Logger {
+	return base.Load()
+}
+
+// Level returns the level of the default logger. It is safe to change at runtime and serves GET and PUT
+// {"level": "debug"} requests as an http.Handler.
+func Level() zap.AtomicLevel {
+	return level
+}
+
+// SetLevel changes the level of the default logger
+func SetLevel(lvl string) error {
+	if err := level.UnmarshalText([]byte(lvl)); err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidLevel, lvl)
 	}
+	return nil
+}
+
+// Debug logs a message at debug level with the context fields
+func Debug(ctx context.Context, msg string, fields ...zap.Field) {
+	FromContext(ctx).WithOptions(zap.AddCallerSkip(1)).Debug(msg, fields...)
+}
+
+// Info logs a message at info level with the context fields
+func Info(ctx context.Context, msg string, fields ...zap.Field) {
+	FromContext(ctx).WithOptions(zap.AddCallerSkip(1)).Info(msg, fields...)
+}
+
+// Warn logs a message at warn level with the context fields
+func Warn(ctx context.Context, msg string, fields ...zap.Field) {
+	FromContext(ctx).WithOptions(zap.AddCallerSkip(1)).Warn(msg, fields...)
+}
+
+// Error logs a message at error level with the context fields
+func Error(ctx context.Context, msg string, fields ...zap.Field) {
+	FromContext(ctx).WithOptions(zap.AddCallerSkip(1)).Error(msg, fields...)
 }
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer returns a Mailer that writes each email as an .eml file into dir. Use it for local development only.
+func NewFileMailer(dir, from string) Mailer {
+	return fileMailer{dir: dir, from: from}
+}
+
+// Send implements Mailer.
+func (m fileMailer) Send(_ context.Context, msg Message) error {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), sanitizeFileName(msg.To))
+	if err := os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, msg), 0644); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
+
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// buildMessage renders the message in RFC 5322 format
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	if from != "" {
+		b.WriteString("From: " + from + "\r\n")
+	}
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
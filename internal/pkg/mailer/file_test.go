@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := t.TempDir()
+	m := NewFileMailer(dir, "noreply@example.com")
+
+	err := m.Send(context.Background(), Message{
+		To:      "test1@example.com",
+		Subject: "Verify your email",
+		Body:    "Click the link",
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*test1@example.com.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(content), "From: noreply@example.com\r\n")
+	require.Contains(t, string(content), "To: test1@example.com\r\n")
+	require.Contains(t, string(content), "Subject: Verify your email\r\n")
+	require.Contains(t, string(content), "\r\n\r\nClick the link")
+}
@@ -0,0 +1,22 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type logMailer struct{}
+
+// NewLogMailer returns a Mailer that logs the recipient and subject of emails and drops them. The body is never
+// logged, it carries verification, reset and login links. Use the file driver to read emails during development.
+func NewLogMailer() Mailer {
+	return logMailer{}
+}
+
+// Send implements Mailer.
+func (logMailer) Send(ctx context.Context, msg Message) error {
+	logger.Info(ctx, "email sent", zap.String("to", msg.To), zap.String("subject", msg.Subject))
+	return nil
+}
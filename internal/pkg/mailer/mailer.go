@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/namf2001/go-backend-template/config"
+)
+
+// Message is an email to be delivered
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails
+type Mailer interface {
+	// Send delivers the message
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the Mailer selected by MAILER_DRIVER (log, file or smtp). It defaults to log in development
+// and is required in other environments, so emails are not silently dropped in production.
+func New() (Mailer, error) {
+	cfg := config.GetConfig()
+	from := cfg.GetString("MAILER_FROM")
+
+	switch driver := cfg.GetString("MAILER_DRIVER"); driver {
+	case "":
+		if env := cfg.GetString("APP_ENV"); env != "dev" {
+			return nil, fmt.Errorf("MAILER_DRIVER is required when APP_ENV is %s", env)
+		}
+		return NewLogMailer(), nil
+	case "log":
+		return NewLogMailer(), nil
+	case "file":
+		dir := cfg.GetString("MAILER_FILE_DIR")
+		if dir == "" {
+			dir = "tmp/mails"
+		}
+		return NewFileMailer(dir, from), nil
+	case "smtp":
+		return NewSMTPMailer(SMTPConfig{
+			Host:     cfg.GetString("MAILER_SMTP_HOST"),
+			Port:     cfg.GetInt("MAILER_SMTP_PORT"),
+			Username: cfg.GetString("MAILER_SMTP_USERNAME"),
+			Password: cfg.GetString("MAILER_SMTP_PASSWORD"),
+			From:     from,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer driver: %s", driver)
+	}
+}
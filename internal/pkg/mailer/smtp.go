@@ -0,0 +1,42 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// SMTPConfig holds the settings of an SMTP server
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+type smtpMailer struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPMailer returns a Mailer that delivers emails through an SMTP server
+func NewSMTPMailer(cfg SMTPConfig) Mailer {
+	return smtpMailer{cfg: cfg}
+}
+
+// Send implements Mailer.
+func (m smtpMailer) Send(_ context.Context, msg Message) error {
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", m.cfg.Host, m.cfg.Port)
+	if err := smtp.SendMail(addr, auth, m.cfg.From, []string{msg.To}, buildMessage(m.cfg.From, msg)); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StatsGetter is a DB connection pool reporting its statistics, e.g. *sql.DB
+type StatsGetter interface {
+	Stats() sql.DBStats
+}
+
+// dbStatsCollector collects the sql.DBStats of a connection pool
+type dbStatsCollector struct {
+	db StatsGetter
+
+	maxOpenConnections *prometheus.Desc
+	openConnections    *prometheus.Desc
+	inUseConnections   *prometheus.Desc
+	idleConnections    *prometheus.Desc
+	waitCount          *prometheus.Desc
+	waitDuration       *prometheus.Desc
+	maxIdleClosed      *prometheus.Desc
+	maxIdleTimeClosed  *prometheus.Desc
+	maxLifetimeClosed  *prometheus.Desc
+}
+
+// NewDBStatsCollector returns a collector of the statistics of a connection pool, labelled with its name
+func NewDBStatsCollector(db StatsGetter, name string) prometheus.Collector {
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc("db_pool_"+metric, help, nil, prometheus.Labels{"db_name": name})
+	}
+	return &dbStatsCollector{
+		db:                 db,
+		maxOpenConnections: desc("max_open_connections", "Maximum number of open connections to the database."),
+		openConnections:    desc("open_connections", "Number of established connections both in use and idle."),
+		inUseConnections:   desc("in_use_connections", "Number of connections currently in use."),
+		idleConnections:    desc("idle_connections", "Number of idle connections."),
+		waitCount:          desc("wait_count_total", "Total number of connections waited for."),
+		waitDuration:       desc("wait_duration_seconds_total", "Total time blocked waiting for a new connection."),
+		maxIdleClosed:      desc("max_idle_closed_total", "Total number of connections closed due to SetMaxIdleConns."),
+		maxIdleTimeClosed:  desc("max_idle_time_closed_total", "Total number of connections closed due to SetConnMaxIdleTime."),
+		maxLifetimeClosed:  desc("max_lifetime_closed_total", "Total number of connections closed due to SetConnMaxLifetime."),
+	}
+}
+
+// RegisterDB registers the collector of the statistics of a connection pool with the default registry
+func RegisterDB(db StatsGetter, name string) error {
+	return prometheus.Register(NewDBStatsCollector(db, name))
+}
+
+// Describe implements prometheus.Collector
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpenConnections
+	ch <- c.openConnections
+	ch <- c.inUseConnections
+	ch <- c.idleConnections
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxIdleTimeClosed
+	ch <- c.maxLifetimeClosed
+}
+
+// Collect implements prometheus.Collector
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.Stats()
+	ch <- prometheus.MustNewConstMetric(c.maxOpenConnections, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.openConnections, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUseConnections, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idleConnections, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxIdleTimeClosed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
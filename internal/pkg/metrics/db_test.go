@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePool sql.DBStats
+
+func (f fakePool) Stats() sql.DBStats {
+	return sql.DBStats(f)
+}
+
+func TestDBStatsCollector(t *testing.T) {
+	// Given
+	collector := NewDBStatsCollector(fakePool{
+		MaxOpenConnections: 25,
+		OpenConnections:    7,
+		InUse:              5,
+		Idle:               2,
+		WaitCount:          3,
+		WaitDuration:       1500 * time.Millisecond,
+	}, "app")
+
+	// When
+	err := testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP db_pool_in_use_connections Number of connections currently in use.
+# TYPE db_pool_in_use_connections gauge
+db_pool_in_use_connections{db_name="app"} 5
+# HELP db_pool_max_open_connections Maximum number of open connections to the database.
+# TYPE db_pool_max_open_connections gauge
+db_pool_max_open_connections{db_name="app"} 25
+# HELP db_pool_wait_duration_seconds_total Total time blocked waiting for a new connection.
+# TYPE db_pool_wait_duration_seconds_total counter
+db_pool_wait_duration_seconds_total{db_name="app"} 1.5
+`), "db_pool_in_use_connections", "db_pool_max_open_connections", "db_pool_wait_duration_seconds_total")
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 9, testutil.CollectAndCount(collector))
+}
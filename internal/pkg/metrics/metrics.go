@@ -0,0 +1,91 @@
+// Package metrics defines the application's Prometheus metrics. They are registered with the default registry
+// and served by Handler.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Results of the auth counters
+const (
+	ResultSuccess     = "success"
+	ResultFailure     = "failure"
+	ResultLocked      = "locked"       // Refused while the account or the IP is locked out
+	ResultMFARequired = "mfa_required" // Credentials accepted, a second factor is required
+)
+
+// Methods of the auth counters
+const (
+	MethodPassword  = "password"
+	MethodMagicLink = "magic_link"
+	MethodPasskey   = "passkey"
+	MethodOAuth     = "oauth"
+	MethodMFA       = "mfa"
+)
+
+var (
+	// HTTPRequestDuration observes the duration of the requests by chi route pattern, method and status
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of the HTTP requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// HTTPRequests counts the requests by chi route pattern, method and status
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Number of HTTP requests.",
+	}, []string{"route", "method", "status"})
+
+	// DBTxBeginRetries counts the retries of beginning a DB transaction
+	DBTxBeginRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "db_transaction_begin_retries_total",
+		Help: "Number of retries of beginning a DB transaction.",
+	})
+
+	// DBTxBeginFailures counts the DB transactions that could not begin after all the retries
+	DBTxBeginFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "db_transaction_begin_failures_total",
+		Help: "Number of DB transactions that could not begin.",
+	})
+
+	// DBQueryDuration observes the duration of the queries by repository method and SQL operation
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Duration of the DB queries.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"method", "operation"})
+
+	// DBQueryErrors counts the failed queries by repository method and SQL operation
+	DBQueryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_errors_total",
+		Help: "Number of failed DB queries.",
+	}, []string{"method", "operation"})
+
+	// AuthLogins counts the logins by method and result
+	AuthLogins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_logins_total",
+		Help: "Number of logins.",
+	}, []string{"method", "result"})
+
+	// AuthRegistrations counts the registrations by method and result
+	AuthRegistrations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_registrations_total",
+		Help: "Number of registrations.",
+	}, []string{"method", "result"})
+
+	// AuthOAuthCallbacks counts the OAuth provider callbacks by provider and result, the error code on failures
+	AuthOAuthCallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_oauth_callbacks_total",
+		Help: "Number of OAuth provider callbacks.",
+	}, []string{"provider", "result"})
+)
+
+// Handler serves the metrics of the default registry
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
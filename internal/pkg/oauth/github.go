@@ -0,0 +1,96 @@
+package oauth
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const githubAPIURL = "https://api.github.com"
+
+var githubScopes = []string{"read:user", "user:email"}
+
+// githubProvider reads the identity from the GitHub REST API since GitHub does not support OpenID Connect
+type githubProvider struct {
+	config *oauth2.Config
+	apiURL string
+}
+
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// NewGitHubProvider returns the GitHub provider
+func NewGitHubProvider(cfg Config) Provider {
+	return &githubProvider{
+		config: cfg.oauth2Config(github.Endpoint, githubScopes),
+		apiURL: githubAPIURL,
+	}
+}
+
+// Name implements Provider.
+func (p *githubProvider) Name() model.Provider {
+	return model.ProviderGitHub
+}
+
+// AuthCodeURL implements Provider.
+func (p *githubProvider) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) string {
+	return p.config.AuthCodeURL(state, opts...)
+}
+
+// Exchange implements Provider.
+func (p *githubProvider) Exchange(ctx context.Context, code string, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	token, err := p.config.Exchange(ctx, code, opts...)
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+	return token, nil
+}
+
+// UserInfo implements Provider. GitHub does not issue ID tokens so the nonce is not used.
+func (p *githubProvider) UserInfo(ctx context.Context, token *oauth2.Token, _ string) (UserInfo, error) {
+	client := p.config.Client(ctx, token)
+
+	var user githubUser
+	if err := getJSON(ctx, client, p.apiURL+"/user", &user); err != nil {
+		return UserInfo{}, err
+	}
+
+	// The profile only exposes the public email, the primary one is read from the emails API
+	var emails []githubEmail
+	if err := getJSON(ctx, client, p.apiURL+"/user/emails", &emails); err != nil {
+		return UserInfo{}, err
+	}
+
+	info := UserInfo{
+		ID:    strconv.FormatInt(user.ID, 10),
+		Name:  user.Name,
+		Image: user.AvatarURL,
+	}
+	if info.Name == "" {
+		info.Name = user.Login
+	}
+
+	for _, e := range emails {
+		if e.Primary {
+			info.Email = e.Email
+			info.EmailVerified = e.Verified
+			break
+		}
+	}
+
+	return info, nil
+}
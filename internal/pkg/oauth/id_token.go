@@ -0,0 +1,85 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const idTokenLeeway = time.Minute
+
+// idTokenClaims are the claims read from an OpenID Connect ID token
+type idTokenClaims struct {
+	Nonce         string       `json:"nonce"`
+	Email         string       `json:"email"`
+	EmailVerified flexibleBool `json:"email_verified"`
+	Name          string       `json:"name"`
+	Picture       string       `json:"picture"`
+	TenantID      string       `json:"tid"` // Microsoft only
+	jwt.RegisteredClaims
+}
+
+// flexibleBool accepts both true and "true" since some providers send email_verified as a string
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case bool:
+		*b = flexibleBool(t)
+	case string:
+		*b = flexibleBool(strings.EqualFold(t, "true"))
+	}
+	return nil
+}
+
+// idTokenVerifier checks ID tokens issued by a provider
+type idTokenVerifier struct {
+	issuer   string // May contain {tenantid}, replaced by the tid claim
+	clientID string
+	keys     *KeySet
+	now      func() time.Time
+}
+
+// verify checks the signature, issuer, audience, expiry and nonce of an ID token
+func (v *idTokenVerifier) verify(ctx context.Context, raw, nonce string) (idTokenClaims, error) {
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithAudience(v.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(idTokenLeeway),
+		jwt.WithTimeFunc(v.now),
+	)
+	if err != nil {
+		return idTokenClaims{}, pkgerrors.WithStack(fmt.Errorf("%w: %v", ErrInvalidIDToken, err))
+	}
+
+	issuer := strings.ReplaceAll(v.issuer, "{tenantid}", claims.TenantID)
+	if claims.Issuer != issuer {
+		return idTokenClaims{}, pkgerrors.WithStack(fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer))
+	}
+
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return idTokenClaims{}, pkgerrors.WithStack(fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken))
+	}
+
+	if claims.Subject == "" {
+		return idTokenClaims{}, pkgerrors.WithStack(fmt.Errorf("%w: missing subject", ErrInvalidIDToken))
+	}
+
+	return claims, nil
+}
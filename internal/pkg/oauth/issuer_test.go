@@ -0,0 +1,122 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// testIssuer is a local OpenID provider serving discovery, JWKS, token and userinfo endpoints
+type testIssuer struct {
+	*httptest.Server
+	t *testing.T
+
+	mu       sync.Mutex
+	keys     map[string]*rsa.PrivateKey
+	kid      string
+	jwksHits int
+	idToken  string
+	userInfo string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	s := &testIssuer{t: t, keys: map[string]*rsa.PrivateKey{}}
+	s.rotate("key-1")
+
+	mux := http.NewServeMux()
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(discoveryDocument{
+			Issuer:                s.URL,
+			AuthorizationEndpoint: s.URL + "/authorize",
+			TokenEndpoint:         s.URL + "/token",
+			UserInfoEndpoint:      s.URL + "/userinfo",
+			JWKSURI:               s.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.jwksHits++
+
+		var keys []jsonWebKey
+		for kid, k := range s.keys {
+			keys = append(keys, jsonWebKey{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"id_token":     s.idToken,
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(s.userInfo))
+	})
+
+	return s
+}
+
+// rotate adds a new signing key and uses it for the next tokens
+func (s *testIssuer) rotate(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(s.t, err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[kid] = key
+	s.kid = kid
+}
+
+// sign returns an ID token with default claims for the client, overridden by claims
+func (s *testIssuer) sign(claims jwt.MapClaims) string {
+	now := time.Now()
+	c := jwt.MapClaims{
+		"iss":            s.URL,
+		"aud":            "client",
+		"sub":            "abc",
+		"nonce":          "the-nonce",
+		"email":          "test1@example.com",
+		"email_verified": true,
+		"name":           "Test User",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		if v == nil {
+			delete(c, k)
+			continue
+		}
+		c[k] = v
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+	token.Header["kid"] = s.kid
+	raw, err := token.SignedString(s.keys[s.kid])
+	require.NoError(s.t, err)
+	return raw
+}
@@ -0,0 +1,194 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultJWKSCacheTTL   = time.Hour
+	defaultJWKSMinRefresh = time.Minute
+	jwksFetchTimeout      = 10 * time.Second
+)
+
+// jsonWebKey is a public key as published in a JWKS document
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet is a cached JWKS. Keys are refetched when the cache expires or when a token is signed
+// with an unknown kid, which is how providers roll their keys.
+type KeySet struct {
+	url        string
+	client     *http.Client
+	ttl        time.Duration
+	minRefresh time.Duration // Minimum delay between fetches caused by unknown kids
+	now        func() time.Time
+
+	// fetches makes concurrent refreshes share one request, the mutex is not held while it runs
+	// so cached keys keep being served while the provider is slow
+	fetches   singleflight.Group
+	mu        sync.Mutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+// NewKeySet returns a KeySet fetching the JWKS at url
+func NewKeySet(url string) *KeySet {
+	return &KeySet{
+		url:        url,
+		client:     http.DefaultClient,
+		ttl:        defaultJWKSCacheTTL,
+		minRefresh: defaultJWKSMinRefresh,
+		now:        time.Now,
+	}
+}
+
+// Key returns the public key with the given kid
+func (s *KeySet) Key(ctx context.Context, kid string) (interface{}, error) {
+	s.mu.Lock()
+	now := s.now()
+	key, ok := s.keys[kid]
+	stale := s.keys == nil || now.Sub(s.fetchedAt) >= s.ttl
+	// Refetch when the cache is stale, or when the kid is unknown and the last fetch is old enough
+	refresh := stale || (!ok && now.Sub(s.fetchedAt) >= s.minRefresh)
+	s.mu.Unlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if refresh {
+		if err := s.refresh(ctx); err != nil {
+			if ok {
+				// Keep serving the cached key if the provider is unreachable
+				return key, nil
+			}
+			return nil, err
+		}
+		s.mu.Lock()
+		key, ok = s.keys[kid]
+		s.mu.Unlock()
+	}
+
+	if !ok {
+		return nil, pkgerrors.WithStack(fmt.Errorf("%w: unknown key id %q", ErrInvalidIDToken, kid))
+	}
+
+	return key, nil
+}
+
+// refresh waits for the JWKS to be refetched. The fetch is shared by every caller, so it is not
+// cancelled with the context of the one that started it.
+func (s *KeySet) refresh(ctx context.Context) error {
+	ch := s.fetches.DoChan("", func() (interface{}, error) {
+		return nil, s.fetch(context.WithoutCancel(ctx))
+	})
+	select {
+	case res := <-ch:
+		return res.Err
+	case <-ctx.Done():
+		return pkgerrors.WithStack(ctx.Err())
+	}
+}
+
+func (s *KeySet) fetch(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return pkgerrors.WithStack(fmt.Errorf("jwks %s: unexpected status %d", s.url, resp.StatusCode))
+	}
+
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	keys := make(map[string]interface{}, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		// Skip key types that are not supported rather than failing the whole set
+		if key, err := k.publicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+	s.fetchedAt = s.now()
+	return nil
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, pkgerrors.WithStack(err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, pkgerrors.WithStack(err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, pkgerrors.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, pkgerrors.WithStack(err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, pkgerrors.WithStack(err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, pkgerrors.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -0,0 +1,127 @@
+package oauth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySet_Key(t *testing.T) {
+	ctx := context.Background()
+	iss := newTestIssuer(t)
+
+	now := time.Now()
+	ks := NewKeySet(iss.URL + "/jwks")
+	ks.now = func() time.Time { return now }
+
+	// First lookup fetches the set, the next one is served from the cache
+	_, err := ks.Key(ctx, "key-1")
+	require.NoError(t, err)
+	_, err = ks.Key(ctx, "key-1")
+	require.NoError(t, err)
+	require.Equal(t, 1, iss.jwksHits)
+
+	// An unknown kid right after a fetch does not hit the provider again
+	iss.rotate("key-2")
+	_, err = ks.Key(ctx, "key-2")
+	require.ErrorIs(t, err, ErrInvalidIDToken)
+	require.Equal(t, 1, iss.jwksHits)
+
+	// Once the minimum refresh delay passed the rotated key is fetched
+	now = now.Add(defaultJWKSMinRefresh)
+	_, err = ks.Key(ctx, "key-2")
+	require.NoError(t, err)
+	require.Equal(t, 2, iss.jwksHits)
+
+	// Cached keys are refetched when the cache expires
+	now = now.Add(defaultJWKSCacheTTL)
+	_, err = ks.Key(ctx, "key-1")
+	require.NoError(t, err)
+	require.Equal(t, 3, iss.jwksHits)
+}
+
+func TestKeySet_Key_ServesStaleKeyWhenUnreachable(t *testing.T) {
+	ctx := context.Background()
+	iss := newTestIssuer(t)
+
+	now := time.Now()
+	ks := NewKeySet(iss.URL + "/jwks")
+	ks.now = func() time.Time { return now }
+
+	_, err := ks.Key(ctx, "key-1")
+	require.NoError(t, err)
+
+	iss.Close()
+	now = now.Add(defaultJWKSCacheTTL)
+	_, err = ks.Key(ctx, "key-1")
+	require.NoError(t, err)
+}
+
+func TestKeySet_Key_RefreshDoesNotBlockCachedKeys(t *testing.T) {
+	ctx := context.Background()
+	iss := newTestIssuer(t)
+
+	// The second fetch hangs until released, like a slow provider
+	var hits atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) > 1 {
+			started <- struct{}{}
+			<-release
+		}
+		resp, err := http.Get(iss.URL + "/jwks")
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(slow.Close)
+
+	now := time.Now()
+	ks := NewKeySet(slow.URL)
+	ks.now = func() time.Time { return now }
+	_, err := ks.Key(ctx, "key-1")
+	require.NoError(t, err)
+
+	// Lookups of a rotated kid share one fetch
+	iss.rotate("key-2")
+	now = now.Add(defaultJWKSMinRefresh)
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ks.Key(ctx, "key-2")
+			errs <- err
+		}()
+	}
+	<-started
+
+	// The cached key is served while the fetch is in flight
+	_, err = ks.Key(ctx, "key-1")
+	require.NoError(t, err)
+
+	// A caller giving up does not cancel the fetch for the others
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = ks.Key(cancelled, "key-2")
+	require.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(2), hits.Load())
+}
@@ -0,0 +1,32 @@
+package oauth
+
+import (
+	"fmt"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"golang.org/x/oauth2/microsoft"
+)
+
+const microsoftUserInfoURL = "https://graph.microsoft.com/oidc/userinfo"
+
+// NewMicrosoftProvider returns the Microsoft (Azure AD / Entra ID) provider for the tenant.
+// Use "common" to accept both work and personal accounts.
+func NewMicrosoftProvider(tenant string, cfg Config) Provider {
+	// Multi-tenant endpoints issue tokens whose issuer is the tenant of the user
+	issuerTenant := tenant
+	switch tenant {
+	case "common", "organizations", "consumers":
+		issuerTenant = "{tenantid}"
+	}
+
+	return newOIDCProvider(
+		model.ProviderMicrosoft,
+		cfg,
+		microsoft.AzureADEndpoint(tenant),
+		fmt.Sprintf("https://login.microsoftonline.com/%s/v2.0", issuerTenant),
+		fmt.Sprintf("https://login.microsoftonline.com/%s/discovery/v2.0/keys", tenant),
+		microsoftUserInfoURL,
+		// Azure AD does not verify the email claim, any tenant admin can set it
+		false,
+	)
+}
@@ -0,0 +1,110 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/namf2001/go-backend-template/config"
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Registry holds the enabled providers by name
+type Registry struct {
+	providers map[model.Provider]Provider
+}
+
+// NewRegistry returns a registry of the given providers
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: make(map[model.Provider]Provider, len(providers))}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+	return r
+}
+
+// Get returns the provider with the given name
+func (r *Registry) Get(name model.Provider) (Provider, error) {
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, pkgerrors.WithStack(ErrProviderNotFound)
+	}
+	return p, nil
+}
+
+// Names returns the names of the enabled providers, sorted
+func (r *Registry) Names() []model.Provider {
+	names := make([]model.Provider, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// NewRegistryFromConfig enables every provider whose <PROVIDER>_CLIENT_ID is configured
+func NewRegistryFromConfig(ctx context.Context) (*Registry, error) {
+	cfg := config.GetConfig()
+	var providers []Provider
+
+	if c, ok := providerConfig(model.ProviderGoogle); ok {
+		providers = append(providers, NewGoogleProvider(c))
+	}
+
+	if c, ok := providerConfig(model.ProviderGitHub); ok {
+		providers = append(providers, NewGitHubProvider(c))
+	}
+
+	if c, ok := providerConfig(model.ProviderMicrosoft); ok {
+		tenant := cfg.GetString("MICROSOFT_TENANT")
+		if tenant == "" {
+			tenant = "common"
+		}
+		providers = append(providers, NewMicrosoftProvider(tenant, c))
+	}
+
+	if c, ok := providerConfig(model.ProviderOIDC); ok {
+		issuer := cfg.GetString("OIDC_ISSUER")
+		if issuer == "" {
+			return nil, pkgerrors.New("OIDC_ISSUER is required when OIDC_CLIENT_ID is set")
+		}
+		p, err := NewOIDCProvider(ctx, issuer, c)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+
+	return NewRegistry(providers...), nil
+}
+
+// providerConfig reads the <PROVIDER>_CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL and _SCOPES settings
+func providerConfig(name model.Provider) (Config, bool) {
+	cfg := config.GetConfig()
+	prefix := strings.ToUpper(name.String())
+
+	c := Config{
+		ClientID:     cfg.GetString(prefix + "_CLIENT_ID"),
+		ClientSecret: cfg.GetString(prefix + "_CLIENT_SECRET"),
+		RedirectURL:  cfg.GetString(prefix + "_REDIRECT_URL"),
+	}
+	if c.ClientID == "" {
+		return Config{}, false
+	}
+
+	if scopes := cfg.GetString(prefix + "_SCOPES"); scopes != "" {
+		c.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+	}
+
+	if c.RedirectURL == "" {
+		baseURL := strings.TrimRight(cfg.GetString("APP_BASE_URL"), "/")
+		if baseURL == "" {
+			baseURL = "http://localhost:8080"
+		}
+		c.RedirectURL = fmt.Sprintf("%s/api/v1/auth/%s/callback", baseURL, name)
+	}
+
+	return c, true
+}
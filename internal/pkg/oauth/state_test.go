@@ -0,0 +1,93 @@
+package oauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateCookie_Verify(t *testing.T) {
+	type args struct {
+		provider model.Provider
+		state    func(req AuthRequest) string
+		cookie   func(c *http.Cookie)
+		advance  time.Duration
+		expErr   error
+	}
+	tcs := map[string]args{
+		"success": {
+			provider: model.ProviderGoogle,
+			state:    func(req AuthRequest) string { return req.State },
+		},
+		"state_mismatch": {
+			provider: model.ProviderGoogle,
+			state:    func(req AuthRequest) string { return "random-string" },
+			expErr:   ErrInvalidState,
+		},
+		"provider_mismatch": {
+			provider: model.ProviderGitHub,
+			state:    func(req AuthRequest) string { return req.State },
+			expErr:   ErrInvalidState,
+		},
+		"tampered_cookie": {
+			provider: model.ProviderGoogle,
+			state:    func(req AuthRequest) string { return req.State },
+			cookie:   func(c *http.Cookie) { c.Value = "x" + c.Value },
+			expErr:   ErrInvalidState,
+		},
+		"expired": {
+			provider: model.ProviderGoogle,
+			state:    func(req AuthRequest) string { return req.State },
+			advance:  defaultStateTTL + time.Second,
+			expErr:   ErrInvalidState,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			now := time.Now()
+			s := NewStateCookie([]byte("secret"), false)
+			s.now = func() time.Time { return now }
+
+			w := httptest.NewRecorder()
+			issued, err := s.Issue(w, AuthRequest{Provider: model.ProviderGoogle, LinkUserID: 1})
+			require.NoError(t, err)
+			require.NotEmpty(t, issued.State)
+			require.NotEmpty(t, issued.CodeVerifier)
+			require.NotEmpty(t, issued.Nonce)
+
+			cookies := w.Result().Cookies()
+			require.Len(t, cookies, 1)
+			require.True(t, cookies[0].HttpOnly)
+			if tc.cookie != nil {
+				tc.cookie(cookies[0])
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/auth/google/callback", nil)
+			r.AddCookie(cookies[0])
+			now = now.Add(tc.advance)
+
+			w = httptest.NewRecorder()
+			rs, err := s.Verify(w, r, tc.provider, tc.state(issued))
+			require.Equal(t, -1, w.Result().Cookies()[0].MaxAge)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, issued, rs)
+		})
+	}
+}
+
+func TestStateCookie_Verify_MissingCookie(t *testing.T) {
+	s := NewStateCookie([]byte("secret"), false)
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/auth/google/callback?state=x", nil)
+
+	_, err := s.Verify(httptest.NewRecorder(), r, model.ProviderGoogle, "x")
+	require.ErrorIs(t, err, ErrInvalidState)
+}
@@ -0,0 +1,102 @@
+package passkey
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/namf2001/go-backend-template/config"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const (
+	defaultBaseURL = "http://localhost:8080"
+	defaultTimeout = 5 * time.Minute
+)
+
+// Config describes the relying party passkeys are bound to
+type Config struct {
+	RPID      string        // Domain the passkeys are scoped to, e.g. example.com
+	RPName    string        // Name shown by the authenticator
+	RPOrigins []string      // Origins allowed to run the ceremonies, e.g. https://app.example.com
+	RequireUV bool          // Require user verification (PIN or biometrics) instead of preferring it
+	Timeout   time.Duration // How long a ceremony may take, defaults to 5 minutes
+}
+
+// New returns a WebAuthn relying party. Passkeys are discoverable credentials so users
+// can sign in without typing their email first.
+func New(cfg Config) (*webauthn.WebAuthn, error) {
+	userVerification := protocol.VerificationPreferred
+	if cfg.RequireUV {
+		userVerification = protocol.VerificationRequired
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	timeout := webauthn.TimeoutConfig{Enforce: true, Timeout: cfg.Timeout, TimeoutUVD: cfg.Timeout}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:                  cfg.RPID,
+		RPDisplayName:         cfg.RPName,
+		RPOrigins:             cfg.RPOrigins,
+		AttestationPreference: protocol.PreferNoAttestation,
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			UserVerification:   userVerification,
+		},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        timeout,
+			Registration: timeout,
+		},
+	})
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+	return w, nil
+}
+
+// NewFromConfig returns a WebAuthn relying party configured from WEBAUTHN_* settings,
+// defaulting to the host and origin of APP_BASE_URL
+func NewFromConfig() (*webauthn.WebAuthn, error) {
+	cfg := config.GetConfig()
+
+	baseURL := cfg.GetString("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "APP_BASE_URL must be a valid URL")
+	}
+
+	rpID := cfg.GetString("WEBAUTHN_RP_ID")
+	if rpID == "" {
+		rpID = u.Hostname()
+	}
+
+	rpName := cfg.GetString("WEBAUTHN_RP_NAME")
+	if rpName == "" {
+		rpName = rpID
+	}
+
+	var origins []string
+	for _, origin := range strings.Split(cfg.GetString("WEBAUTHN_RP_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		origins = []string{u.Scheme + "://" + u.Host}
+	}
+
+	return New(Config{
+		RPID:      rpID,
+		RPName:    rpName,
+		RPOrigins: origins,
+		RequireUV: cfg.GetBool("WEBAUTHN_REQUIRE_USER_VERIFICATION"),
+		Timeout:   cfg.GetDuration("WEBAUTHN_TIMEOUT"),
+	})
+}
@@ -0,0 +1,96 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// hashPrefixLength is the length of the SHA-1 prefix ranges are split on, as in the Pwned Passwords range API
+const hashPrefixLength = 5
+
+// BreachedChecker tells whether a password is known from data breaches
+type BreachedChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// BreachedRanges checks passwords against a local copy of the Pwned Passwords ranges: a directory with
+// one file per 5 hex characters prefix of the SHA-1 hashes, named after the prefix (e.g. 21BD1 or 21BD1.txt),
+// with a SUFFIX:COUNT line per hash, as served by the k-anonymity range API. Such a copy is made with
+// the haveibeenpwned-downloader. Only the file of the password's prefix is read, so nothing is kept in memory
+// and the password never leaves the server.
+type BreachedRanges struct {
+	dir      string
+	minCount int
+}
+
+// NewBreachedRanges returns a BreachedRanges reading dir. Passwords seen fewer than minCount
+// times are accepted, every breached password is rejected when minCount is 0.
+func NewBreachedRanges(dir string, minCount int) (*BreachedRanges, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "breached passwords directory")
+	}
+	if !info.IsDir() {
+		return nil, pkgerrors.Errorf("breached passwords directory %s is not a directory", dir)
+	}
+
+	if minCount < 1 {
+		minCount = 1
+	}
+	return &BreachedRanges{dir: dir, minCount: minCount}, nil
+}
+
+// IsBreached implements BreachedChecker.
+func (b *BreachedRanges) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+
+	f, err := b.open(prefix)
+	if err != nil {
+		return false, err
+	}
+	if f == nil {
+		return false, nil
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lineSuffix, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(lineSuffix, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return false, pkgerrors.Wrapf(err, "breached passwords range %s", prefix)
+		}
+		return n >= b.minCount, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return false, pkgerrors.WithStack(err)
+	}
+
+	return false, nil
+}
+
+// open opens the range file of a prefix, nil when there is none
+func (b *BreachedRanges) open(prefix string) (*os.File, error) {
+	for _, name := range []string{prefix, prefix + ".txt"} {
+		f, err := os.Open(filepath.Join(b.dir, name))
+		if err == nil {
+			return f, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, pkgerrors.WithStack(err)
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,8 @@
+package password
+
+import "errors"
+
+var (
+	ErrMismatch        = errors.New("password does not match")
+	ErrUnsupportedHash = errors.New("unsupported password hash")
+)
@@ -0,0 +1,90 @@
+package password
+
+import (
+	"strings"
+
+	"github.com/namf2001/go-backend-template/config"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Algorithm names of PASSWORD_HASH_ALGORITHM
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+// Hasher hashes passwords into self-describing strings: the algorithm and its parameters
+// are part of the hash, so it can always be verified after the configuration changed.
+type Hasher interface {
+	// Hash returns the hash of a password
+	Hash(password string) (string, error)
+
+	// Verify checks a password against a hash, ErrMismatch when it does not match
+	Verify(hash, password string) error
+
+	// NeedsRehash reports whether the hash was made with another algorithm or other parameters
+	// than the ones the Hasher hashes with
+	NeedsRehash(hash string) bool
+}
+
+// algorithm is a Hasher for a single hash format
+type algorithm interface {
+	Hasher
+	// identifies reports whether the hash is of the algorithm's format
+	identifies(hash string) bool
+}
+
+// multi hashes with the current algorithm and verifies hashes of every supported one
+type multi struct {
+	current algorithm
+	all     []algorithm
+}
+
+// New returns a Hasher hashing with the given algorithm, Bcrypt or Argon2id, and verifying hashes of both
+func New(current Hasher) (Hasher, error) {
+	a, ok := current.(algorithm)
+	if !ok {
+		return nil, pkgerrors.WithStack(ErrUnsupportedHash)
+	}
+	return multi{current: a, all: []algorithm{a, Bcrypt{}, Argon2id{}}}, nil
+}
+
+// NewFromConfig returns a Hasher for the PASSWORD_HASH_ALGORITHM, argon2id (default) or bcrypt.
+// Its parameters are read from BCRYPT_COST and ARGON2_MEMORY (KiB), ARGON2_ITERATIONS and ARGON2_PARALLELISM,
+// unset values use the defaults of Bcrypt and Argon2id.
+func NewFromConfig() (Hasher, error) {
+	cfg := config.GetConfig()
+
+	switch strings.ToLower(cfg.GetString("PASSWORD_HASH_ALGORITHM")) {
+	case "", AlgorithmArgon2id:
+		return New(Argon2id{
+			Memory:      cfg.GetUint32("ARGON2_MEMORY"),
+			Iterations:  cfg.GetUint32("ARGON2_ITERATIONS"),
+			Parallelism: uint8(cfg.GetUint("ARGON2_PARALLELISM")),
+		})
+	case AlgorithmBcrypt:
+		return New(Bcrypt{Cost: cfg.GetInt("BCRYPT_COST")})
+	default:
+		return nil, pkgerrors.Errorf("unsupported PASSWORD_HASH_ALGORITHM %q", cfg.GetString("PASSWORD_HASH_ALGORITHM"))
+	}
+}
+
+// Hash implements Hasher.
+func (m multi) Hash(password string) (string, error) {
+	return m.current.Hash(password)
+}
+
+// Verify implements Hasher.
+func (m multi) Verify(hash, password string) error {
+	for _, a := range m.all {
+		if a.identifies(hash) {
+			return a.Verify(hash, password)
+		}
+	}
+	return pkgerrors.WithStack(ErrUnsupportedHash)
+}
+
+// NeedsRehash implements Hasher.
+func (m multi) NeedsRehash(hash string) bool {
+	return m.current.NeedsRehash(hash)
+}
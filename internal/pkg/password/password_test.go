@@ -0,0 +1,121 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Small parameters keep the tests fast
+var (
+	testArgon2id = Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1}
+	testBcrypt   = Bcrypt{Cost: 4}
+)
+
+func TestHasher(t *testing.T) {
+	bcryptHash, err := testBcrypt.Hash("s3cret-password")
+	require.NoError(t, err)
+	argon2idHash, err := testArgon2id.Hash("s3cret-password")
+	require.NoError(t, err)
+	require.Regexp(t, `^\$argon2id\$v=19\$m=1024,t=1,p=1\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`, argon2idHash)
+
+	type args struct {
+		current        Hasher
+		hash           string
+		password       string
+		expErr         error
+		expNeedsRehash bool
+	}
+	tcs := map[string]args{
+		"argon2id": {
+			current:  testArgon2id,
+			hash:     argon2idHash,
+			password: "s3cret-password",
+		},
+		"bcrypt": {
+			current:  testBcrypt,
+			hash:     bcryptHash,
+			password: "s3cret-password",
+		},
+		"bcrypt hash with argon2id current": {
+			current:        testArgon2id,
+			hash:           bcryptHash,
+			password:       "s3cret-password",
+			expNeedsRehash: true,
+		},
+		"argon2id hash with bcrypt current": {
+			current:        testBcrypt,
+			hash:           argon2idHash,
+			password:       "s3cret-password",
+			expNeedsRehash: true,
+		},
+		"bcrypt cost increased": {
+			current:        Bcrypt{Cost: 5},
+			hash:           bcryptHash,
+			password:       "s3cret-password",
+			expNeedsRehash: true,
+		},
+		"argon2id memory increased": {
+			current:        Argon2id{Memory: 2048, Iterations: 1, Parallelism: 1},
+			hash:           argon2idHash,
+			password:       "s3cret-password",
+			expNeedsRehash: true,
+		},
+		"err - argon2id mismatch": {
+			current:  testArgon2id,
+			hash:     argon2idHash,
+			password: "wrong-password",
+			expErr:   ErrMismatch,
+		},
+		"err - bcrypt mismatch": {
+			current:        testArgon2id,
+			hash:           bcryptHash,
+			password:       "wrong-password",
+			expErr:         ErrMismatch,
+			expNeedsRehash: true,
+		},
+		"err - unknown format": {
+			current:        testArgon2id,
+			hash:           "$scrypt$ln=15,r=8,p=1$c2FsdA$a2V5",
+			password:       "s3cret-password",
+			expErr:         ErrUnsupportedHash,
+			expNeedsRehash: true,
+		},
+		"err - corrupted argon2id": {
+			current:        testArgon2id,
+			hash:           "$argon2id$v=19$m=1024,t=1$c2FsdA$a2V5",
+			password:       "s3cret-password",
+			expErr:         ErrUnsupportedHash,
+			expNeedsRehash: true,
+		},
+		"err - empty hash": {
+			current:        testArgon2id,
+			password:       "s3cret-password",
+			expErr:         ErrUnsupportedHash,
+			expNeedsRehash: true,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			h, err := New(tc.current)
+			require.NoError(t, err)
+
+			err = h.Verify(tc.hash, tc.password)
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expNeedsRehash, h.NeedsRehash(tc.hash))
+		})
+	}
+}
+
+func TestArgon2id_HashIsSalted(t *testing.T) {
+	first, err := testArgon2id.Hash("s3cret-password")
+	require.NoError(t, err)
+	second, err := testArgon2id.Hash("s3cret-password")
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+}
@@ -0,0 +1,104 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 seed of the RFC 6238 test vectors
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to 6 digits
+	tcs := map[string]struct {
+		unix    int64
+		expCode string
+	}{
+		"59":          {unix: 59, expCode: "287082"},
+		"1111111109":  {unix: 1111111109, expCode: "081804"},
+		"1111111111":  {unix: 1111111111, expCode: "050471"},
+		"1234567890":  {unix: 1234567890, expCode: "005924"},
+		"2000000000":  {unix: 2000000000, expCode: "279037"},
+		"20000000000": {unix: 20000000000, expCode: "353130"},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			code, err := Code(rfcSecret, Step(time.Unix(tc.unix, 0)))
+			require.NoError(t, err)
+			require.Equal(t, tc.expCode, code)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	type args struct {
+		code    string
+		skew    int
+		expOK   bool
+		expStep int64
+	}
+	tcs := map[string]args{
+		"current step": {
+			code:    "050471",
+			expOK:   true,
+			expStep: Step(now),
+		},
+		"spaces are ignored": {
+			code:    "050 471",
+			expOK:   true,
+			expStep: Step(now),
+		},
+		"previous step within skew": {
+			code:    mustCode(t, Step(now)-1),
+			skew:    1,
+			expOK:   true,
+			expStep: Step(now) - 1,
+		},
+		"previous step without skew": {
+			code: mustCode(t, Step(now)-1),
+		},
+		"wrong code": {
+			code: "123456",
+			skew: 1,
+		},
+		"wrong length": {
+			code: "05047",
+			skew: 1,
+		},
+	}
+
+	for desc, tc := range tcs {
+		t.Run(desc, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tc.code, now, tc.skew)
+			require.Equal(t, tc.expOK, ok)
+			require.Equal(t, tc.expStep, step)
+		})
+	}
+}
+
+func TestURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+
+	u, err := url.Parse(URI("Example", "test1@example.com", secret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/Example:test1@example.com", u.Path)
+	require.Equal(t, secret, u.Query().Get("secret"))
+	require.Equal(t, "Example", u.Query().Get("issuer"))
+}
+
+func mustCode(t *testing.T, step int64) string {
+	code, err := Code(rfcSecret, step)
+	require.NoError(t, err)
+	return code
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestNewExporter(t *testing.T) {
+	type args struct {
+		name   string
+		expErr error
+	}
+	tcs := map[string]args{
+		"success - otlp":   {name: ExporterOTLP},
+		"success - stdout": {name: ExporterStdout},
+		"success - none":   {name: ExporterNone},
+		"err - unknown":    {name: "zipkin", expErr: ErrInvalidConfig},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// When
+			exporter, err := NewExporter(context.Background(), tc.name)
+
+			// Then
+			if tc.expErr != nil {
+				require.ErrorIs(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, exporter)
+			require.NoError(t, exporter.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestStartEnd(t *testing.T) {
+	type args struct {
+		givenErr   error
+		expCode    codes.Code
+		expEvents  int
+		expMessage string
+	}
+	tcs := map[string]args{
+		"success": {expCode: codes.Unset},
+		"err": {
+			givenErr:   errors.New("connection refused"),
+			expCode:    codes.Error,
+			expEvents:  1,
+			expMessage: "connection refused",
+		},
+	}
+	for scenario, tc := range tcs {
+		t.Run(scenario, func(t *testing.T) {
+			// Given
+			spans, restore := NewInMemory()
+			t.Cleanup(restore)
+
+			// When
+			ctx, parent := Start(context.Background(), "parent")
+			_, child := Start(ctx, "child")
+			End(child, tc.givenErr)
+			End(parent, nil)
+
+			// Then
+			ended := spans.GetSpans()
+			require.Len(t, ended, 2)
+			require.Equal(t, "child", ended[0].Name)
+			require.Equal(t, ended[1].SpanContext.SpanID(), ended[0].Parent.SpanID())
+			require.Equal(t, tc.expCode, ended[0].Status.Code)
+			require.Equal(t, tc.expMessage, ended[0].Status.Description)
+			require.Len(t, ended[0].Events, tc.expEvents)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package accounts
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteByUserID(t *testing.T) {
+	type args struct {
+		givenUserID   int64
+		givenProvider model.Provider
+		expErr        error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenUserID:   4001,
+			givenProvider: model.ProviderGitHub,
+		},
+		"err - provider not linked": {
+			givenUserID:   4001,
+			givenProvider: model.ProviderMicrosoft,
+			expErr:        ErrNotFound,
+		},
+		"err - user not found": {
+			givenUserID:   9999,
+			givenProvider: model.ProviderGoogle,
+			expErr:        ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/accounts.sql")
+				repo := New(tx)
+				err := repo.DeleteByUserID(context.Background(), tc.givenUserID, tc.givenProvider)
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+
+					// Verify only the other provider is still linked
+					accounts, err := repo.GetByUserID(context.Background(), tc.givenUserID)
+					require.NoError(t, err)
+					require.Len(t, accounts, 1)
+					require.Equal(t, model.ProviderGoogle, accounts[0].Provider)
+				}
+			})
+		})
+	}
+}
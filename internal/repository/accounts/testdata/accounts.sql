@@ -0,0 +1,14 @@
+-- Test data for accounts repository tests
+-- This file is loaded by testdb.LoadTestSQLFile within a rolled-back transaction
+
+DELETE FROM accounts;
+DELETE FROM users;
+
+INSERT INTO users (id, email, name, password, image, created_at, updated_at)
+VALUES
+    (4001, 'account1@example.com', 'Account User 1', '', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00');
+
+INSERT INTO accounts (id, "userId", type, provider, "providerAccountId", refresh_token, access_token, expires_at, id_token, scope, session_state, token_type)
+VALUES
+    (5001, 4001, 'oauth', 'google', 'google-1', '', '', 0, '', '', '', 'Bearer'),
+    (5002, 4001, 'oauth', 'github', 'github-1', '', '', 0, '', '', '', 'Bearer');
@@ -0,0 +1,33 @@
+package apikeys
+
+import (
+	"context"
+	"strings"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Create implements Repository.
+func (i impl) Create(ctx context.Context, key model.APIKey) (model.APIKey, error) {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, created_at
+	`
+
+	err := i.db.QueryRowContext(ctx, query,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		strings.Join(key.Scopes, " "),
+		key.ExpiresAt,
+	).Scan(&key.ID, &key.CreatedAt)
+
+	if err != nil {
+		return model.APIKey{}, pkgerrors.WithStack(err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,85 @@
+package apikeys
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate(t *testing.T) {
+	type args struct {
+		givenKey model.APIKey
+	}
+
+	tcs := map[string]args{
+		"success - scoped": {
+			givenKey: model.APIKey{UserID: 8001, Name: "Deploy", Prefix: "gbt_dddddddd", KeyHash: "hash-new", Scopes: []string{"users:read", "users:update"}},
+		},
+		"success - unscoped": {
+			givenKey: model.APIKey{UserID: 8001, Name: "Admin script", Prefix: "gbt_eeeeeeee", KeyHash: "hash-new"},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/api_keys.sql")
+				repo := New(tx)
+
+				created, err := repo.Create(context.Background(), tc.givenKey)
+				require.NoError(t, err)
+				require.NotZero(t, created.ID)
+
+				got, err := repo.GetByHash(context.Background(), tc.givenKey.KeyHash)
+				require.NoError(t, err)
+				require.Equal(t, created.ID, got.ID)
+				require.Equal(t, tc.givenKey.Prefix, got.Prefix)
+				require.Equal(t, tc.givenKey.Scopes, got.Scopes)
+				require.Nil(t, got.RevokedAt)
+			})
+		})
+	}
+}
+
+func TestGetByHash(t *testing.T) {
+	type args struct {
+		givenHash string
+		expID     int64
+		expErr    error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenHash: "hash-8101",
+			expID:     8101,
+		},
+		"success - revoked keys are returned": {
+			givenHash: "hash-8102",
+			expID:     8102,
+		},
+		"err - not found": {
+			givenHash: "hash-unknown",
+			expErr:    ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/api_keys.sql")
+				got, err := New(tx).GetByHash(context.Background(), tc.givenHash)
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+					require.Equal(t, tc.expID, got.ID)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,16 @@
+-- Test data for api keys repository tests
+-- This file is loaded by testdb.LoadTestSQLFile within a rolled-back transaction
+
+DELETE FROM api_keys;
+DELETE FROM users;
+
+INSERT INTO users (id, email, name, password, image, created_at, updated_at)
+VALUES
+    (8001, 'apikey1@example.com', 'API Key User 1', '', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00'),
+    (8002, 'apikey2@example.com', 'API Key User 2', '', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00');
+
+INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes, expires_at, revoked_at, created_at)
+VALUES
+    (8101, 8001, 'CI', 'gbt_aaaaaaaa', 'hash-8101', 'users:read', NULL, NULL, '2024-01-01 00:00:00'),
+    (8102, 8001, 'Old script', 'gbt_bbbbbbbb', 'hash-8102', '', NULL, '2024-02-01 00:00:00', '2024-01-02 00:00:00'),
+    (8103, 8002, 'Backup', 'gbt_cccccccc', 'hash-8103', '', '2030-01-01 00:00:00', NULL, '2024-01-01 00:00:00');
@@ -0,0 +1,38 @@
+package apikeys
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Revoke implements Repository.
+func (i impl) Revoke(ctx context.Context, userID, id int64, revokedAt time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = $3 WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := i.db.ExecContext(ctx, query, id, userID, revokedAt)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+
+	return nil
+}
+
+// UpdateLastUsed implements Repository.
+func (i impl) UpdateLastUsed(ctx context.Context, id int64, usedAt time.Time) error {
+	if _, err := i.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, usedAt); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
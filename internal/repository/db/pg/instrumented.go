@@ -2,41 +2,212 @@ package pg
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/namf2001/go-backend-template/internal/pkg/logger"
+	"github.com/namf2001/go-backend-template/internal/pkg/metrics"
+	"github.com/namf2001/go-backend-template/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
-// instrumentedDB wraps the *sql.DB to add logging
+// attrRowsAffected is the number of rows changed by an Exec
+const attrRowsAffected = attribute.Key("db.response.rows_affected")
+
+// pkgPath is skipped when looking for the repository method running a query
+var pkgPath = reflect.TypeOf(instrumentedExecutor{}).PkgPath()
+
+// instrumentedExecutor records a span, the duration and the errors of each query, tagged with the repository method
+// running it, and logs the slow ones
+type instrumentedExecutor struct {
+	next      ContextExecutor
+	parent    trace.Span    // Span of the transaction the queries run in, if any
+	slowQuery time.Duration // Queries taking longer are logged, 0 disables it
+}
+
+// queryRun is a query being run by an instrumentedExecutor
+type queryRun struct {
+	ctx       context.Context
+	span      trace.Span
+	method    string
+	operation string
+	query     string
+	start     time.Time
+}
+
+// ExecContext records the query, tagged with the number of affected rows
+func (e instrumentedExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	run := e.start(ctx, query)
+	res, err := e.next.ExecContext(run.ctx, query, args...)
+	if err == nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			run.span.SetAttributes(attrRowsAffected.Int64(n))
+		}
+	}
+	e.end(run, args, err)
+	return res, err
+}
+
+// QueryContext records the query until it returns, the rows are read after
+func (e instrumentedExecutor) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	run := e.start(ctx, query)
+	rows, err := e.next.QueryContext(run.ctx, query, args...)
+	e.end(run, args, err)
+	return rows, err
+}
+
+// QueryRowContext records the query until it returns
+func (e instrumentedExecutor) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	run := e.start(ctx, query)
+	row := e.next.QueryRowContext(run.ctx, query, args...)
+	err := row.Err()
+	if err == nil {
+		run.span.SetAttributes(semconv.DBResponseReturnedRows(1))
+	}
+	e.end(run, args, err)
+	return row
+}
+
+func (e instrumentedExecutor) start(ctx context.Context, query string) queryRun {
+	if e.parent != nil {
+		ctx = trace.ContextWithSpan(ctx, e.parent)
+	}
+	method := callerMethod()
+	operation := sqlOperation(query)
+	ctx, span := tracing.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(query),
+			semconv.CodeFunctionName(method),
+		),
+	)
+	return queryRun{ctx: ctx, span: span, method: method, operation: operation, query: query, start: time.Now()}
+}
+
+func (e instrumentedExecutor) end(run queryRun, args []interface{}, err error) {
+	duration := time.Since(run.start)
+	tracing.End(run.span, err)
+
+	metrics.DBQueryDuration.WithLabelValues(run.method, run.operation).Observe(duration.Seconds())
+	if err != nil {
+		metrics.DBQueryErrors.WithLabelValues(run.method, run.operation).Inc()
+	}
+
+	if e.slowQuery > 0 && duration >= e.slowQuery {
+		logger.Warn(run.ctx, "slow query",
+			zap.String("method", run.method),
+			zap.String("operation", run.operation),
+			zap.String("query", run.query),
+			zap.Duration("duration", duration),
+			zap.String("args_fingerprint", argsFingerprint(args)),
+			zap.Error(err),
+		)
+	}
+}
+
+// instrumentedDB instruments the queries of a pool and of the transactions TxWithBackOff begins on it
 type instrumentedDB struct {
-	*sql.DB
+	BeginnerExecutor
+	exec instrumentedExecutor
+}
+
+// ExecContext records the query, tagged with the number of affected rows
+func (db instrumentedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.exec.ExecContext(ctx, query, args...)
+}
+
+// QueryContext records the query until it returns, the rows are read after
+func (db instrumentedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.exec.QueryContext(ctx, query, args...)
 }
 
-// BeginTx begins a transaction with logging
-func (i *instrumentedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
-	logger.DEBUG.Printf("DB: BeginTx")
-	return i.DB.BeginTx(ctx, opts)
+// QueryRowContext records the query until it returns
+func (db instrumentedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return db.exec.QueryRowContext(ctx, query, args...)
 }
 
-// ExecContext wraps the base connector with logging
-func (i *instrumentedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	logger.DEBUG.Printf("DB Exec: %s", query)
-	return i.DB.ExecContext(ctx, query, args...)
+// instrumentTx returns the executor of a transaction begun on the pool, its queries are children of the tx span
+func (db instrumentedDB) instrumentTx(tx *sql.Tx, span trace.Span) ContextExecutor {
+	return instrumentedExecutor{next: tx, parent: span, slowQuery: db.exec.slowQuery}
 }
 
-// QueryContext wraps the base connector with logging
-func (i *instrumentedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	logger.DEBUG.Printf("DB Query: %s", query)
-	return i.DB.QueryContext(ctx, query, args...)
+// NewInstrumentedDB wraps a BeginnerExecutor to trace, measure and log its queries and those of its transactions.
+// Queries slower than slowQuery are logged, 0 disables it.
+func NewInstrumentedDB(db BeginnerExecutor, slowQuery time.Duration) BeginnerExecutor {
+	return instrumentedDB{BeginnerExecutor: db, exec: instrumentedExecutor{next: db, slowQuery: slowQuery}}
 }
 
-// QueryRowContext wraps the base connector with logging
-func (i *instrumentedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	logger.DEBUG.Printf("DB QueryRow: %s", query)
-	return i.DB.QueryRowContext(ctx, query, args...)
+// sqlOperation returns the SQL keyword starting the query, e.g. SELECT
+func sqlOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "UNKNOWN"
+	}
+	return strings.ToUpper(strings.TrimLeft(fields[0], "("))
 }
 
-// NewInstrumentedDB wraps a *sql.DB with logging and returns a BeginnerExecutor
-func NewInstrumentedDB(db *sql.DB) BeginnerExecutor {
-	return &instrumentedDB{DB: db}
+// callerMethod returns the method running the query, the first caller outside this package, e.g. users.GetByEmail
+func callerMethod() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, pkgPath+".") {
+			return methodName(frame.Function)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// methodName shortens a function name to its package and method,
+// e.g. github.com/org/app/internal/repository/users.(*impl).List.func1 to users.List
+func methodName(function string) string {
+	parts := strings.Split(function[strings.LastIndex(function, "/")+1:], ".")
+	for len(parts) > 2 && isClosure(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) > 2 {
+		// Drop the receiver
+		parts = []string{parts[0], parts[len(parts)-1]}
+	}
+	return strings.Join(parts, ".")
+}
+
+// isClosure reports whether a function name part is added by the compiler for a closure, e.g. func1 or 2
+func isClosure(part string) bool {
+	return strings.HasPrefix(part, "func") || strings.Trim(part, "0123456789") == ""
+}
+
+// fingerprintKey keys argsFingerprint. It is drawn at startup so emails or ids cannot be brute-forced back
+// from the logs, fingerprints only match within a process.
+var fingerprintKey = []byte(rand.Text())
+
+// argsFingerprint returns a short HMAC of the query arguments, so runs of a slow query with the same arguments
+// can be told apart from others without logging their values
+func argsFingerprint(args []interface{}) string {
+	h := hmac.New(sha256.New, fingerprintKey)
+	for _, arg := range args {
+		// Dereferences pointers and calls driver.Valuer, so equal arguments hash the same
+		if v, err := driver.DefaultParameterConverter.ConvertValue(arg); err == nil {
+			arg = v
+		}
+		fmt.Fprintf(h, "%T:%v\x00", arg, arg)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
 }
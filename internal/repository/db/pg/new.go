@@ -13,7 +13,7 @@ import (
 
 // NewPool opens a new DB connection pool, pings it and returns a BeginnerExecutor
 func NewPool(dsn string, maxOpenConns int, maxIdleConns int) (BeginnerExecutor, error) {
-	logger.INFO.Printf("Initializing Postgres connection pool...")
+	logger.L().Info("initializing Postgres connection pool")
 
 	pool, err := sql.Open("postgres", dsn)
 	if err != nil {
@@ -29,7 +29,7 @@ func NewPool(dsn string, maxOpenConns int, maxIdleConns int) (BeginnerExecutor,
 		return nil, pkgerrors.WithStack(fmt.Errorf("unable to ping DB: %w", err))
 	}
 
-	logger.INFO.Printf("Postgres connection pool initialized successfully")
+	logger.L().Info("Postgres connection pool initialized")
 
 	return pool, nil
 }
@@ -0,0 +1,47 @@
+package loginattempts
+
+import (
+	"context"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Lock implements Repository.
+func (i impl) Lock(ctx context.Context, key string, until time.Time) error {
+	query := `
+		UPDATE login_attempts
+		SET locked_until = $2
+		WHERE key = $1
+	`
+
+	result, err := i.db.ExecContext(ctx, query, key, until)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+
+	return nil
+}
+
+// Reset implements Repository.
+func (i impl) Reset(ctx context.Context, key string) error {
+	query := `
+		DELETE FROM login_attempts
+		WHERE key = $1
+	`
+
+	if _, err := i.db.ExecContext(ctx, query, key); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	return nil
+}
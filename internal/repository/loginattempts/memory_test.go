@@ -0,0 +1,66 @@
+package loginattempts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_RecordFailure(t *testing.T) {
+	type args struct {
+		givenKey    string
+		expFailures int
+	}
+
+	tcs := map[string]args{
+		"first failure": {
+			givenKey:    "ip:192.0.2.1",
+			expFailures: 1,
+		},
+		"failure within window": {
+			givenKey:    "account:recent@example.com",
+			expFailures: 3,
+		},
+		"failure after window": {
+			givenKey:    "account:stale@example.com",
+			expFailures: 1,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			repo := seededMemory(t, now)
+
+			attempt, err := repo.RecordFailure(context.Background(), tc.givenKey, now, now.Add(-15*time.Minute))
+			require.NoError(t, err)
+			require.Equal(t, tc.expFailures, attempt.Failures)
+			require.Equal(t, now, attempt.LastFailureAt)
+		})
+	}
+}
+
+func TestMemory_LockAndReset(t *testing.T) {
+	now := time.Now()
+	testLockAndReset(t, seededMemory(t, now), now)
+}
+
+// seededMemory returns an in-memory repository holding the same attempts as testdata/login_attempts.sql
+func seededMemory(t *testing.T, now time.Time) Repository {
+	ctx := context.Background()
+	m := NewMemory()
+
+	for i := 0; i < 2; i++ {
+		_, err := m.RecordFailure(ctx, "account:recent@example.com", now.Add(-time.Minute), now.Add(-time.Hour))
+		require.NoError(t, err)
+	}
+	for i := 0; i < 4; i++ {
+		_, err := m.RecordFailure(ctx, "account:stale@example.com", now.Add(-24*time.Hour), now.Add(-48*time.Hour))
+		require.NoError(t, err)
+	}
+	require.NoError(t, m.Lock(ctx, "account:stale@example.com", now.Add(-23*time.Hour)))
+
+	return m
+}
@@ -0,0 +1,73 @@
+package loginattempts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordFailure(t *testing.T) {
+	type args struct {
+		givenKey    string
+		expFailures int
+	}
+
+	tcs := map[string]args{
+		"first failure": {
+			givenKey:    "ip:192.0.2.1",
+			expFailures: 1,
+		},
+		"failure within window": {
+			givenKey:    "account:recent@example.com",
+			expFailures: 3,
+		},
+		"failure after window": {
+			givenKey:    "account:stale@example.com",
+			expFailures: 1,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/login_attempts.sql")
+				now := time.Now()
+
+				repo := New(tx)
+				attempt, err := repo.RecordFailure(context.Background(), tc.givenKey, now, now.Add(-15*time.Minute))
+				require.NoError(t, err)
+				require.Equal(t, tc.expFailures, attempt.Failures)
+				require.WithinDuration(t, now, attempt.LastFailureAt, time.Second)
+			})
+		})
+	}
+}
+
+func TestLockAndReset(t *testing.T) {
+	testdb.WithTx(t, func(tx pg.ContextExecutor) {
+		testdb.LoadTestSQLFile(t, tx, "testdata/login_attempts.sql")
+		now := time.Now()
+
+		testLockAndReset(t, New(tx), now)
+	})
+}
+
+func testLockAndReset(t *testing.T, repo Repository, now time.Time) {
+	ctx := context.Background()
+	until := now.Add(15 * time.Minute)
+	require.NoError(t, repo.Lock(ctx, "account:recent@example.com", until))
+
+	attempt, err := repo.Get(ctx, "account:recent@example.com")
+	require.NoError(t, err)
+	require.True(t, attempt.IsLocked(now))
+
+	require.ErrorIs(t, repo.Lock(ctx, "account:unknown@example.com", until), ErrNotFound)
+
+	require.NoError(t, repo.Reset(ctx, "account:recent@example.com"))
+	_, err = repo.Get(ctx, "account:recent@example.com")
+	require.ErrorIs(t, err, ErrNotFound)
+}
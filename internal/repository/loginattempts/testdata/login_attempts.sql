@@ -0,0 +1,9 @@
+-- Test data for login attempts repository tests
+-- This file is loaded by testdb.LoadTestSQLFile within a rolled-back transaction
+
+DELETE FROM login_attempts;
+
+INSERT INTO login_attempts (key, failures, last_failure_at, locked_until)
+VALUES
+    ('account:recent@example.com', 2, NOW() - INTERVAL '1 minute', NULL),
+    ('account:stale@example.com', 4, NOW() - INTERVAL '1 day', NOW() - INTERVAL '23 hours');
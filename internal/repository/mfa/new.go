@@ -0,0 +1,43 @@
+package mfa
+
+import (
+	"context"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+)
+
+type Repository interface {
+	// Get retrieves the second factor of a user
+	Get(ctx context.Context, userID int64) (model.MFA, error)
+
+	// Upsert stores a new pending secret for a user, replacing any previous one
+	Upsert(ctx context.Context, mfa model.MFA) error
+
+	// Enable marks the second factor of a user as confirmed
+	Enable(ctx context.Context, userID int64, enabledAt time.Time) error
+
+	// UseStep records the time step of an accepted code. It returns ErrStepAlreadyUsed
+	// unless the step is newer than the last one used.
+	UseStep(ctx context.Context, userID int64, step int64) error
+
+	// Delete removes the second factor and the recovery codes of a user
+	Delete(ctx context.Context, userID int64) error
+
+	// ReplaceRecoveryCodes replaces the recovery codes of a user with the given hashes
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+
+	// UseRecoveryCode marks an unused recovery code as used
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error
+}
+
+type impl struct {
+	db pg.ContextExecutor
+}
+
+func New(db pg.ContextExecutor) Repository {
+	return impl{
+		db: db,
+	}
+}
@@ -0,0 +1,61 @@
+package mfa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseRecoveryCode(t *testing.T) {
+	type args struct {
+		givenHash string
+		expErr    error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenHash: "unused-hash",
+		},
+		"err - code already used": {
+			givenHash: "used-hash",
+			expErr:    ErrNotFound,
+		},
+		"err - unknown code": {
+			givenHash: "unknown-hash",
+			expErr:    ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/mfa.sql")
+				repo := New(tx)
+				err := repo.UseRecoveryCode(context.Background(), 6001, tc.givenHash)
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+
+					// A code can only be used once
+					require.ErrorIs(t, repo.UseRecoveryCode(context.Background(), 6001, tc.givenHash), ErrNotFound)
+				}
+			})
+		})
+	}
+}
+
+func TestReplaceRecoveryCodes(t *testing.T) {
+	testdb.WithTx(t, func(tx pg.ContextExecutor) {
+		testdb.LoadTestSQLFile(t, tx, "testdata/mfa.sql")
+		repo := New(tx)
+		require.NoError(t, repo.ReplaceRecoveryCodes(context.Background(), 6001, []string{"new-hash-1", "new-hash-2"}))
+
+		require.ErrorIs(t, repo.UseRecoveryCode(context.Background(), 6001, "unused-hash"), ErrNotFound)
+		require.NoError(t, repo.UseRecoveryCode(context.Background(), 6001, "new-hash-1"))
+	})
+}
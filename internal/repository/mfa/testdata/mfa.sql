@@ -0,0 +1,20 @@
+-- Test data for mfa repository tests
+-- This file is loaded by testdb.LoadTestSQLFile within a rolled-back transaction
+
+DELETE FROM mfa_recovery_codes;
+DELETE FROM user_mfa;
+DELETE FROM users;
+
+INSERT INTO users (id, email, name, password, image, created_at, updated_at)
+VALUES
+    (6001, 'mfa1@example.com', 'MFA User 1', '$2a$10$hashedpassword1', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00'),
+    (6002, 'mfa2@example.com', 'MFA User 2', '$2a$10$hashedpassword2', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00');
+
+INSERT INTO user_mfa (user_id, secret, enabled_at, last_used_step, created_at)
+VALUES
+    (6001, 'encrypted-secret', '2024-01-01 00:00:00', 100, '2024-01-01 00:00:00');
+
+INSERT INTO mfa_recovery_codes (user_id, code_hash, used_at)
+VALUES
+    (6001, 'unused-hash', NULL),
+    (6001, 'used-hash', '2024-01-02 00:00:00');
@@ -0,0 +1,129 @@
+package oauthclients
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate(t *testing.T) {
+	createdBy := int64(9001)
+	type args struct {
+		givenClient model.OAuthClient
+	}
+
+	tcs := map[string]args{
+		"success - public client": {
+			givenClient: model.OAuthClient{
+				ClientID:     "mobile-app",
+				Name:         "Mobile app",
+				RedirectURIs: []string{"com.example.app:/callback"},
+				GrantTypes:   []string{model.GrantTypeAuthorizationCode, model.GrantTypeRefreshToken},
+				Scopes:       []string{"users:read"},
+				CreatedBy:    &createdBy,
+			},
+		},
+		"success - confidential client": {
+			givenClient: model.OAuthClient{
+				ClientID:   "reports",
+				SecretHash: "hash",
+				Name:       "Reports",
+				GrantTypes: []string{model.GrantTypeClientCredentials},
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/oauth_clients.sql")
+				repo := New(tx)
+
+				created, err := repo.Create(context.Background(), tc.givenClient)
+				require.NoError(t, err)
+				require.NotZero(t, created.ID)
+
+				got, err := repo.GetByClientID(context.Background(), tc.givenClient.ClientID)
+				require.NoError(t, err)
+				require.Equal(t, created.ID, got.ID)
+				require.Equal(t, tc.givenClient.SecretHash, got.SecretHash)
+				require.Equal(t, tc.givenClient.GrantTypes, got.GrantTypes)
+				require.Equal(t, tc.givenClient.CreatedBy, got.CreatedBy)
+			})
+		})
+	}
+}
+
+func TestGetByClientID(t *testing.T) {
+	type args struct {
+		givenClientID   string
+		expRedirectURIs []string
+		expScopes       []string
+		expErr          error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenClientID:   "web-app",
+			expRedirectURIs: []string{"https://app.example.com/callback", "http://localhost:3000/callback"},
+			expScopes:       []string{"users:read"},
+		},
+		"err - not found": {
+			givenClientID: "unknown",
+			expErr:        ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/oauth_clients.sql")
+				got, err := New(tx).GetByClientID(context.Background(), tc.givenClientID)
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+					require.Equal(t, tc.expRedirectURIs, got.RedirectURIs)
+					require.Equal(t, tc.expScopes, got.Scopes)
+				}
+			})
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	type args struct {
+		givenID int64
+		expErr  error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenID: 9101,
+		},
+		"err - not found": {
+			givenID: 9999,
+			expErr:  ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/oauth_clients.sql")
+				err := New(tx).Delete(context.Background(), tc.givenID)
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+				}
+			})
+		})
+	}
+}
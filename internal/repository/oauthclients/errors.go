@@ -0,0 +1,7 @@
+package oauthclients
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("oauth client not found")
+)
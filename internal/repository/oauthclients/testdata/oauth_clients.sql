@@ -0,0 +1,15 @@
+-- Test data for oauth clients repository tests
+-- This file is loaded by testdb.LoadTestSQLFile within a rolled-back transaction
+
+DELETE FROM oauth_authorization_codes;
+DELETE FROM oauth_clients;
+DELETE FROM users;
+
+INSERT INTO users (id, email, name, password, image, created_at, updated_at)
+VALUES
+    (9001, 'oauth-admin@example.com', 'OAuth Admin', '', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00');
+
+INSERT INTO oauth_clients (id, client_id, secret_hash, name, redirect_uris, grant_types, scopes, created_by, created_at)
+VALUES
+    (9101, 'web-app', '', 'Web app', 'https://app.example.com/callback http://localhost:3000/callback', 'authorization_code refresh_token', 'users:read', 9001, '2024-01-01 00:00:00'),
+    (9102, 'billing', 'secret-hash', 'Billing service', '', 'client_credentials', 'users:read users:update', NULL, '2024-01-02 00:00:00');
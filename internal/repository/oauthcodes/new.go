@@ -0,0 +1,27 @@
+package oauthcodes
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+)
+
+type Repository interface {
+	// Create stores a new authorization code
+	Create(ctx context.Context, code model.OAuthAuthorizationCode) error
+
+	// Redeem marks an authorization code as used by the token family and returns it. When the code was
+	// already used, UsedAt and FamilyID are those of its first redemption.
+	Redeem(ctx context.Context, codeHash, familyID string) (model.OAuthAuthorizationCode, error)
+}
+
+type impl struct {
+	db pg.ContextExecutor
+}
+
+func New(db pg.ContextExecutor) Repository {
+	return impl{
+		db: db,
+	}
+}
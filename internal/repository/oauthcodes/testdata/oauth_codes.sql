@@ -0,0 +1,18 @@
+-- Test data for oauth authorization codes repository tests
+-- This file is loaded by testdb.LoadTestSQLFile within a rolled-back transaction
+
+DELETE FROM oauth_authorization_codes;
+DELETE FROM oauth_clients;
+DELETE FROM users;
+
+INSERT INTO users (id, email, name, password, image, created_at, updated_at)
+VALUES
+    (9201, 'oauth-user@example.com', 'OAuth User', '', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00');
+
+INSERT INTO oauth_clients (id, client_id, name, redirect_uris, grant_types, scopes, created_at)
+VALUES
+    (9301, 'web-app', 'Web app', 'https://app.example.com/callback', 'authorization_code', 'users:read', '2024-01-01 00:00:00');
+
+INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at, created_at)
+VALUES
+    ('hash-code-1', 'web-app', 9201, 'https://app.example.com/callback', 'users:read', 'challenge', NOW() + INTERVAL '1 minute', '2024-01-01 00:00:00');
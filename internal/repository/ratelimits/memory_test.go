@@ -0,0 +1,37 @@
+package ratelimits
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Take(t *testing.T) {
+	repo := NewMemory()
+	// Same bucket as testdata/rate_limits.sql
+	_, err := repo.Take(context.Background(), "api:user:1001", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), model.RateLimit{Limit: 1, Period: time.Second})
+	require.NoError(t, err)
+
+	testTake(t, repo)
+}
+
+func TestMemory_Prune(t *testing.T) {
+	m := NewMemory().(*memory)
+	limit := model.RateLimit{Limit: 10, Period: time.Second}
+	now := time.Now()
+
+	for i := 0; i < pruneEvery-1; i++ {
+		_, err := m.Take(context.Background(), "old", now, limit)
+		require.NoError(t, err)
+	}
+	require.Len(t, m.tats, 1)
+
+	// The bucket of "old" is full by the next sweep
+	_, err := m.Take(context.Background(), "new", now.Add(time.Hour), limit)
+	require.NoError(t, err)
+	require.Len(t, m.tats, 1)
+	require.Contains(t, m.tats, "new")
+}
@@ -0,0 +1,24 @@
+package ratelimits
+
+import (
+	"context"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+)
+
+type Repository interface {
+	// Take takes a token from the bucket of a key at now, if one is left
+	Take(ctx context.Context, key string, now time.Time, limit model.RateLimit) (model.RateLimitResult, error)
+}
+
+type impl struct {
+	db pg.ContextExecutor
+}
+
+func New(db pg.ContextExecutor) Repository {
+	return impl{
+		db: db,
+	}
+}
@@ -0,0 +1,57 @@
+package ratelimits
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Take implements Repository.
+func (i impl) Take(ctx context.Context, key string, now time.Time, limit model.RateLimit) (model.RateLimitResult, error) {
+	if limit.Limit <= 0 || limit.Period <= 0 {
+		return model.RateLimitResult{}, pkgerrors.WithStack(ErrInvalidRateLimit)
+	}
+
+	// A single upsert so concurrent requests of every instance each take their own token.
+	// The arrival time only moves when a token is left, once past now the bucket is full.
+	query := `
+		INSERT INTO rate_limits (key, tat, allowed)
+		VALUES ($1, $2::timestamptz + make_interval(secs => $3::float8), TRUE)
+		ON CONFLICT (key) DO UPDATE SET
+			tat = CASE
+				WHEN GREATEST(rate_limits.tat, $2::timestamptz) + make_interval(secs => $3::float8) <= $2::timestamptz + make_interval(secs => $4::float8)
+				THEN GREATEST(rate_limits.tat, $2::timestamptz) + make_interval(secs => $3::float8)
+				ELSE rate_limits.tat
+			END,
+			allowed = GREATEST(rate_limits.tat, $2::timestamptz) + make_interval(secs => $3::float8) <= $2::timestamptz + make_interval(secs => $4::float8)
+		RETURNING tat, allowed
+	`
+
+	var (
+		tat     time.Time
+		allowed bool
+	)
+	if err := i.db.QueryRowContext(ctx, query, key, now, limit.Interval().Seconds(), limit.Period.Seconds()).Scan(&tat, &allowed); err != nil {
+		return model.RateLimitResult{}, pkgerrors.WithStack(err)
+	}
+
+	if rand.IntN(pruneEvery) == 0 {
+		if err := i.prune(ctx, now); err != nil {
+			return model.RateLimitResult{}, err
+		}
+	}
+
+	return model.NewRateLimitResult(limit, tat, now, allowed), nil
+}
+
+// prune deletes full buckets, they are the same as no bucket. Without it every client that ever made
+// a request would keep a row.
+func (i impl) prune(ctx context.Context, now time.Time) error {
+	if _, err := i.db.ExecContext(ctx, `DELETE FROM rate_limits WHERE tat < $1`, now); err != nil {
+		return pkgerrors.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+-- Test data for rate limits repository tests
+-- This file is loaded by testdb.LoadTestSQLFile within a rolled-back transaction
+
+DELETE FROM rate_limits;
+
+INSERT INTO rate_limits (key, tat, allowed)
+VALUES
+    ('api:user:1001', '2000-01-01 00:00:00+00', TRUE);
@@ -5,10 +5,20 @@ import (
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	"github.com/namf2001/go-backend-template/config"
 	"github.com/namf2001/go-backend-template/internal/repository/accounts"
+	"github.com/namf2001/go-backend-template/internal/repository/apikeys"
 	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/namf2001/go-backend-template/internal/repository/loginattempts"
+	"github.com/namf2001/go-backend-template/internal/repository/mfa"
+	"github.com/namf2001/go-backend-template/internal/repository/oauthclients"
+	"github.com/namf2001/go-backend-template/internal/repository/oauthcodes"
+	"github.com/namf2001/go-backend-template/internal/repository/ratelimits"
+	"github.com/namf2001/go-backend-template/internal/repository/roles"
 	"github.com/namf2001/go-backend-template/internal/repository/sessions"
 	"github.com/namf2001/go-backend-template/internal/repository/users"
+	"github.com/namf2001/go-backend-template/internal/repository/verificationtokens"
+	"github.com/namf2001/go-backend-template/internal/repository/webauthncredentials"
 	pkgerrors "github.com/pkg/errors"
 )
 
@@ -20,26 +30,71 @@ type Registry interface {
 	Account() accounts.Repository
 	// Session return session repository
 	Session() sessions.Repository
+	// VerificationToken return verification token repository
+	VerificationToken() verificationtokens.Repository
+	// Role return role repository
+	Role() roles.Repository
+	// LoginAttempt return login attempt repository
+	LoginAttempt() loginattempts.Repository
+	// MFA return mfa repository
+	MFA() mfa.Repository
+	// WebAuthnCredential return webauthn credentials repository
+	WebAuthnCredential() webauthncredentials.Repository
+	// APIKey return api key repository
+	APIKey() apikeys.Repository
+	// OAuthClient return oauth client repository
+	OAuthClient() oauthclients.Repository
+	// OAuthCode return oauth authorization code repository
+	OAuthCode() oauthcodes.Repository
+	// RateLimit return rate limit repository
+	RateLimit() ratelimits.Repository
 	// DoInTx wraps operations within a db tx
 	DoInTx(ctx context.Context, txFunc func(ctx context.Context, txRepo Registry) error, overrideBackoffPolicy backoff.BackOff) error
 }
 
-// New returns a new instance of Registry
+// defaultSlowQueryThreshold is used when DB_SLOW_QUERY_THRESHOLD is not set
+const defaultSlowQueryThreshold = 200 * time.Millisecond
+
+// New returns a new instance of Registry. Its queries are traced and measured, those slower than
+// DB_SLOW_QUERY_THRESHOLD (0 disables it) are logged.
 func New(db pg.BeginnerExecutor) Registry {
+	slowQuery := defaultSlowQueryThreshold
+	if cfg := config.GetConfig(); cfg.IsSet("DB_SLOW_QUERY_THRESHOLD") {
+		slowQuery = cfg.GetDuration("DB_SLOW_QUERY_THRESHOLD")
+	}
+	db = pg.NewInstrumentedDB(db, slowQuery)
 	return &impl{
-		pgConn:   db,
-		users:    users.New(db),
-		accounts: accounts.New(db),
-		sessions: sessions.New(db),
+		pgConn:             db,
+		users:              users.New(db),
+		accounts:           accounts.New(db),
+		sessions:           sessions.New(db),
+		verificationTokens: verificationtokens.New(db),
+		roles:              roles.New(db),
+		loginAttempts:      loginattempts.New(db),
+		mfa:                mfa.New(db),
+		webAuthnCredential: webauthncredentials.New(db),
+		apiKeys:            apikeys.New(db),
+		oauthClients:       oauthclients.New(db),
+		oauthCodes:         oauthcodes.New(db),
+		rateLimits:         ratelimits.New(db),
 	}
 }
 
 type impl struct {
-	pgConn   pg.BeginnerExecutor // Only used to start DB txns
-	tx       pg.ContextExecutor  // Only used to keep track if txn has already been started to prevent nested txns
-	users    users.Repository
-	accounts accounts.Repository
-	sessions sessions.Repository
+	pgConn             pg.BeginnerExecutor // Only used to start DB txns
+	tx                 pg.ContextExecutor  // Only used to keep track if txn has already been started to prevent nested txns
+	users              users.Repository
+	accounts           accounts.Repository
+	sessions           sessions.Repository
+	verificationTokens verificationtokens.Repository
+	roles              roles.Repository
+	loginAttempts      loginattempts.Repository
+	mfa                mfa.Repository
+	webAuthnCredential webauthncredentials.Repository
+	apiKeys            apikeys.Repository
+	oauthClients       oauthclients.Repository
+	oauthCodes         oauthcodes.Repository
+	rateLimits         ratelimits.Repository
 }
 
 func (i *impl) User() users.Repository {
@@ -54,6 +109,42 @@ func (i *impl) Session() sessions.Repository {
 	return i.sessions
 }
 
+func (i *impl) VerificationToken() verificationtokens.Repository {
+	return i.verificationTokens
+}
+
+func (i *impl) Role() roles.Repository {
+	return i.roles
+}
+
+func (i *impl) LoginAttempt() loginattempts.Repository {
+	return i.loginAttempts
+}
+
+func (i *impl) MFA() mfa.Repository {
+	return i.mfa
+}
+
+func (i *impl) WebAuthnCredential() webauthncredentials.Repository {
+	return i.webAuthnCredential
+}
+
+func (i *impl) APIKey() apikeys.Repository {
+	return i.apiKeys
+}
+
+func (i *impl) OAuthClient() oauthclients.Repository {
+	return i.oauthClients
+}
+
+func (i *impl) OAuthCode() oauthcodes.Repository {
+	return i.oauthCodes
+}
+
+func (i *impl) RateLimit() ratelimits.Repository {
+	return i.rateLimits
+}
+
 // DoInTx wraps operations within a db tx.
 // It creates a new Registry where all repositories share the same transaction.
 // Nested transactions are not allowed.
@@ -68,10 +159,19 @@ func (i *impl) DoInTx(ctx context.Context, txFunc func(ctx context.Context, txRe
 
 	return pg.TxWithBackOff(ctx, overrideBackoffPolicy, i.pgConn, func(tx pg.ContextExecutor) error {
 		newI := &impl{
-			tx:       tx,
-			users:    users.New(tx),
-			accounts: accounts.New(tx),
-			sessions: sessions.New(tx),
+			tx:                 tx,
+			users:              users.New(tx),
+			accounts:           accounts.New(tx),
+			sessions:           sessions.New(tx),
+			verificationTokens: verificationtokens.New(tx),
+			roles:              roles.New(tx),
+			loginAttempts:      loginattempts.New(tx),
+			mfa:                mfa.New(tx),
+			webAuthnCredential: webauthncredentials.New(tx),
+			apiKeys:            apikeys.New(tx),
+			oauthClients:       oauthclients.New(tx),
+			oauthCodes:         oauthcodes.New(tx),
+			rateLimits:         ratelimits.New(tx),
 		}
 		return txFunc(ctx, newI)
 	})
@@ -0,0 +1,80 @@
+package roles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignToUser(t *testing.T) {
+	type args struct {
+		givenRole string
+		expErr    error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenRole: model.RoleAdmin,
+		},
+		"success - already granted": {
+			givenRole: model.RoleUser,
+		},
+		"err - role not found": {
+			givenRole: "superhero",
+			expErr:    ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/roles.sql")
+				repo := New(tx)
+				err := repo.AssignToUser(context.Background(), 4002, tc.givenRole)
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+				}
+			})
+		})
+	}
+}
+
+func TestRemoveFromUser(t *testing.T) {
+	type args struct {
+		givenRole string
+		expErr    error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenRole: model.RoleUser,
+		},
+		"err - role not granted": {
+			givenRole: model.RoleAdmin,
+			expErr:    ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/roles.sql")
+				repo := New(tx)
+				err := repo.RemoveFromUser(context.Background(), 4002, tc.givenRole)
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package roles
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// ListByUserID implements Repository.
+func (i impl) ListByUserID(ctx context.Context, userID int64) ([]model.Role, error) {
+	query := `
+		SELECT r.id, r.name, r.description, r.created_at
+		FROM roles r
+		JOIN user_roles ur ON ur.role_id = r.id
+		WHERE ur.user_id = $1
+		ORDER BY r.name
+	`
+
+	rows, err := i.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+	defer rows.Close()
+
+	var roles []model.Role
+	for rows.Next() {
+		var role model.Role
+		if err := rows.Scan(
+			&role.ID,
+			&role.Name,
+			&role.Description,
+			&role.CreatedAt,
+		); err != nil {
+			return nil, pkgerrors.WithStack(err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+
+	return roles, nil
+}
+
+// ListPermissionsByUserID implements Repository.
+func (i impl) ListPermissionsByUserID(ctx context.Context, userID int64) ([]string, error) {
+	query := `
+		SELECT DISTINCT p.name
+		FROM permissions p
+		JOIN role_permissions rp ON rp.permission_id = p.id
+		JOIN user_roles ur ON ur.role_id = rp.role_id
+		WHERE ur.user_id = $1
+		ORDER BY p.name
+	`
+
+	rows, err := i.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+	defer rows.Close()
+
+	var permissions []string
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, pkgerrors.WithStack(err)
+		}
+		permissions = append(permissions, permission)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+
+	return permissions, nil
+}
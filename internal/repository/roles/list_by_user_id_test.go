@@ -0,0 +1,64 @@
+package roles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListByUserID(t *testing.T) {
+	type args struct {
+		givenUserID int64
+		expRoles    []string
+	}
+
+	tcs := map[string]args{
+		"admin": {
+			givenUserID: 4001,
+			expRoles:    []string{model.RoleAdmin, model.RoleUser},
+		},
+		"member": {
+			givenUserID: 4002,
+			expRoles:    []string{model.RoleUser},
+		},
+		"no roles": {
+			givenUserID: 99999,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/roles.sql")
+				repo := New(tx)
+				roles, err := repo.ListByUserID(context.Background(), tc.givenUserID)
+				require.NoError(t, err)
+
+				var names []string
+				for _, r := range roles {
+					names = append(names, r.Name)
+				}
+				require.Equal(t, tc.expRoles, names)
+			})
+		})
+	}
+}
+
+func TestListPermissionsByUserID(t *testing.T) {
+	testdb.WithTx(t, func(tx pg.ContextExecutor) {
+		testdb.LoadTestSQLFile(t, tx, "testdata/roles.sql")
+		repo := New(tx)
+
+		adminPermissions, err := repo.ListPermissionsByUserID(context.Background(), 4001)
+		require.NoError(t, err)
+		require.Contains(t, adminPermissions, model.PermissionUsersDelete)
+
+		memberPermissions, err := repo.ListPermissionsByUserID(context.Background(), 4002)
+		require.NoError(t, err)
+		require.Empty(t, memberPermissions)
+	})
+}
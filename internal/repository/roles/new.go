@@ -0,0 +1,32 @@
+package roles
+
+import (
+	"context"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+)
+
+type Repository interface {
+	// ListByUserID retrieves the roles granted to a user
+	ListByUserID(ctx context.Context, userID int64) ([]model.Role, error)
+
+	// ListPermissionsByUserID retrieves the names of every permission a user has through their roles
+	ListPermissionsByUserID(ctx context.Context, userID int64) ([]string, error)
+
+	// AssignToUser grants a role to a user. Granting a role the user already has is a no-op.
+	AssignToUser(ctx context.Context, userID int64, roleName string) error
+
+	// RemoveFromUser revokes a role from a user
+	RemoveFromUser(ctx context.Context, userID int64, roleName string) error
+}
+
+type impl struct {
+	db pg.ContextExecutor
+}
+
+func New(db pg.ContextExecutor) Repository {
+	return impl{
+		db: db,
+	}
+}
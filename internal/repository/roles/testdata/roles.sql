@@ -0,0 +1,17 @@
+-- Test data for roles repository tests
+-- This file is loaded by testdb.LoadTestSQLFile within a rolled-back transaction
+-- The admin and user roles and their permissions are seeded by migration 005
+
+DELETE FROM user_roles;
+DELETE FROM users;
+
+INSERT INTO users (id, email, name, password, image, created_at, updated_at)
+VALUES
+    (4001, 'admin@example.com', 'Admin User', '$2a$10$hashedpassword1', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00'),
+    (4002, 'member@example.com', 'Member User', '$2a$10$hashedpassword2', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00');
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT 4001, id FROM roles WHERE name IN ('admin', 'user');
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT 4002, id FROM roles WHERE name = 'user';
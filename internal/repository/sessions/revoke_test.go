@@ -0,0 +1,92 @@
+package sessions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevoke(t *testing.T) {
+	type args struct {
+		givenToken string
+		expErr     error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenToken: "hashed-active-token",
+		},
+		"err - already revoked": {
+			givenToken: "hashed-rotated-token",
+			expErr:     ErrNotFound,
+		},
+		"err - session not found": {
+			givenToken: "unknown-token",
+			expErr:     ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/sessions.sql")
+				repo := New(tx)
+				err := repo.Revoke(context.Background(), tc.givenToken)
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+
+					// Verify the session is now revoked
+					session, err := repo.GetByToken(context.Background(), tc.givenToken)
+					require.NoError(t, err)
+					require.NotNil(t, session.RevokedAt)
+				}
+			})
+		})
+	}
+}
+
+func TestRevokeFamily(t *testing.T) {
+	testdb.WithTx(t, func(tx pg.ContextExecutor) {
+		testdb.LoadTestSQLFile(t, tx, "testdata/sessions.sql")
+		repo := New(tx)
+		require.NoError(t, repo.RevokeFamily(context.Background(), "family-1"))
+
+		revoked, err := repo.GetByToken(context.Background(), "hashed-active-token")
+		require.NoError(t, err)
+		require.NotNil(t, revoked.RevokedAt)
+
+		// Sessions in other families are untouched
+		other, err := repo.GetByToken(context.Background(), "hashed-other-token")
+		require.NoError(t, err)
+		require.Nil(t, other.RevokedAt)
+	})
+}
+
+func TestRevokeByClientID(t *testing.T) {
+	testdb.WithTx(t, func(tx pg.ContextExecutor) {
+		testdb.LoadTestSQLFile(t, tx, "testdata/sessions.sql")
+		repo := New(tx)
+
+		granted, err := repo.GetByToken(context.Background(), "hashed-client-token")
+		require.NoError(t, err)
+		require.Equal(t, "web-app", granted.ClientID)
+		require.Equal(t, []string{"users:read", "users:update"}, granted.Scopes)
+
+		require.NoError(t, repo.RevokeByClientID(context.Background(), "web-app"))
+
+		revoked, err := repo.GetByToken(context.Background(), "hashed-client-token")
+		require.NoError(t, err)
+		require.NotNil(t, revoked.RevokedAt)
+
+		// First-party sessions of the same user are untouched
+		firstParty, err := repo.GetByToken(context.Background(), "hashed-first-party-token")
+		require.NoError(t, err)
+		require.Nil(t, firstParty.RevokedAt)
+	})
+}
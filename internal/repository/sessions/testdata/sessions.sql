@@ -0,0 +1,22 @@
+-- Test data for sessions repository tests
+-- This file is loaded by testdb.LoadTestSQLFile within a rolled-back transaction
+
+DELETE FROM sessions;
+DELETE FROM users;
+
+INSERT INTO users (id, email, name, password, image, created_at, updated_at)
+VALUES
+    (2001, 'session1@example.com', 'Session User 1', '$2a$10$hashedpassword1', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00'),
+    (2002, 'session2@example.com', 'Session User 2', '$2a$10$hashedpassword2', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00');
+
+INSERT INTO sessions (id, "userId", expires, "sessionToken", "familyId", "revokedAt", created_at)
+VALUES
+    (3001, 2001, NOW() + INTERVAL '30 days', 'hashed-active-token', 'family-1', NULL, '2024-01-01 00:00:00'),
+    (3002, 2001, NOW() + INTERVAL '30 days', 'hashed-rotated-token', 'family-1', '2024-01-01 00:00:00', '2024-01-01 00:00:00'),
+    (3003, 2001, NOW() + INTERVAL '30 days', 'hashed-other-token', 'family-2', NULL, '2024-01-01 00:00:00');
+
+-- Sessions granted to an OAuth client
+INSERT INTO sessions (id, "userId", expires, "sessionToken", "familyId", "clientId", scopes, created_at)
+VALUES
+    (3004, 2002, NOW() + INTERVAL '30 days', 'hashed-client-token', 'family-3', 'web-app', 'users:read users:update', '2024-01-01 00:00:00'),
+    (3005, 2002, NOW() + INTERVAL '30 days', 'hashed-first-party-token', 'family-4', '', '', '2024-01-01 00:00:00');
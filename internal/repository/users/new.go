@@ -23,6 +23,9 @@ type Repository interface {
 	// Update updates an existing user
 	Update(ctx context.Context, user model.User) error
 
+	// UpdatePassword replaces the password hash of a user, only if it still is the given current hash
+	UpdatePassword(ctx context.Context, id int64, currentHash, newHash string) error
+
 	// Delete deletes a user by ID
 	Delete(ctx context.Context, id int64) error
 
@@ -0,0 +1,32 @@
+package users
+
+import (
+	"context"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// UpdatePassword implements Repository. A password changed in between is kept, ErrNotFound is returned.
+func (i impl) UpdatePassword(ctx context.Context, id int64, currentHash, newHash string) error {
+	query := `
+		UPDATE users
+		SET password = $1
+		WHERE id = $2 AND password = $3
+	`
+
+	result, err := i.db.ExecContext(ctx, query, newHash, id, currentHash)
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return pkgerrors.WithStack(err)
+	}
+
+	if rowsAffected == 0 {
+		return pkgerrors.WithStack(ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package users
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdatePassword(t *testing.T) {
+	type args struct {
+		givenID          int64
+		givenCurrentHash string
+		expPassword      string
+		expErr           error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenID:          1001,
+			givenCurrentHash: "$2a$10$hashedpassword1",
+			expPassword:      "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$a2V5",
+		},
+		"err - password changed in between": {
+			givenID:          1001,
+			givenCurrentHash: "$2a$10$stalepassword",
+			expPassword:      "$2a$10$hashedpassword1",
+			expErr:           ErrNotFound,
+		},
+		"err - user not found": {
+			givenID:          99999,
+			givenCurrentHash: "$2a$10$hashedpassword1",
+			expErr:           ErrNotFound,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/users.sql")
+				repo := New(tx)
+				err := repo.UpdatePassword(context.Background(), tc.givenID, tc.givenCurrentHash, "$argon2id$v=19$m=65536,t=3,p=4$c2FsdA$a2V5")
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+				} else {
+					require.NoError(t, err)
+				}
+
+				if tc.expPassword != "" {
+					user, err := repo.GetByID(context.Background(), tc.givenID)
+					require.NoError(t, err)
+					require.Equal(t, tc.expPassword, user.Password)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package verificationtokens
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Consume implements Repository.
+func (i impl) Consume(ctx context.Context, identifier, token string) (model.VerificationToken, error) {
+	query := `
+		DELETE FROM verification_token
+		WHERE identifier = $1 AND token = $2
+		RETURNING identifier, expires, token
+	`
+
+	var consumed model.VerificationToken
+	err := i.db.QueryRowContext(ctx, query, identifier, token).Scan(
+		&consumed.Identifier,
+		&consumed.Expires,
+		&consumed.Token,
+	)
+
+	if err == sql.ErrNoRows {
+		return model.VerificationToken{}, pkgerrors.WithStack(ErrNotFound)
+	}
+
+	if err != nil {
+		return model.VerificationToken{}, pkgerrors.WithStack(err)
+	}
+
+	return consumed, nil
+}
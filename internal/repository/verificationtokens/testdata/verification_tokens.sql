@@ -0,0 +1,10 @@
+-- Test data for verification tokens repository tests
+-- This file is loaded by testdb.LoadTestSQLFile within a rolled-back transaction
+
+DELETE FROM verification_token;
+
+INSERT INTO verification_token (identifier, expires, token)
+VALUES
+    ('verify-email:test1@example.com', NOW() + INTERVAL '1 day', 'hashed-token-1'),
+    ('verify-email:test1@example.com', NOW() + INTERVAL '1 day', 'hashed-token-2'),
+    ('verify-email:test2@example.com', NOW() - INTERVAL '1 day', 'hashed-token-3');
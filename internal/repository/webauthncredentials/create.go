@@ -0,0 +1,44 @@
+package webauthncredentials
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// Create implements Repository.
+func (i impl) Create(ctx context.Context, credential model.WebAuthnCredential) (model.WebAuthnCredential, error) {
+	query := `
+		INSERT INTO webauthn_credentials (account_id, user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports, backup_eligible, backup_state, name, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())
+		RETURNING id, created_at
+	`
+
+	err := i.db.QueryRowContext(ctx, query,
+		credential.AccountID,
+		credential.UserID,
+		credential.CredentialID,
+		credential.PublicKey,
+		credential.AttestationType,
+		credential.AAGUID,
+		int64(credential.SignCount),
+		strings.Join(credential.Transports, ","),
+		credential.BackupEligible,
+		credential.BackupState,
+		credential.Name,
+	).Scan(&credential.ID, &credential.CreatedAt)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return model.WebAuthnCredential{}, pkgerrors.WithStack(ErrAlreadyRegistered)
+		}
+		return model.WebAuthnCredential{}, pkgerrors.WithStack(err)
+	}
+
+	return credential, nil
+}
@@ -0,0 +1,59 @@
+package webauthncredentials
+
+import (
+	"context"
+	"testing"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	"github.com/namf2001/go-backend-template/internal/pkg/testdb"
+	"github.com/namf2001/go-backend-template/internal/repository/db/pg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate(t *testing.T) {
+	type args struct {
+		givenCredentialID []byte
+		expErr            error
+	}
+
+	tcs := map[string]args{
+		"success": {
+			givenCredentialID: []byte{0x01, 0x03},
+		},
+		"err - credential id already registered": {
+			givenCredentialID: []byte{0x01, 0x01},
+			expErr:            ErrAlreadyRegistered,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			testdb.WithTx(t, func(tx pg.ContextExecutor) {
+				testdb.LoadTestSQLFile(t, tx, "testdata/webauthn_credentials.sql")
+				repo := New(tx)
+				created, err := repo.Create(context.Background(), model.WebAuthnCredential{
+					AccountID:    7101,
+					UserID:       7001,
+					CredentialID: tc.givenCredentialID,
+					PublicKey:    []byte{0xa1},
+					SignCount:    1,
+					Transports:   []string{"usb", "nfc"},
+					Name:         "Security key",
+				})
+
+				if tc.expErr != nil {
+					require.ErrorIs(t, err, tc.expErr)
+					return
+				}
+				require.NoError(t, err)
+				require.NotZero(t, created.ID)
+
+				found, err := repo.GetByCredentialID(context.Background(), tc.givenCredentialID)
+				require.NoError(t, err)
+				require.Equal(t, created.ID, found.ID)
+				require.Equal(t, []string{"usb", "nfc"}, found.Transports)
+				require.Equal(t, uint32(1), found.SignCount)
+			})
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package webauthncredentials
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/namf2001/go-backend-template/internal/model"
+	pkgerrors "github.com/pkg/errors"
+)
+
+const columns = `id, account_id, user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports, backup_eligible, backup_state, name, created_at, last_used_at`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scan(row scanner) (model.WebAuthnCredential, error) {
+	var (
+		credential model.WebAuthnCredential
+		signCount  int64
+		transports string
+	)
+	if err := row.Scan(
+		&credential.ID,
+		&credential.AccountID,
+		&credential.UserID,
+		&credential.CredentialID,
+		&credential.PublicKey,
+		&credential.AttestationType,
+		&credential.AAGUID,
+		&signCount,
+		&transports,
+		&credential.BackupEligible,
+		&credential.BackupState,
+		&credential.Name,
+		&credential.CreatedAt,
+		&credential.LastUsedAt,
+	); err != nil {
+		return model.WebAuthnCredential{}, err
+	}
+
+	credential.SignCount = uint32(signCount)
+	if transports != "" {
+		credential.Transports = strings.Split(transports, ",")
+	}
+	return credential, nil
+}
+
+// GetByCredentialID implements Repository.
+func (i impl) GetByCredentialID(ctx context.Context, credentialID []byte) (model.WebAuthnCredential, error) {
+	query := `SELECT ` + columns + ` FROM webauthn_credentials WHERE credential_id = $1`
+
+	credential, err := scan(i.db.QueryRowContext(ctx, query, credentialID))
+	if err == sql.ErrNoRows {
+		return model.WebAuthnCredential{}, pkgerrors.WithStack(ErrNotFound)
+	}
+
+	if err != nil {
+		return model.WebAuthnCredential{}, pkgerrors.WithStack(err)
+	}
+
+	return credential, nil
+}
+
+// ListByUserID implements Repository.
+func (i impl) ListByUserID(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error) {
+	query := `SELECT ` + columns + ` FROM webauthn_credentials WHERE user_id = $1 ORDER BY created_at, id`
+
+	rows, err := i.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, pkgerrors.WithStack(err)
+	}
+	defer rows.Close()
+
+	var credentials []model.WebAuthnCredential
+	for rows.Next() {
+		credential, err := scan(rows)
+		if err != nil {
+			return nil, pkgerrors.WithStack(err)
+		}
+		credentials = append(credentials, credential)
+	}
+
+	return credentials, pkgerrors.WithStack(rows.Err())
+}
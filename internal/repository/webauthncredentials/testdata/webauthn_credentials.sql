@@ -0,0 +1,22 @@
+-- Test data for webauthn credentials repository tests
+-- This file is loaded by testdb.LoadTestSQLFile within a rolled-back transaction
+
+DELETE FROM webauthn_credentials;
+DELETE FROM accounts;
+DELETE FROM users;
+
+INSERT INTO users (id, email, name, password, image, created_at, updated_at)
+VALUES
+    (7001, 'passkey1@example.com', 'Passkey User 1', '', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00'),
+    (7002, 'passkey2@example.com', 'Passkey User 2', '', '', '2024-01-01 00:00:00', '2024-01-01 00:00:00');
+
+INSERT INTO accounts (id, "userId", type, provider, "providerAccountId", refresh_token, access_token, expires_at, id_token, scope, session_state, token_type)
+VALUES
+    (7101, 7001, 'webauthn', 'webauthn', '7001', '', '', 0, '', '', '', ''),
+    (7102, 7002, 'webauthn', 'webauthn', '7002', '', '', 0, '', '', '', '');
+
+INSERT INTO webauthn_credentials (id, account_id, user_id, credential_id, public_key, sign_count, transports, name, created_at)
+VALUES
+    (7201, 7101, 7001, '\x0101', '\xa1', 10, 'internal,hybrid', 'Laptop', '2024-01-01 00:00:00'),
+    (7202, 7101, 7001, '\x0102', '\xa1', 0, '', 'Phone', '2024-01-02 00:00:00'),
+    (7203, 7102, 7002, '\x0201', '\xa1', 0, '', 'Security key', '2024-01-01 00:00:00');
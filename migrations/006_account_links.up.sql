@@ -0,0 +1,7 @@
+-- Registration used to add an account without a provider for credential users. The password is
+-- the sign-in method of those users, the rows carry nothing and would break the unique indexes below.
+DELETE FROM accounts WHERE provider = '';
+
+-- A provider identity can be linked to a single user, and a user links each provider at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_provider_account ON accounts(provider, "providerAccountId");
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounts_user_provider ON accounts("userId", provider);
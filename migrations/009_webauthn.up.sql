@@ -0,0 +1,19 @@
+-- Passkeys. Every credential hangs off the user's "webauthn" account, so unlinking the provider removes them all.
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id BYTEA NOT NULL UNIQUE,
+    public_key BYTEA NOT NULL,
+    attestation_type VARCHAR(32) NOT NULL DEFAULT '',
+    aaguid BYTEA,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    transports TEXT NOT NULL DEFAULT '',
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    backup_state BOOLEAN NOT NULL DEFAULT FALSE,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials(user_id);
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'profile:read';
+
+DROP TABLE IF EXISTS api_keys;